@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"sean-stapleton-doyle/csvtk/pkg/csveditor"
+
+	"github.com/spf13/cobra"
+)
+
+var aggregateCmd = &cobra.Command{
+	Use:     "aggregate [file]",
+	Aliases: []string{"groupby"},
+	Short:   "Group rows and compute aggregates per group",
+	Long: `Group rows by one or more columns and compute aggregates for each group.
+The input is read in a single pass; groups are emitted in order of first appearance.
+Without --group-by the whole file is treated as one group.
+
+An aggregation is written as [NAME=]FUNCTION[:COLUMN[:ARG]]:
+  count               number of rows (or non-empty values with a column)
+  count-distinct:C    number of distinct non-empty values
+  sum:C, mean:C       sum and arithmetic mean
+  min:C, max:C        numeric minimum and maximum
+  median:C            median
+  pNN:C               NNth percentile, e.g. p90:Latency
+  first:C, last:C     first and last value in the group
+  join:C[:SEP]        values joined with SEP (default ",")
+
+Empty cells are ignored by numeric aggregates. Other non-numeric values are
+skipped unless --non-numeric error is given.
+
+Examples:
+  csvtk aggregate sales.csv -g Region -a count -a sum:Revenue -a mean:Revenue
+  csvtk aggregate sales.csv -g Region,Year -a p90:Latency -a reps=join:Rep:";"
+  cat sales.csv | csvtk groupby -g Region -a count-distinct:Customer -`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		filename := "-"
+		if len(args) > 0 {
+			filename = args[0]
+		}
+
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		specs, _ := cmd.Flags().GetStringArray("agg")
+		if len(specs) == 0 {
+			specs = []string{"count"}
+		}
+
+		aggConfig := csveditor.AggregateConfig{}
+		if groupBy != "" {
+			aggConfig.GroupBy = splitColumns(groupBy)
+		}
+
+		for _, spec := range specs {
+			agg, err := csveditor.ParseAggregation(spec)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			aggConfig.Aggregations = append(aggConfig.Aggregations, agg)
+		}
+
+		nonNumeric, _ := cmd.Flags().GetString("non-numeric")
+		switch nonNumeric {
+		case "skip":
+			aggConfig.NonNumeric = csveditor.NonNumericSkip
+		case "error":
+			aggConfig.NonNumeric = csveditor.NonNumericError
+		default:
+			fmt.Fprintf(os.Stderr, "Error: --non-numeric must be \"skip\" or \"error\"\n")
+			os.Exit(1)
+		}
+
+		config := getConfig(cmd)
+
+		reader := openInput(filename, config)
+		defer reader.Close()
+
+		output, _ := cmd.Flags().GetString("output")
+		writer := openOutput(cmd, output, config)
+
+		err := csveditor.AggregateStream(reader, writer, aggConfig)
+		if err != nil {
+			abortOutput(writer)
+			fmt.Fprintf(os.Stderr, "Error aggregating CSV: %v\n", err)
+			os.Exit(1)
+		}
+		closeOutput(writer)
+
+		if !isStdout(output) {
+			fmt.Fprintf(os.Stderr, "Aggregated %s into %s\n", filename, output)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(aggregateCmd)
+	aggregateCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
+	aggregateCmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
+	aggregateCmd.Flags().StringP("group-by", "g", "", "Comma-separated columns to group by")
+	aggregateCmd.Flags().StringArrayP("agg", "a", nil, "Aggregation [NAME=]FUNCTION[:COLUMN[:ARG]] (repeatable, defaults to count)")
+	aggregateCmd.Flags().String("non-numeric", "skip", "How numeric aggregates treat non-numeric values: skip or error")
+}
@@ -1,92 +1,343 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"sean-stapleton-doyle/csvtk/pkg/csveditor"
+	"sean-stapleton-doyle/csvtk/pkg/csvformat"
+	"sean-stapleton-doyle/csvtk/pkg/csvjson"
 	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+	"sean-stapleton-doyle/csvtk/pkg/csvschema"
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
 
 	"github.com/spf13/cobra"
 )
 
+var formatExtensions = map[string]string{
+	"csv":      ".csv",
+	"tsv":      ".tsv",
+	"json":     ".json",
+	"ndjson":   ".ndjson",
+	"markdown": ".md",
+	"md":       ".md",
+	"html":     ".html",
+	"latex":    ".tex",
+	"tex":      ".tex",
+	"sql":      ".sql",
+	"xlsx":     ".xlsx",
+}
+
 var convertCmd = &cobra.Command{
 	Use:   "convert [file]",
-	Short: "Convert between CSV and TSV formats",
-	Long: `Convert a CSV file to TSV (tab-delimited) or vice versa.
-Use --to-tsv or --to-csv flags to specify the conversion direction.
-If neither flag is specified, the tool will infer based on the input file extension.`,
+	Short: "Convert between CSV, TSV, JSON, NDJSON and XLSX",
+	Long: `Convert a file between CSV, TSV, JSON (array of objects), NDJSON
+(one object per line) and Excel workbooks (.xlsx).
+
+The input format is taken from --from or inferred from the file extension
+(.tsv/.tab, .json, .ndjson/.jsonl, .xlsx, otherwise CSV). Use --sheet to pick
+a worksheet by name or 1-based index when reading a workbook. Without --to, CSV is
+converted to TSV and everything else to CSV. --to-tsv and --to-csv are
+shorthands for --to tsv and --to csv. --to also accepts any of the global
+--format writers (markdown, html, latex, sql, xlsx).
+
+When reading JSON, nested objects are flattened into dotted column names
+(address.city). Arrays of scalars are joined with --array-separator, or with
+--explode-arrays each array element becomes its own row.
+
+When writing JSON, every value is a string unless --infer-types is given, in
+which case numeric and boolean columns become JSON numbers and booleans and
+empty cells become null. --schema takes the column types from a Table Schema
+(see "csvtk schema infer") instead, for JSON values and SQL column types.
+
+Examples:
+  csvtk convert data.csv --to-tsv
+  csvtk convert data.csv --to json --infer-types
+  csvtk convert data.csv --to sql --schema schema.yaml
+  csvtk convert events.ndjson --to csv --explode-arrays
+  csvtk convert users.json --to sql --table users --dialect postgres
+  csvtk convert report.xlsx --sheet Summary --to csv
+  csvtk convert data.csv --to xlsx
+  cat data.csv | csvtk convert - --from csv --to ndjson -o -`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		filename := args[0]
 
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
 		toTSV, _ := cmd.Flags().GetBool("to-tsv")
 		toCSV, _ := cmd.Flags().GetBool("to-csv")
 
-		if !toTSV && !toCSV {
-			ext := strings.ToLower(filepath.Ext(filename))
-			if ext == ".tsv" || ext == ".tab" {
-				toCSV = true
+		if from == "" {
+			from = formatFromExtension(filename)
+			if from == "csv" || from == "tsv" {
+				switch {
+				case toTSV:
+					from = "csv"
+				case toCSV:
+					from = "tsv"
+				}
+			}
+		}
+		if to == "" && cmd.Flags().Changed("format") {
+			to, _ = cmd.Flags().GetString("format")
+		}
+		if output, _ := cmd.Flags().GetString("output"); to == "" && !toTSV && !toCSV && csvparser.IsXLSX(output) {
+			to = "xlsx"
+		}
+		if to == "" {
+			switch {
+			case toTSV:
+				to = "tsv"
+			case toCSV:
+				to = "csv"
+			case from == "csv":
+				to = "tsv"
+			default:
+				to = "csv"
+			}
+		}
+
+		to = strings.ToLower(to)
+		switch from {
+		case "csv", "tsv", "json", "ndjson", "xlsx":
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown input format %q\n", from)
+			os.Exit(1)
+		}
+		if to != "json" && to != "ndjson" {
+			if _, err := csvformat.Lookup(to); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		outputExt, ok := formatExtensions[to]
+		if !ok {
+			outputExt = "." + to
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			if filename == "-" {
+				output = "-"
 			} else {
-				toTSV = true
+				base := csvparser.TrimCompression(filename)
+				output = strings.TrimSuffix(base, filepath.Ext(base)) + outputExt
 			}
 		}
 
-		var inputDelimiter, outputDelimiter rune
-		var outputExt string
+		config := csvparser.DefaultConfig()
+		config.Sheet, _ = cmd.Flags().GetString("sheet")
+		config.Encoding, _ = cmd.Flags().GetString("encoding")
+		if from == "tsv" {
+			config.Delimiter = '\t'
+		}
+		if cmd.Flags().Changed("delimiter") {
+			config.Delimiter = getDelimiter(cmd)
+		} else if from == "csv" {
+			config.Sniff = true
+		}
 
-		if toTSV {
-			inputDelimiter = ','
-			outputDelimiter = '\t'
-			outputExt = ".tsv"
+		var err error
+		if from == "json" || from == "ndjson" {
+			err = convertFromJSON(cmd, filename, output, to)
 		} else {
-			inputDelimiter = '\t'
-			outputDelimiter = ','
-			outputExt = ".csv"
+			err = convertFromDelimited(cmd, filename, output, from, to, config)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error converting file: %v\n", err)
+			os.Exit(1)
 		}
 
-		if cmd.Flags().Changed("delimiter") {
-			inputDelimiter = getDelimiter(cmd)
+		if !isStdout(output) {
+			fmt.Printf("Converted to %s: %s\n", strings.ToUpper(to), output)
 		}
+	},
+}
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = inputDelimiter
+func formatFromExtension(filename string) string {
+	switch strings.ToLower(filepath.Ext(csvparser.TrimCompression(filename))) {
+	case ".tsv", ".tab":
+		return "tsv"
+	case ".json":
+		return "json"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	case ".xlsx", ".xlsm":
+		return "xlsx"
+	default:
+		return "csv"
+	}
+}
+
+func convertFromDelimited(cmd *cobra.Command, filename, output, from, to string, config *csvparser.Config) error {
+	var reader *csvparser.Reader
+	var err error
+	if from == "xlsx" && filename == "-" {
+		var data []byte
+		if data, err = io.ReadAll(os.Stdin); err == nil {
+			reader, err = csvparser.ReadXLSX(bytes.NewReader(data), int64(len(data)), config)
+		}
+	} else {
+		reader, err = csvparser.OpenFileOrStdin(filename, config)
+	}
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
 
-		csv, err := csvparser.ParseFile(filename, config)
+	if to == "json" || to == "ndjson" {
+		inferTypes, _ := cmd.Flags().GetBool("infer-types")
+		types, err := getSchemaTypes(cmd)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing file: %v\n", err)
-			os.Exit(1)
+			return err
+		}
+		options := csvjson.WriteOptions{
+			NDJSON:     to == "ndjson",
+			InferTypes: inferTypes,
+			Types:      types,
 		}
 
-		output, _ := cmd.Flags().GetString("output")
-		if output == "" {
+		out, closer, err := createOutputFile(cmd, output)
+		if err != nil {
+			return err
+		}
+		if err := csvjson.Write(reader, out, options); err != nil {
+			csvparser.AbortOutput(closer)
+			return err
+		}
+		return closer.Close()
+	}
 
-			ext := filepath.Ext(filename)
-			output = strings.TrimSuffix(filename, ext) + outputExt
+	writer, err := createConvertOutput(cmd, output, to)
+	if err != nil {
+		return err
+	}
+	if err := csveditor.Copy(reader, writer); err != nil {
+		writer.Abort()
+		return err
+	}
+	return writer.Close()
+}
+
+func convertFromJSON(cmd *cobra.Command, filename, output, to string) error {
+	options := csvjson.DefaultReadOptions()
+	if explode, _ := cmd.Flags().GetBool("explode-arrays"); explode {
+		options.Arrays = csvjson.ArrayExplode
+	}
+	options.Separator, _ = cmd.Flags().GetString("array-separator")
+
+	var in io.Reader = os.Stdin
+	if filename != "-" {
+		file, err := os.Open(filename)
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
 		}
+		defer file.Close()
+		in = file
+	}
 
-		outputConfig := csvparser.DefaultConfig()
-		outputConfig.Delimiter = outputDelimiter
+	in, decompressor, err := csvparser.Decompress(in)
+	if err != nil {
+		return err
+	}
+	if decompressor != nil {
+		defer decompressor.Close()
+	}
 
-		err = csv.WriteToFile(output, outputConfig)
+	encoding, _ := cmd.Flags().GetString("encoding")
+	in, _, err = csvparser.DecodeReader(in, encoding)
+	if err != nil {
+		return err
+	}
+
+	csv, err := csvjson.Read(in, options)
+	if err != nil {
+		return err
+	}
+
+	if to == "json" || to == "ndjson" {
+		inferTypes, _ := cmd.Flags().GetBool("infer-types")
+		types, err := getSchemaTypes(cmd)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
-			os.Exit(1)
+			return err
+		}
+		out, closer, err := createOutputFile(cmd, output)
+		if err != nil {
+			return err
+		}
+		if err := csvjson.WriteCSV(csv, out, csvjson.WriteOptions{NDJSON: to == "ndjson", InferTypes: inferTypes, Types: types}); err != nil {
+			csvparser.AbortOutput(closer)
+			return err
 		}
+		return closer.Close()
+	}
+
+	writer, err := createConvertOutput(cmd, output, to)
+	if err != nil {
+		return err
+	}
+	if err := csv.WriteAll(writer); err != nil {
+		writer.Abort()
+		return err
+	}
+	return writer.Close()
+}
 
-		format := "TSV"
-		if toCSV {
-			format = "CSV"
+func createConvertOutput(cmd *cobra.Command, output, to string) (*csvparser.Writer, error) {
+	_, options, err := getOutputFormat(cmd, csvparser.DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	schema, err := getSchema(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if schema != nil {
+		options.Types = schema.ValueTypes()
+		if options.Layouts, err = schema.Layouts(); err != nil {
+			return nil, err
 		}
-		fmt.Printf("Converted to %s: %s\n", format, output)
-	},
+	}
+	return csvformat.CreateFileOrStdout(to, output, options)
+}
+
+func getSchema(cmd *cobra.Command) (*csvschema.Schema, error) {
+	filename, _ := cmd.Flags().GetString("schema")
+	if filename == "" {
+		return nil, nil
+	}
+	return csvschema.Load(filename)
+}
+
+func getSchemaTypes(cmd *cobra.Command) (map[string]csvstats.ValueType, error) {
+	schema, err := getSchema(cmd)
+	if err != nil || schema == nil {
+		return nil, err
+	}
+	return schema.ValueTypes(), nil
+}
+
+func createOutputFile(cmd *cobra.Command, output string) (io.Writer, io.Closer, error) {
+	encoding, _ := cmd.Flags().GetString("output-encoding")
+	bom, _ := cmd.Flags().GetBool("bom")
+	return csvparser.CreateOutput(output, encoding, bom)
 }
 
 func init() {
 	rootCmd.AddCommand(convertCmd)
 	convertCmd.Flags().StringP("delimiter", "d", "", "Input delimiter (auto-detected if not specified)")
-	convertCmd.Flags().StringP("output", "o", "", "Output file (auto-generated if not specified)")
+	convertCmd.Flags().StringP("output", "o", "", "Output file (auto-generated if not specified, \"-\" for stdout)")
+	convertCmd.Flags().String("from", "", "Input format: csv, tsv, json, ndjson, xlsx (inferred from extension if not specified)")
+	convertCmd.Flags().String("to", "", "Output format: json, ndjson or any --format writer (csv, tsv, markdown, html, latex, sql, xlsx)")
 	convertCmd.Flags().Bool("to-tsv", false, "Convert to TSV format")
 	convertCmd.Flags().Bool("to-csv", false, "Convert to CSV format")
+	convertCmd.Flags().Bool("infer-types", false, "Emit numbers, booleans and nulls as JSON types instead of strings")
+	convertCmd.Flags().String("schema", "", "Table Schema whose field types set JSON value types and SQL column types")
+	convertCmd.Flags().Bool("explode-arrays", false, "Emit one row per JSON array element instead of joining arrays")
+	convertCmd.Flags().String("array-separator", ";", "Separator used when joining JSON arrays into a single cell")
 }
@@ -2,10 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 
-	"sean-stapleton-doyle/csvtk/pkg/csvparser"
-
 	"github.com/spf13/cobra"
 )
 
@@ -26,16 +25,22 @@ var countRowsCmd = &cobra.Command{
 			filename = args[0]
 		}
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = getDelimiter(cmd)
+		config := getConfig(cmd)
+
+		reader := openInput(filename, config)
+		defer reader.Close()
 
-		csv, err := csvparser.ParseFromFileOrStdin(filename, config)
+		rows := 0
+		err := reader.ForEach(func(record []string) error {
+			rows++
+			return nil
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Println(csv.CountRows())
+		fmt.Println(rows)
 	},
 }
 
@@ -50,16 +55,22 @@ var countColumnsCmd = &cobra.Command{
 			filename = args[0]
 		}
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = getDelimiter(cmd)
+		config := getConfig(cmd)
 
-		csv, err := csvparser.ParseFromFileOrStdin(filename, config)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
-			os.Exit(1)
+		reader := openInput(filename, config)
+		defer reader.Close()
+
+		columns := len(reader.Header())
+		if columns == 0 {
+			record, err := reader.Next()
+			if err != nil && err != io.EOF {
+				fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
+				os.Exit(1)
+			}
+			columns = len(record)
 		}
 
-		fmt.Println(csv.CountColumns())
+		fmt.Println(columns)
 	},
 }
 
@@ -68,6 +79,6 @@ func init() {
 	countCmd.AddCommand(countRowsCmd)
 	countCmd.AddCommand(countColumnsCmd)
 
-	countRowsCmd.Flags().StringP("delimiter", "d", ",", "Field delimiter")
-	countColumnsCmd.Flags().StringP("delimiter", "d", ",", "Field delimiter")
+	countRowsCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
+	countColumnsCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
 }
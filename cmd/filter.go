@@ -1,11 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"sean-stapleton-doyle/csvtk/pkg/csveditor"
-	"sean-stapleton-doyle/csvtk/pkg/csvparser"
 
 	"github.com/spf13/cobra"
 )
@@ -17,13 +18,40 @@ var filterCmd = &cobra.Command{
 By default, filters rows where the column value equals the given value.
 Use flags to specify different filter operations including regex and numeric comparisons.
 
+With -e/--expr, rows are filtered by an expression instead of a single
+column and value. Conditions compare a column with a value or another
+column and combine with && (and), || (or), ! (not) and parentheses:
+
+  ==, !=               equality; numeric when neither side is a "quoted" string
+  >, <, >=, <=         numeric comparison
+  =~, !~               regex match
+  contains, startswith, endswith
+
+Quote text values with "..." or '...', and column names that are not plain
+words with backticks: ` + "`First Name`" + `.
+
 Examples:
   csvtk filter City "New York" data.csv
   csvtk filter Age 30 data.csv --operator ">"
   csvtk filter Email "@gmail.com" data.csv --regex
+  csvtk filter -e 'Country == "US" && (Age > 30 || VIP == "yes")' data.csv
+  csvtk filter -e 'Price > Cost && !(Name =~ "^test")' data.csv
   cat data.csv | csvtk filter Name "John" -  # from stdin`,
-	Args: cobra.RangeArgs(2, 3),
+	Args: cobra.RangeArgs(0, 3),
 	Run: func(cmd *cobra.Command, args []string) {
+		if expr, _ := cmd.Flags().GetString("expr"); cmd.Flags().Changed("expr") {
+			if len(args) > 1 {
+				fmt.Fprintln(os.Stderr, "Error: with --expr, filter takes at most one file argument")
+				os.Exit(1)
+			}
+			runFilterExpr(cmd, expr, args)
+			return
+		}
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: filter requires a column and a value, or --expr")
+			os.Exit(1)
+		}
+
 		columnName := args[0]
 		value := args[1]
 		filename := ""
@@ -33,14 +61,7 @@ Examples:
 			filename = "-"
 		}
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = getDelimiter(cmd)
-
-		csv, err := csvparser.ParseFromFileOrStdin(filename, config)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
-			os.Exit(1)
-		}
+		config := getConfig(cmd)
 
 		operator, _ := cmd.Flags().GetString("operator")
 		regex, _ := cmd.Flags().GetBool("regex")
@@ -65,37 +86,73 @@ Examples:
 
 		strategy := csveditor.NewFilterStrategy(operator)
 
-		filtered, err := csveditor.FilterWithStrategy(csv, columnName, value, strategy)
+		reader := openInput(filename, config)
+		defer reader.Close()
+
+		output, _ := cmd.Flags().GetString("output")
+		writer := openOutput(cmd, output, config)
+
+		matched, err := csveditor.FilterStream(reader, writer, columnName, value, strategy)
 		if err != nil {
+			abortOutput(writer)
 			fmt.Fprintf(os.Stderr, "Error filtering CSV: %v\n", err)
 			os.Exit(1)
 		}
+		closeOutput(writer)
 
-		output, _ := cmd.Flags().GetString("output")
-		if output == "" || output == "-" {
-
-			err = filtered.Write(os.Stdout, config)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
-				os.Exit(1)
-			}
-		} else {
-			err = filtered.WriteToFile(output, config)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Fprintf(os.Stderr, "Filtered %d rows to %s\n", len(filtered.Records), output)
+		if !isStdout(output) {
+			fmt.Fprintf(os.Stderr, "Filtered %d rows to %s\n", matched, output)
 		}
 	},
 }
 
+func runFilterExpr(cmd *cobra.Command, expr string, args []string) {
+	filename := "-"
+	if len(args) == 1 {
+		filename = args[0]
+	}
+
+	config := getConfig(cmd)
+	reader := openInput(filename, config)
+	defer reader.Close()
+
+	cond, err := csveditor.ParseFilter(expr, reader.Header())
+	if err != nil {
+		printExprError("filter expression", err)
+		os.Exit(1)
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	writer := openOutput(cmd, output, config)
+
+	matched, err := csveditor.FilterExprStream(reader, writer, cond)
+	if err != nil {
+		abortOutput(writer)
+		fmt.Fprintf(os.Stderr, "Error filtering CSV: %v\n", err)
+		os.Exit(1)
+	}
+	closeOutput(writer)
+
+	if !isStdout(output) {
+		fmt.Fprintf(os.Stderr, "Filtered %d rows to %s\n", matched, output)
+	}
+}
+
+func printExprError(what string, err error) {
+	fmt.Fprintf(os.Stderr, "Error in %s: %v\n", what, err)
+	var perr *csveditor.ParseError
+	if errors.As(err, &perr) {
+		fmt.Fprintf(os.Stderr, "  %s\n  %s^\n", perr.Expr, strings.Repeat(" ", perr.Column()-1))
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(filterCmd)
-	filterCmd.Flags().StringP("delimiter", "d", ",", "Field delimiter")
+	filterCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
 	filterCmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
 	filterCmd.Flags().StringP("operator", "p", "", "Filter operator: equals, contains, starts-with, ends-with, not-equals, regex, >, <, >=, <=, ==, !=")
 	filterCmd.Flags().Bool("regex", false, "Use regex matching")
+	filterCmd.Flags().StringP("expr", "e", "", "Filter expression, e.g. 'Country == \"US\" && (Age > 30 || VIP == \"yes\")'")
 
 	filterCmd.Flags().Bool("contains", false, "Filter rows where column contains the value")
 	filterCmd.Flags().Bool("starts-with", false, "Filter rows where column starts with the value")
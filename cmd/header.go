@@ -21,8 +21,7 @@ var headerCmd = &cobra.Command{
 			filename = args[0]
 		}
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = getDelimiter(cmd)
+		config := getConfig(cmd)
 
 		csv, err := csvparser.ParseFromFileOrStdin(filename, config)
 		if err != nil {
@@ -44,6 +43,6 @@ var headerCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(headerCmd)
-	headerCmd.Flags().StringP("delimiter", "d", ",", "Field delimiter")
+	headerCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
 	headerCmd.Flags().BoolP("numbered", "n", false, "Show column numbers")
 }
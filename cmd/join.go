@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csveditor"
+
+	"github.com/spf13/cobra"
+)
+
+var joinCmd = &cobra.Command{
+	Use:   "join [left-file] [right-file]",
+	Short: "Join two CSV files on key columns",
+	Long: `Join two CSV files on one or more key columns.
+
+Join types:
+  inner  rows with a match in both files (default)
+  left   all rows of the left file, with right columns when matched
+  right  all rows of the right file, with left columns when matched
+  full   all rows of both files
+  semi   left rows that have a match in the right file (left columns only)
+  anti   left rows that have no match in the right file (left columns only)
+
+The output contains every left column followed by the non-key right columns.
+Non-key columns present in both files are renamed with the configured prefixes
+and suffixes. The smaller file is loaded into a hash table and the larger file
+is streamed; use "-" for either file to read it from stdin.
+
+Examples:
+  csvtk join customers.csv orders.csv -k CustomerID
+  csvtk join customers.csv orders.csv --left-on id --right-on customer_id -t left
+  csvtk join a.csv b.csv -k "Region,Year" -t full --right-suffix _b
+  cat orders.csv | csvtk join - blocked.csv -k CustomerID -t anti`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		leftFile := args[0]
+		rightFile := args[1]
+
+		if leftFile == "-" && rightFile == "-" {
+			fmt.Fprintf(os.Stderr, "Error: only one input can be read from stdin\n")
+			os.Exit(1)
+		}
+
+		joinTypeName, _ := cmd.Flags().GetString("type")
+		joinType, err := csveditor.ParseJoinType(joinTypeName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		on, _ := cmd.Flags().GetString("on")
+		leftOn, _ := cmd.Flags().GetString("left-on")
+		rightOn, _ := cmd.Flags().GetString("right-on")
+		if leftOn == "" {
+			leftOn = on
+		}
+		if rightOn == "" {
+			rightOn = on
+		}
+		if leftOn == "" || rightOn == "" {
+			fmt.Fprintf(os.Stderr, "Error: key columns required (use --on or --left-on/--right-on)\n")
+			os.Exit(1)
+		}
+
+		leftPrefix, _ := cmd.Flags().GetString("left-prefix")
+		leftSuffix, _ := cmd.Flags().GetString("left-suffix")
+		rightPrefix, _ := cmd.Flags().GetString("right-prefix")
+		rightSuffix, _ := cmd.Flags().GetString("right-suffix")
+
+		joinConfig := csveditor.JoinConfig{
+			Type:        joinType,
+			LeftKeys:    splitColumns(leftOn),
+			RightKeys:   splitColumns(rightOn),
+			LeftPrefix:  leftPrefix,
+			LeftSuffix:  leftSuffix,
+			RightPrefix: rightPrefix,
+			RightSuffix: rightSuffix,
+			BuildLeft:   fileSize(rightFile) > fileSize(leftFile),
+		}
+
+		config := getConfig(cmd)
+		rightConfig := *config
+
+		left := openInput(leftFile, config)
+		defer left.Close()
+		right := openInput(rightFile, &rightConfig)
+		defer right.Close()
+
+		output, _ := cmd.Flags().GetString("output")
+		writer := openOutput(cmd, output, config)
+
+		err = csveditor.JoinStream(left, right, writer, joinConfig)
+		if err != nil {
+			abortOutput(writer)
+			fmt.Fprintf(os.Stderr, "Error joining CSV: %v\n", err)
+			os.Exit(1)
+		}
+		closeOutput(writer)
+
+		if !isStdout(output) {
+			fmt.Fprintf(os.Stderr, "Joined %s and %s into %s\n", leftFile, rightFile, output)
+		}
+	},
+}
+
+func splitColumns(s string) []string {
+	columnNames := strings.Split(s, ",")
+	for i := range columnNames {
+		columnNames[i] = strings.TrimSpace(columnNames[i])
+	}
+	return columnNames
+}
+
+func fileSize(filename string) int64 {
+	if filename == "" || filename == "-" {
+		return 1<<63 - 1
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func init() {
+	rootCmd.AddCommand(joinCmd)
+	joinCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
+	joinCmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
+	joinCmd.Flags().StringP("type", "t", "inner", "Join type: inner, left, right, full, semi, anti")
+	joinCmd.Flags().StringP("on", "k", "", "Comma-separated key columns present in both files")
+	joinCmd.Flags().String("left-on", "", "Comma-separated key columns in the left file")
+	joinCmd.Flags().String("right-on", "", "Comma-separated key columns in the right file")
+	joinCmd.Flags().String("left-prefix", "", "Prefix for duplicate non-key column names from the left file")
+	joinCmd.Flags().String("left-suffix", "", "Suffix for duplicate non-key column names from the left file")
+	joinCmd.Flags().String("right-prefix", "", "Prefix for duplicate non-key column names from the right file")
+	joinCmd.Flags().String("right-suffix", "_right", "Suffix for duplicate non-key column names from the right file")
+}
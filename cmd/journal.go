@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"sean-stapleton-doyle/csvtk/pkg/csveditor"
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+
+	"github.com/spf13/cobra"
+)
+
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Replay or roll back a journal of edits",
+	Long: `Replay or roll back the edits recorded with --journal.
+
+The move, rename and transform commands write every edit they make to the
+file given with --journal, one JSON object per line holding the operation and
+its inverse; each run replaces the journal. Columns are found by name, and
+transforms are recomputed on replay, so the same batch of edits can be applied
+to another file, or undone on the edited file.
+
+Examples:
+  csvtk transform upper Name data.csv --journal edits.jsonl -o out.csv
+  csvtk journal replay edits.jsonl other.csv -o other-out.csv
+  csvtk journal rollback edits.jsonl out.csv -o restored.csv`,
+}
+
+var journalReplayCmd = &cobra.Command{
+	Use:   "replay [journal] [file]",
+	Short: "Apply the edits in a journal to a file",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runJournal(cmd, args, csveditor.Replay, "Replayed")
+	},
+}
+
+var journalRollbackCmd = &cobra.Command{
+	Use:   "rollback [journal] [file]",
+	Short: "Undo the edits in a journal, newest first",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runJournal(cmd, args, csveditor.Rollback, "Rolled back")
+	},
+}
+
+func runJournal(cmd *cobra.Command, args []string, run func(*csvparser.CSV, []csveditor.Entry) error, verb string) {
+	file, err := os.Open(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to open journal: %v\n", err)
+		os.Exit(1)
+	}
+	entries, err := csveditor.ReadJournal(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	filename := "-"
+	if len(args) == 2 {
+		filename = args[1]
+	}
+	config := getConfig(cmd)
+	reader := openInput(filename, config)
+	csv, err := csvparser.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := run(csv, entries); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	writer := openOutput(cmd, output, config)
+	if err := csv.WriteAll(writer); err != nil {
+		abortOutput(writer)
+		fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
+		os.Exit(1)
+	}
+	closeOutput(writer)
+
+	if !isStdout(output) {
+		fmt.Fprintf(os.Stderr, "%s %d edits to %s\n", verb, len(entries), output)
+	}
+}
+
+func writeJournal(cmd *cobra.Command, entries []csveditor.Entry) {
+	path, _ := cmd.Flags().GetString("journal")
+	if path == "" || len(entries) == 0 {
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to open journal: %v\n", err)
+		os.Exit(1)
+	}
+	if err := csveditor.WriteJournal(file, entries); err != nil {
+		file.Close()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := file.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write journal: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(journalCmd)
+	journalCmd.AddCommand(journalReplayCmd)
+	journalCmd.AddCommand(journalRollbackCmd)
+
+	for _, cmd := range []*cobra.Command{journalReplayCmd, journalRollbackCmd} {
+		cmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
+		cmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
+	}
+}
@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
 	"sean-stapleton-doyle/csvtk/pkg/csvlint"
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
 
 	"github.com/spf13/cobra"
 )
@@ -12,43 +18,230 @@ import (
 var lintCmd = &cobra.Command{
 	Use:   "lint [file]",
 	Short: "Validate a CSV file against RFC 4180",
-	Long: `Validate a CSV file according to RFC 4180 standards.
-Reports any parsing errors or inconsistent field counts.`,
-	Args: cobra.ExactArgs(1),
+	Long: `Validate a CSV file according to RFC 4180 standards and common data
+hygiene rules. Reports every problem with its rule ID, line, column and
+record number.
+
+Each rule has a severity. The exit code is 0 for a valid file, 1 if there
+are only warnings and 2 if there are errors. Use --list-rules to see the
+rules and --enable/--disable to toggle them. Use --max-errors to stop after
+a number of problems.
+
+--fix rewrites the file in place, repairing what it safely can: ragged rows
+are padded (or truncated, see --ragged), fields with stray quotes are
+re-quoted, the byte order mark and blank lines are removed, line endings are
+normalized and duplicate or empty header names are renamed. --trim-space also
+trims whitespace around cell values.
+--dry-run prints a unified diff instead of writing the file.
+
+--report json, sarif or junit writes a machine-readable report for CI:
+SARIF 2.1.0 for code scanning tools, JUnit XML with one test case per rule
+for test dashboards.
+
+Examples:
+  csvtk lint data.csv
+  csvtk lint data.csv --disable whitespace,inconsistent-quotes
+  csvtk lint data.csv --enable unnecessary-quotes
+  csvtk lint data.csv --report sarif -o lint.sarif
+  csvtk lint data.csv --fix --dry-run
+  csvtk lint data.csv --fix --ragged both --line-ending lf
+  csvtk lint --list-rules`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if list, _ := cmd.Flags().GetBool("list-rules"); list {
+			listRules()
+			return
+		}
+		if len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: lint requires a file argument")
+			os.Exit(2)
+		}
 		filename := args[0]
 
-		delimiter := getDelimiter(cmd)
-		lazyQuotes, _ := cmd.Flags().GetBool("lazy-quotes")
+		options := csvlint.DefaultOptions()
+		options.Delimiter = getDelimiter(cmd)
+		if !cmd.Flags().Changed("delimiter") {
+			if sniffed, err := csvparser.SniffFile(filename); err == nil && sniffed.DelimiterConfidence > 0 {
+				options.Delimiter = sniffed.Delimiter
+			}
+		}
+		if fix, _ := cmd.Flags().GetBool("fix"); fix {
+			runLintFix(cmd, filename, options.Delimiter)
+			return
+		}
+
+		options.LazyQuotes, _ = cmd.Flags().GetBool("lazy-quotes")
+		options.MaxErrors, _ = cmd.Flags().GetInt("max-errors")
+		rules, err := getLintRules(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		options.Rules = rules
+
+		write := getReportWriter(cmd)
 
-		errors, hasFatalError, err := csvlint.ValidateFile(filename, delimiter, lazyQuotes)
+		problems, hasErrors, err := csvlint.ValidateFileWithOptions(filename, options)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error validating file: %v\n", err)
-			os.Exit(1)
+			os.Exit(2)
 		}
 
-		if hasFatalError {
-			fmt.Println("❌ CSV validation failed with fatal errors:")
-			for _, e := range errors {
-				fmt.Printf("  %s\n", e.Error())
-			}
-			os.Exit(1)
+		writeReport(cmd, write, csvlint.NewReport(filename, options, problems))
+
+		if len(problems) == 0 {
+			return
 		}
+		if hasErrors {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	},
+}
+
+var lintReportWriters = map[string]func(io.Writer, csvlint.Report) error{
+	"text":  csvlint.WriteText,
+	"json":  csvlint.WriteJSON,
+	"sarif": csvlint.WriteSARIF,
+	"junit": csvlint.WriteJUnit,
+}
 
-		if len(errors) > 0 {
-			fmt.Printf("⚠️  CSV validation completed with %d warning(s):\n", len(errors))
-			for _, e := range errors {
-				fmt.Printf("  %s\n", e.Error())
+func getReportWriter(cmd *cobra.Command) func(io.Writer, csvlint.Report) error {
+	format, _ := cmd.Flags().GetString("report")
+	write, ok := lintReportWriters[format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown report format %q (available: text, json, sarif, junit)\n", format)
+		os.Exit(2)
+	}
+	return write
+}
+
+func writeReport(cmd *cobra.Command, write func(io.Writer, csvlint.Report) error, report csvlint.Report) {
+	var out io.Writer = os.Stdout
+	var closer io.Closer
+	if output, _ := cmd.Flags().GetString("output"); !isStdout(output) {
+		var err error
+		if out, closer, err = csvparser.CreateOutput(output, "", false); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	err := write(out, report)
+	if err != nil && closer != nil {
+		csvparser.AbortOutput(closer)
+	} else if closer != nil {
+		err = closer.Close()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		os.Exit(2)
+	}
+}
+
+func runLintFix(cmd *cobra.Command, filename string, delimiter rune) {
+	options := csvlint.DefaultFixOptions()
+	options.Delimiter = delimiter
+	options.TrimSpace, _ = cmd.Flags().GetBool("trim-space")
+	options.LineEnding, _ = cmd.Flags().GetString("line-ending")
+	ragged, _ := cmd.Flags().GetString("ragged")
+	mode, err := csvlint.ParseRaggedMode(ragged)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	options.Ragged = mode
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	var diff bytes.Buffer
+	changes, err := csvlint.FixFile(filename, options, dryRun, &diff)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fixing file: %v\n", err)
+		os.Exit(2)
+	}
+
+	summary := os.Stdout
+	if dryRun {
+		summary = os.Stderr
+		if diff.Len() > 0 {
+			name := diffPath(filename)
+			fmt.Printf("--- a/%s\n+++ b/%s\n", name, name)
+			os.Stdout.Write(diff.Bytes())
+		}
+	}
+
+	fixed := 0
+	for _, c := range changes {
+		if c.Unfixed {
+			fmt.Fprintf(summary, "  cannot fix [%s]: %s\n", c.Rule, c)
+			continue
+		}
+		fixed++
+		fmt.Fprintf(summary, "  fixed [%s]: %s\n", c.Rule, c)
+	}
+	switch {
+	case fixed == 0 && len(changes) == 0:
+		fmt.Fprintln(summary, "✓ Nothing to fix")
+	case fixed == 0:
+		fmt.Fprintf(summary, "Nothing could be fixed automatically in %s\n", filename)
+	case dryRun:
+		fmt.Fprintf(summary, "Would make %d change(s) to %s\n", fixed, filename)
+	default:
+		fmt.Fprintf(summary, "✓ Made %d change(s) to %s\n", fixed, filename)
+	}
+}
+
+func getLintRules(cmd *cobra.Command) (map[string]bool, error) {
+	rules := make(map[string]bool)
+	for flag, enabled := range map[string]bool{"enable": true, "disable": false} {
+		ids, _ := cmd.Flags().GetStringSlice(flag)
+		for _, id := range ids {
+			if _, err := csvlint.LookupRule(id); err != nil {
+				return nil, err
 			}
-			os.Exit(1)
+			rules[id] = enabled
 		}
+	}
+	return rules, nil
+}
 
-		fmt.Println("✓ CSV file is valid")
-	},
+func listRules() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RULE\tSEVERITY\tDEFAULT\tDESCRIPTION")
+	for _, rule := range csvlint.Rules {
+		enabled := "on"
+		if !rule.Default {
+			enabled = "off"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", rule.ID, rule.Severity, enabled, rule.Description)
+	}
+	w.Flush()
+}
+
+func diffPath(filename string) string {
+	name := filepath.Clean(filename)
+	if filepath.IsAbs(name) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, name); err == nil && !strings.HasPrefix(rel, "..") {
+				name = rel
+			}
+		}
+	}
+	return strings.TrimPrefix(filepath.ToSlash(name), "/")
 }
 
 func init() {
 	rootCmd.AddCommand(lintCmd)
-	lintCmd.Flags().StringP("delimiter", "d", ",", "Field delimiter")
+	lintCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
 	lintCmd.Flags().Bool("lazy-quotes", false, "Allow lazy quotes (less strict parsing)")
+	lintCmd.Flags().Int("max-errors", 0, "Stop after this many errors and warnings (0 for no limit)")
+	lintCmd.Flags().StringSlice("enable", nil, "Rules to enable in addition to the defaults (comma-separated)")
+	lintCmd.Flags().StringSlice("disable", nil, "Rules to disable (comma-separated)")
+	lintCmd.Flags().Bool("list-rules", false, "List the available rules and exit")
+	lintCmd.Flags().String("report", "text", "Report format: text, json, sarif or junit")
+	lintCmd.Flags().StringP("output", "o", "", "Write the report to a file instead of stdout")
+	lintCmd.Flags().Bool("fix", false, "Rewrite the file, repairing what can be fixed safely")
+	lintCmd.Flags().Bool("dry-run", false, "With --fix, print a diff of the changes instead of writing them")
+	lintCmd.Flags().String("ragged", "pad", "With --fix, how to repair rows with the wrong field count: pad, truncate, both or keep")
+	lintCmd.Flags().Bool("trim-space", false, "With --fix, trim leading and trailing whitespace in cells")
+	lintCmd.Flags().String("line-ending", "auto", "With --fix, line ending to write: lf, crlf or auto (the first line's)")
 }
@@ -34,17 +34,18 @@ The target index is 0-based.`,
 			os.Exit(1)
 		}
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = getDelimiter(cmd)
+		config := getConfig(cmd)
 
-		csv, err := csvparser.ParseFile(filename, config)
+		reader := openInput(filename, config)
+		csv, err := csvparser.ReadAll(reader)
+		reader.Close()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
 			os.Exit(1)
 		}
 
-		err = csveditor.MoveColumn(csv, columnName, targetIndex)
-		if err != nil {
+		journal := csveditor.NewJournal(csv)
+		if _, err := journal.Apply(&csveditor.MoveColumnOp{Column: columnName, To: targetIndex}); err != nil {
 			fmt.Fprintf(os.Stderr, "Error moving column: %v\n", err)
 			os.Exit(1)
 		}
@@ -54,11 +55,14 @@ The target index is 0-based.`,
 			output = filename
 		}
 
-		err = csv.WriteToFile(output, config)
-		if err != nil {
+		writer := openOutput(cmd, output, config)
+		if err := csv.WriteAll(writer); err != nil {
+			abortOutput(writer)
 			fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
 			os.Exit(1)
 		}
+		closeOutput(writer)
+		writeJournal(cmd, journal.Entries())
 
 		fmt.Printf("Moved column '%s' to index %d in %s\n", columnName, targetIndex, output)
 	},
@@ -87,17 +91,18 @@ Both indices are 0-based and refer to data rows (excluding the header).`,
 			os.Exit(1)
 		}
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = getDelimiter(cmd)
+		config := getConfig(cmd)
 
-		csv, err := csvparser.ParseFile(filename, config)
+		reader := openInput(filename, config)
+		csv, err := csvparser.ReadAll(reader)
+		reader.Close()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
 			os.Exit(1)
 		}
 
-		err = csveditor.MoveRow(csv, oldIndex, newIndex)
-		if err != nil {
+		journal := csveditor.NewJournal(csv)
+		if _, err := journal.Apply(&csveditor.MoveRowOp{From: oldIndex, To: newIndex}); err != nil {
 			fmt.Fprintf(os.Stderr, "Error moving row: %v\n", err)
 			os.Exit(1)
 		}
@@ -107,11 +112,14 @@ Both indices are 0-based and refer to data rows (excluding the header).`,
 			output = filename
 		}
 
-		err = csv.WriteToFile(output, config)
-		if err != nil {
+		writer := openOutput(cmd, output, config)
+		if err := csv.WriteAll(writer); err != nil {
+			abortOutput(writer)
 			fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
 			os.Exit(1)
 		}
+		closeOutput(writer)
+		writeJournal(cmd, journal.Entries())
 
 		fmt.Printf("Moved row %d to index %d in %s\n", oldIndex, newIndex, output)
 	},
@@ -122,9 +130,11 @@ func init() {
 	moveCmd.AddCommand(moveColumnCmd)
 	moveCmd.AddCommand(moveRowCmd)
 
-	moveColumnCmd.Flags().StringP("delimiter", "d", ",", "Field delimiter")
+	moveColumnCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
 	moveColumnCmd.Flags().StringP("output", "o", "", "Output file (defaults to input file)")
+	moveColumnCmd.Flags().String("journal", "", "Write the edit and its inverse to this journal file")
 
-	moveRowCmd.Flags().StringP("delimiter", "d", ",", "Field delimiter")
+	moveRowCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
 	moveRowCmd.Flags().StringP("output", "o", "", "Output file (defaults to input file)")
+	moveRowCmd.Flags().String("journal", "", "Write the edit and its inverse to this journal file")
 }
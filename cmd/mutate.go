@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"sean-stapleton-doyle/csvtk/pkg/csveditor"
+
+	"github.com/spf13/cobra"
+)
+
+var mutateCmd = &cobra.Command{
+	Use:   "mutate [file]",
+	Short: "Add or overwrite columns computed from expressions",
+	Long: `Add or overwrite columns with values computed from an expression over
+each row. Each -e takes the form "column = expression"; an existing column is
+overwritten and a new one is appended. Assignments run in order, so later
+expressions can use columns computed by earlier ones.
+
+Expressions support:
+  + - * / %            arithmetic; + adds when either side is a number (a
+                       literal, number() or arithmetic result) and joins text
+  == != > < >= <=      comparison; numeric when both sides are numbers
+  =~ !~ contains startswith endswith
+  && || !              logic (also and, or, not)
+  cond ? a : b         conditional, also if(cond, a, b)
+
+Functions:
+  upper, lower, trim, len, substr(s, start[, n]), replace(s, old, new), concat
+  round(x[, digits]), floor, ceil, abs, min, max, number, coalesce
+  date(s[, format]), format_date(d, format), year, month, day
+  extract(s, regex[, group])
+
+Cells are text, so "02134" + "12" is "0213412", while Age + 1 adds and fails
+when Age is not a number. Date formats use strftime directives such as %d/%m/%Y. Empty
+cells are null: arithmetic on null gives null and coalesce picks the first
+non-empty value.
+
+--on-error decides what happens when a row cannot be evaluated, such as a
+non-numeric value in arithmetic: fail stops (default), null leaves the cell
+empty and skip drops the row. Problems are reported on stderr.
+
+Examples:
+  csvtk mutate -e 'Total = Price * Qty' data.csv
+  csvtk mutate -e 'Name = upper(trim(Name))' data.csv
+  csvtk mutate -e 'Full = First + " " + Last' -e 'Initials = substr(First, 1, 1) + substr(Last, 1, 1)' data.csv
+  csvtk mutate -e 'Band = Age >= 65 ? "senior" : "adult"' data.csv
+  csvtk mutate -e 'Month = format_date(date(Created, "%d/%m/%Y"), "%Y-%m")' data.csv
+  csvtk mutate -e 'Domain = extract(Email, "@(.+)$")' --on-error null data.csv`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		filename := "-"
+		if len(args) == 1 {
+			filename = args[0]
+		}
+
+		assignments, _ := cmd.Flags().GetStringArray("expr")
+		if len(assignments) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: mutate requires at least one -e 'column = expression'")
+			os.Exit(1)
+		}
+		onError, _ := cmd.Flags().GetString("on-error")
+		mode, err := csveditor.ParseErrorMode(onError)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		config := getConfig(cmd)
+		reader := openInput(filename, config)
+		defer reader.Close()
+
+		mutator, err := csveditor.NewMutator(reader.Header(), assignments, mode)
+		if err != nil {
+			printExprError("expression", err)
+			os.Exit(1)
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		writer := openOutput(cmd, output, config)
+
+		reported := 0
+		failed, err := csveditor.MutateStream(reader, writer, mutator, func(err error) {
+			if reported < maxReportedErrors {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			reported++
+		})
+		if err != nil {
+			abortOutput(writer)
+			fmt.Fprintf(os.Stderr, "Error computing columns: %v\n", err)
+			os.Exit(1)
+		}
+		closeOutput(writer)
+
+		if failed > 0 {
+			action := "left empty"
+			if mode == csveditor.ErrorSkip {
+				action = "skipped"
+			}
+			fmt.Fprintf(os.Stderr, "%d rows could not be evaluated and were %s\n", failed, action)
+		}
+	},
+}
+
+const maxReportedErrors = 10
+
+func init() {
+	rootCmd.AddCommand(mutateCmd)
+	mutateCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
+	mutateCmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
+	mutateCmd.Flags().StringArrayP("expr", "e", nil, "Assignment 'column = expression' (repeatable)")
+	mutateCmd.Flags().String("on-error", "fail", "On a row error: fail, null or skip")
+}
@@ -5,7 +5,6 @@ import (
 	"os"
 
 	"sean-stapleton-doyle/csvtk/pkg/csveditor"
-	"sean-stapleton-doyle/csvtk/pkg/csvparser"
 
 	"github.com/spf13/cobra"
 )
@@ -30,35 +29,27 @@ Examples:
 			filename = "-"
 		}
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = getDelimiter(cmd)
+		config := getConfig(cmd)
 
-		csv, err := csvparser.ParseFromFileOrStdin(filename, config)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
-			os.Exit(1)
-		}
+		reader := openInput(filename, config)
+		defer reader.Close()
+
+		output, _ := cmd.Flags().GetString("output")
+		writer := openOutput(cmd, output, config)
 
-		err = csveditor.RenameHeader(csv, oldName, newName)
+		err := csveditor.RenameHeaderStream(reader, writer, oldName, newName)
 		if err != nil {
+			abortOutput(writer)
 			fmt.Fprintf(os.Stderr, "Error renaming header: %v\n", err)
 			os.Exit(1)
 		}
+		closeOutput(writer)
+		writeJournal(cmd, []csveditor.Entry{{
+			Do:   &csveditor.RenameColumnOp{Column: oldName, Name: newName},
+			Undo: &csveditor.RenameColumnOp{Column: newName, Name: oldName},
+		}})
 
-		output, _ := cmd.Flags().GetString("output")
-		if output == "" || output == "-" {
-
-			err = csv.Write(os.Stdout, config)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
-				os.Exit(1)
-			}
-		} else {
-			err = csv.WriteToFile(output, config)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
-				os.Exit(1)
-			}
+		if !isStdout(output) {
 			fmt.Fprintf(os.Stderr, "Renamed header '%s' to '%s' in %s\n", oldName, newName, output)
 		}
 	},
@@ -66,6 +57,7 @@ Examples:
 
 func init() {
 	rootCmd.AddCommand(renameCmd)
-	renameCmd.Flags().StringP("delimiter", "d", ",", "Field delimiter")
+	renameCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
 	renameCmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
+	renameCmd.Flags().String("journal", "", "Write the edit and its inverse to this journal file")
 }
@@ -5,6 +5,9 @@ package cmd
 
 import (
 	"os"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvformat"
 
 	"github.com/spf13/cobra"
 )
@@ -38,4 +41,12 @@ func Execute() {
 func init() {
 
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.PersistentFlags().String("format", "csv", "Output format: "+strings.Join(csvformat.Names(), ", "))
+	rootCmd.PersistentFlags().String("table", "data", "Table name for --format sql")
+	rootCmd.PersistentFlags().String("dialect", "sqlite", "SQL dialect for --format sql: sqlite, postgres, mysql")
+	rootCmd.PersistentFlags().String("encoding", "", "Input character encoding: utf-8, utf-16le, utf-16be, windows-1252, latin1, ... (detected if not specified)")
+	rootCmd.PersistentFlags().String("output-encoding", "utf-8", "Output character encoding")
+	rootCmd.PersistentFlags().Bool("bom", false, "Write a byte order mark (helps Excel recognise UTF-8 and UTF-16 output)")
+	rootCmd.PersistentFlags().Bool("no-header", false, "Treat the first row as data and name the columns column1, column2, ...")
+	rootCmd.PersistentFlags().String("sheet", "", "Worksheet to read from .xlsx input (name or 1-based index) and to name in .xlsx output")
 }
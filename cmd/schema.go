@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+	"sean-stapleton-doyle/csvtk/pkg/csvschema"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Work with Table Schemas",
+}
+
+var schemaInferCmd = &cobra.Command{
+	Use:   "infer [file]",
+	Short: "Infer a Table Schema from a CSV file",
+	Long: `Scan a CSV file and write a Frictionless Table Schema describing it.
+
+Each column gets a type (integer, number, boolean, date, datetime, string or
+any for empty columns) and, for dates, the strftime format the values use.
+Columns without empty cells are marked required, columns without repeated
+values unique, and string columns with at most --enum-limit distinct values
+get an enum constraint.
+
+The schema is written as JSON, or as YAML with --format yaml or an -o file
+ending in .yaml or .yml. Feed it back into "csvtk validate --schema" or into
+"csvtk convert --schema" for typed JSON and SQL output.
+
+Examples:
+  csvtk schema infer data.csv
+  csvtk schema infer data.csv -o schema.yaml
+  csvtk schema infer huge.csv --sample 10000 --enum-limit 20`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var filename string
+		if len(args) > 0 {
+			filename = args[0]
+		}
+		output, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+		if format == "" {
+			format = "json"
+			if ext := strings.ToLower(filepath.Ext(csvparser.TrimCompression(output))); ext == ".yaml" || ext == ".yml" {
+				format = "yaml"
+			}
+		}
+
+		options := csvschema.DefaultInferOptions()
+		options.EnumLimit, _ = cmd.Flags().GetInt("enum-limit")
+		options.SampleSize, _ = cmd.Flags().GetInt("sample")
+
+		schema, err := csvschema.InferFile(filename, getConfig(cmd), options)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error inferring schema: %v\n", err)
+			os.Exit(1)
+		}
+
+		var out io.Writer = os.Stdout
+		var closer io.Closer
+		if !isStdout(output) {
+			if out, closer, err = csvparser.CreateOutput(output, "", false); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing schema: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		err = schema.Write(out, format)
+		if closer != nil {
+			if cerr := closer.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing schema: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.AddCommand(schemaInferCmd)
+	schemaInferCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
+	schemaInferCmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
+	schemaInferCmd.Flags().String("format", "", "Schema format: json or yaml (default json, or yaml for a .yaml/.yml output file)")
+	schemaInferCmd.Flags().Int("enum-limit", 10, "Maximum distinct values for a string column to get an enum constraint (0 to disable)")
+	schemaInferCmd.Flags().Int("sample", 0, "Only scan the first N rows (0 scans the whole file)")
+}
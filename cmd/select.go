@@ -3,10 +3,8 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"sean-stapleton-doyle/csvtk/pkg/csveditor"
-	"sean-stapleton-doyle/csvtk/pkg/csvparser"
 
 	"github.com/spf13/cobra"
 )
@@ -27,40 +25,25 @@ Example: csvtk select "Name,Email,Age" data.csv
 			filename = args[1]
 		}
 
-		columnNames := strings.Split(columnsStr, ",")
-		for i := range columnNames {
-			columnNames[i] = strings.TrimSpace(columnNames[i])
-		}
+		columnNames := splitColumns(columnsStr)
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = getDelimiter(cmd)
+		config := getConfig(cmd)
 
-		csv, err := csvparser.ParseFromFileOrStdin(filename, config)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
-			os.Exit(1)
-		}
+		reader := openInput(filename, config)
+		defer reader.Close()
 
-		selected, err := csveditor.SelectColumns(csv, columnNames)
+		output, _ := cmd.Flags().GetString("output")
+		writer := openOutput(cmd, output, config)
+
+		err := csveditor.SelectColumnsStream(reader, writer, columnNames)
 		if err != nil {
+			abortOutput(writer)
 			fmt.Fprintf(os.Stderr, "Error selecting columns: %v\n", err)
 			os.Exit(1)
 		}
+		closeOutput(writer)
 
-		output, _ := cmd.Flags().GetString("output")
-		if output == "" {
-
-			err = selected.Write(os.Stdout, config)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
-				os.Exit(1)
-			}
-		} else {
-			err = selected.WriteToFile(output, config)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
-				os.Exit(1)
-			}
+		if !isStdout(output) {
 			fmt.Fprintf(os.Stderr, "Selected %d columns to %s\n", len(columnNames), output)
 		}
 	},
@@ -68,6 +51,6 @@ Example: csvtk select "Name,Email,Age" data.csv
 
 func init() {
 	rootCmd.AddCommand(selectCmd)
-	selectCmd.Flags().StringP("delimiter", "d", ",", "Field delimiter")
+	selectCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
 	selectCmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
 }
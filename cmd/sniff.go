@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+
+	"github.com/spf13/cobra"
+)
+
+var sniffCmd = &cobra.Command{
+	Use:   "sniff [file]",
+	Short: "Detect the delimiter, quote character and header of a CSV file",
+	Long: `Sample the start of a file and report the delimiter, quote character and
+whether the first row is a header, together with how confident each guess is.
+
+Every command runs the same detection automatically when -d is not given.
+The first row is always read as the header; pass --no-header to treat it as
+data and name the columns column1, column2, ...
+
+Examples:
+  csvtk sniff data.csv
+  cat export.txt | csvtk sniff`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		filename := "-"
+		if len(args) > 0 {
+			filename = args[0]
+		}
+
+		result, err := csvparser.SniffFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error sniffing CSV: %v\n", err)
+			os.Exit(1)
+		}
+
+		header := "no"
+		if result.HasHeader {
+			header = "yes"
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "Delimiter:\t%s\t%s\n", describeRune(result.Delimiter), confidence(result.DelimiterConfidence))
+		fmt.Fprintf(tw, "Quote:\t%s\t%s\n", describeRune(result.Quote), confidence(result.QuoteConfidence))
+		fmt.Fprintf(tw, "Header:\t%s\t%s\n", header, confidence(result.HeaderConfidence))
+		fmt.Fprintf(tw, "Columns:\t%d\n", result.Columns)
+		fmt.Fprintf(tw, "Rows sampled:\t%d\n", result.Rows)
+		tw.Flush()
+	},
+}
+
+func describeRune(r rune) string {
+	switch r {
+	case ',':
+		return "',' (comma)"
+	case '\t':
+		return `'\t' (tab)`
+	case ';':
+		return "';' (semicolon)"
+	case '|':
+		return "'|' (pipe)"
+	case ':':
+		return "':' (colon)"
+	case '"':
+		return `'"' (double quote)`
+	case '\'':
+		return `'\'' (single quote)`
+	}
+	return strconv.QuoteRune(r)
+}
+
+func confidence(c float64) string {
+	if c == 0 {
+		return "(no evidence, default)"
+	}
+	return fmt.Sprintf("(confidence %.0f%%)", c*100)
+}
+
+func init() {
+	rootCmd.AddCommand(sniffCmd)
+}
@@ -3,78 +3,140 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"sean-stapleton-doyle/csvtk/pkg/csveditor"
-	"sean-stapleton-doyle/csvtk/pkg/csvparser"
 
 	"github.com/spf13/cobra"
 )
 
 var sortCmd = &cobra.Command{
 	Use:   "sort [column] [file]",
-	Short: "Sort a CSV file by a column",
-	Long: `Sort a CSV file by the values in a specified column.
+	Short: "Sort a CSV file by one or more columns",
+	Long: `Sort a CSV file by the values in a specified column, or by several
+keys given with -k. The sort is stable. Inputs larger than the memory budget
+are sorted in chunks that are spilled to temporary files and merged.
+
+A sort key is written as COLUMN[:TYPE][:asc|desc][:empty-first|empty-last].
+Types:
+  str      plain string comparison (default)
+  istr     case-insensitive string comparison
+  num      numeric comparison
+  natural  natural/version ordering ("file2" < "file10", "1.9" < "1.10")
+  date     ISO 8601 dates and timestamps
+  date=L   dates in a custom Go layout, e.g. date=02/01/2006
+
+Empty cells and values that cannot be parsed as the key type sort last
+unless --empty first or a per-key empty-first modifier is given.
 
 Examples:
   csvtk sort Age data.csv -o sorted.csv
-  cat data.csv | csvtk sort Name - > sorted.csv`,
-	Args: cobra.RangeArgs(1, 2),
+  cat data.csv | csvtk sort Name - > sorted.csv
+  csvtk sort -k Region -k Revenue:num:desc -k Date:date data.csv
+  csvtk sort -k "Created:date=02/01/2006 15:04" data.csv
+  csvtk sort Date huge.csv -S 512M -T /scratch -o sorted.csv`,
+	Args: cobra.RangeArgs(0, 2),
 	Run: func(cmd *cobra.Command, args []string) {
-		columnName := args[0]
+		keySpecs, _ := cmd.Flags().GetStringArray("key")
+		descending, _ := cmd.Flags().GetBool("descending")
+
+		var keys []csveditor.SortKey
+		if len(keySpecs) == 0 {
+			if len(args) == 0 {
+				fmt.Fprintf(os.Stderr, "Error: column name or --key required\n")
+				os.Exit(1)
+			}
+			keys = append(keys, csveditor.SortKey{ColumnName: args[0], Descending: descending})
+			args = args[1:]
+		} else if len(args) > 1 {
+			fmt.Fprintf(os.Stderr, "Error: only a file argument is allowed with --key\n")
+			os.Exit(1)
+		} else if descending {
+			fmt.Fprintf(os.Stderr, "Error: --descending cannot be combined with --key; add :desc to the key instead\n")
+			os.Exit(1)
+		}
+
+		for _, spec := range keySpecs {
+			key, err := csveditor.ParseSortKey(spec)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			keys = append(keys, key)
+		}
+
 		filename := "-"
-		if len(args) == 2 {
-			filename = args[1]
+		if len(args) == 1 {
+			filename = args[0]
 		}
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = getDelimiter(cmd)
+		config := getConfig(cmd)
 
-		csv, err := csvparser.ParseFromFileOrStdin(filename, config)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
+		sortConfig := csveditor.SortConfig{Keys: keys}
+
+		empty, _ := cmd.Flags().GetString("empty")
+		switch empty {
+		case "first":
+			sortConfig.Empty = csveditor.EmptyFirst
+		case "last", "":
+			sortConfig.Empty = csveditor.EmptyLast
+		default:
+			fmt.Fprintf(os.Stderr, "Error: --empty must be \"first\" or \"last\"\n")
 			os.Exit(1)
 		}
 
-		descending, _ := cmd.Flags().GetBool("descending")
+		bufferSize, _ := cmd.Flags().GetString("buffer-size")
+		memoryLimit, err := parseSize(bufferSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid buffer size: %v\n", err)
+			os.Exit(1)
+		}
+		tempDir, _ := cmd.Flags().GetString("temp-dir")
 
-		sortConfig := csveditor.SortConfig{
-			ColumnName: columnName,
-			Descending: descending,
+		options := csveditor.ExternalSortOptions{
+			MemoryLimit: memoryLimit,
+			TempDir:     tempDir,
 		}
 
-		err = csveditor.Sort(csv, sortConfig)
+		reader := openInput(filename, config)
+		defer reader.Close()
+
+		output, _ := cmd.Flags().GetString("output")
+		writer := openOutput(cmd, output, config)
+
+		err = csveditor.ExternalSort(reader, writer, sortConfig, options)
 		if err != nil {
+			abortOutput(writer)
 			fmt.Fprintf(os.Stderr, "Error sorting CSV: %v\n", err)
 			os.Exit(1)
 		}
+		closeOutput(writer)
 
-		output, _ := cmd.Flags().GetString("output")
-		direction := "ascending"
-		if descending {
-			direction = "descending"
+		if !isStdout(output) {
+			fmt.Fprintf(os.Stderr, "Sorted by %s in %s\n", describeSortKeys(keys), output)
 		}
+	},
+}
 
-		if output == "" || output == "-" {
-
-			err = csv.Write(os.Stdout, config)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
-				os.Exit(1)
-			}
-		} else {
-			err = csv.WriteToFile(output, config)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Fprintf(os.Stderr, "Sorted by column '%s' (%s) in %s\n", columnName, direction, output)
+func describeSortKeys(keys []csveditor.SortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		direction := "ascending"
+		if key.Descending {
+			direction = "descending"
 		}
-	},
+		parts[i] = fmt.Sprintf("'%s' (%s)", key.ColumnName, direction)
+	}
+	return strings.Join(parts, ", ")
 }
 
 func init() {
 	rootCmd.AddCommand(sortCmd)
-	sortCmd.Flags().StringP("delimiter", "d", ",", "Field delimiter")
+	sortCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
 	sortCmd.Flags().StringP("output", "o", "", "Output file (defaults to input file)")
-	sortCmd.Flags().BoolP("descending", "r", false, "Sort in descending order")
+	sortCmd.Flags().BoolP("descending", "r", false, "Sort the positional column in descending order (use :desc with --key)")
+	sortCmd.Flags().StringArrayP("key", "k", nil, "Sort key COLUMN[:TYPE][:asc|desc] (repeatable)")
+	sortCmd.Flags().String("empty", "last", "Where empty or unparseable values sort: first or last")
+	sortCmd.Flags().StringP("buffer-size", "S", "256M", "Memory budget before spilling sorted runs to disk (e.g. 64M, 2G)")
+	sortCmd.Flags().StringP("temp-dir", "T", "", "Directory for temporary sort files (defaults to the system temp directory)")
 }
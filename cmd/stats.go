@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvformat"
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [file]",
+	Short: "Profile every column of a CSV file",
+	Long: `Profile every column of a CSV file in a single pass.
+
+For each column this reports the inferred type, null/empty count, distinct
+count, min/max, mean and standard deviation, quantiles, min/max string length
+and the most frequent values. Use --approx on very large files to estimate
+distinct counts with HyperLogLog and compute quantiles and top values from
+bounded summaries instead of holding every value in memory.
+
+Examples:
+  csvtk stats data.csv
+  csvtk stats data.csv --format json -o profile.json
+  csvtk stats data.csv --format markdown
+  csvtk stats huge.csv --approx --top 10 --quantiles 0.5,0.9,0.99`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		filename := "-"
+		if len(args) > 0 {
+			filename = args[0]
+		}
+
+		options := csvstats.DefaultOptions()
+		options.Approximate, _ = cmd.Flags().GetBool("approx")
+		options.TopN, _ = cmd.Flags().GetInt("top")
+
+		if cmd.Flags().Changed("null") {
+			options.NullValues, _ = cmd.Flags().GetStringSlice("null")
+		}
+
+		quantiles, _ := cmd.Flags().GetString("quantiles")
+		options.Quantiles = nil
+		if quantiles != "" {
+			for _, q := range splitColumns(quantiles) {
+				p, err := strconv.ParseFloat(q, 64)
+				if err != nil || p < 0 || p > 1 {
+					fmt.Fprintf(os.Stderr, "Error: invalid quantile %q (must be between 0 and 1)\n", q)
+					os.Exit(1)
+				}
+				options.Quantiles = append(options.Quantiles, p)
+			}
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		if format != "table" && format != "json" {
+			if _, err := csvformat.Lookup(format); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		config := getConfig(cmd)
+
+		reader := openInput(filename, config)
+		defer reader.Close()
+
+		stats, err := csvstats.Profile(reader, options)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
+			os.Exit(1)
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if format != "table" && format != "json" {
+			writer := openOutput(cmd, output, csvparser.DefaultConfig())
+			if err := csvstats.WriteCSV(writer, stats); err != nil {
+				abortOutput(writer)
+				fmt.Fprintf(os.Stderr, "Error writing stats: %v\n", err)
+				os.Exit(1)
+			}
+			closeOutput(writer)
+			return
+		}
+
+		var out io.Writer = os.Stdout
+		var closer io.Closer
+		if !isStdout(output) {
+			if out, closer, err = csvparser.CreateOutput(output, "", false); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing stats: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if format == "json" {
+			err = csvstats.WriteJSON(out, stats)
+		} else {
+			err = csvstats.WriteTable(out, stats)
+		}
+		if err != nil && closer != nil {
+			csvparser.AbortOutput(closer)
+		} else if closer != nil {
+			err = closer.Close()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing stats: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
+	statsCmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
+	statsCmd.Flags().String("format", "table", "Output format: table, json or any --format writer (csv, markdown, html, ...)")
+	statsCmd.Flags().Bool("approx", false, "Use approximate distinct counts, quantiles and top values")
+	statsCmd.Flags().IntP("top", "n", 5, "Number of most frequent values to report per column")
+	statsCmd.Flags().String("quantiles", "0.25,0.5,0.75", "Comma-separated quantiles to compute for numeric columns")
+	statsCmd.Flags().StringSlice("null", []string{""}, "Values treated as null (repeatable)")
+}
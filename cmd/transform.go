@@ -27,7 +27,7 @@ Examples:
   cat data.csv | csvtk transform lower Name -`,
 	Args: cobra.RangeArgs(0, 2),
 	Run: func(cmd *cobra.Command, args []string) {
-		runTransform(cmd, args, csveditor.ToLower, "lowercase")
+		runTransform(cmd, args, "lower", "lowercase")
 	},
 }
 
@@ -42,7 +42,7 @@ Examples:
   cat data.csv | csvtk transform upper City -`,
 	Args: cobra.RangeArgs(0, 2),
 	Run: func(cmd *cobra.Command, args []string) {
-		runTransform(cmd, args, csveditor.ToUpper, "uppercase")
+		runTransform(cmd, args, "upper", "uppercase")
 	},
 }
 
@@ -79,31 +79,13 @@ Examples:
 			filename = "-"
 		}
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = getDelimiter(cmd)
-
-		csv, err := csvparser.ParseFromFileOrStdin(filename, config)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
-			os.Exit(1)
-		}
+		config := getConfig(cmd)
 
 		allCols, _ := cmd.Flags().GetBool("all")
-		transformFunc := csveditor.ReplaceAll(old, new)
-
-		if allCols || columnName == "" {
-			err = csveditor.TransformAll(csv, transformFunc)
-		} else {
-			err = csveditor.TransformColumn(csv, columnName, transformFunc)
-		}
-
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error transforming CSV: %v\n", err)
-			os.Exit(1)
-		}
+		op := &csveditor.TransformOp{Func: "replace", Args: []string{old, new}}
 
 		output, _ := cmd.Flags().GetString("output")
-		writeOutput(csv, output, config, "Replaced text in")
+		streamTransform(cmd, filename, columnName, allCols, op, output, config, "Replaced text in")
 	},
 }
 
@@ -117,11 +99,11 @@ Examples:
   csvtk transform trim --all data.csv`,
 	Args: cobra.RangeArgs(0, 2),
 	Run: func(cmd *cobra.Command, args []string) {
-		runTransform(cmd, args, csveditor.TrimSpace, "trimmed")
+		runTransform(cmd, args, "trim", "trimmed")
 	},
 }
 
-func runTransform(cmd *cobra.Command, args []string, transform csveditor.TransformFunc, operation string) {
+func runTransform(cmd *cobra.Command, args []string, name string, operation string) {
 	var columnName string
 	var filename string
 
@@ -141,46 +123,79 @@ func runTransform(cmd *cobra.Command, args []string, transform csveditor.Transfo
 		filename = "-"
 	}
 
-	config := csvparser.DefaultConfig()
-	config.Delimiter = getDelimiter(cmd)
+	config := getConfig(cmd)
 
-	csv, err := csvparser.ParseFromFileOrStdin(filename, config)
+	output, _ := cmd.Flags().GetString("output")
+	streamTransform(cmd, filename, columnName, allCols, &csveditor.TransformOp{Func: name}, output, config, fmt.Sprintf("Transformed to %s", operation))
+}
+
+func streamTransform(cmd *cobra.Command, filename, columnName string, allCols bool, op *csveditor.TransformOp, output string, config *csvparser.Config, successMsg string) {
+	transform, err := csveditor.NamedTransform(op.Func, op.Args...)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	reader := openInput(filename, config)
+	defer reader.Close()
+
+	writer := openOutput(cmd, output, config)
+
+	if journal, _ := cmd.Flags().GetString("journal"); journal != "" {
+		entries := journalTransform(reader, writer, columnName, allCols, op)
+		closeOutput(writer)
+		writeJournal(cmd, entries)
+		if !isStdout(output) {
+			fmt.Fprintf(os.Stderr, "%s %s\n", successMsg, output)
+		}
+		return
+	}
+
 	if allCols || columnName == "" {
-		err = csveditor.TransformAll(csv, transform)
+		err = csveditor.TransformAllStream(reader, writer, transform)
 	} else {
-		err = csveditor.TransformColumn(csv, columnName, transform)
+		err = csveditor.TransformColumnStream(reader, writer, columnName, transform)
 	}
 
 	if err != nil {
+		abortOutput(writer)
 		fmt.Fprintf(os.Stderr, "Error transforming CSV: %v\n", err)
 		os.Exit(1)
 	}
+	closeOutput(writer)
 
-	output, _ := cmd.Flags().GetString("output")
-	writeOutput(csv, output, config, fmt.Sprintf("Transformed to %s", operation))
+	if !isStdout(output) {
+		fmt.Fprintf(os.Stderr, "%s %s\n", successMsg, output)
+	}
 }
 
-func writeOutput(csv *csvparser.CSV, output string, config *csvparser.Config, successMsg string) {
-	if output == "" || output == "-" {
+func journalTransform(reader *csvparser.Reader, writer *csvparser.Writer, columnName string, allCols bool, op *csveditor.TransformOp) []csveditor.Entry {
+	csv, err := csvparser.ReadAll(reader)
+	if err != nil {
+		abortOutput(writer)
+		fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
+		os.Exit(1)
+	}
 
-		err := csv.Write(os.Stdout, config)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
-			os.Exit(1)
-		}
-	} else {
-		err := csv.WriteToFile(output, config)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
+	columns := []string{columnName}
+	if allCols || columnName == "" {
+		columns = csv.Header
+	}
+
+	journal := csveditor.NewJournal(csv)
+	for _, column := range columns {
+		if _, err := journal.Apply(&csveditor.TransformOp{Column: column, Func: op.Func, Args: op.Args}); err != nil {
+			abortOutput(writer)
+			fmt.Fprintf(os.Stderr, "Error transforming CSV: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "%s %s\n", successMsg, output)
 	}
+	if err := csv.WriteAll(writer); err != nil {
+		abortOutput(writer)
+		fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
+		os.Exit(1)
+	}
+	return journal.Entries()
 }
 
 func init() {
@@ -191,8 +206,9 @@ func init() {
 	transformCmd.AddCommand(transformTrimCmd)
 
 	for _, cmd := range []*cobra.Command{transformLowerCmd, transformUpperCmd, transformReplaceCmd, transformTrimCmd} {
-		cmd.Flags().StringP("delimiter", "d", ",", "Field delimiter")
+		cmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
 		cmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
 		cmd.Flags().Bool("all", false, "Apply transformation to all columns")
+		cmd.Flags().String("journal", "", "Write the edits and their inverses to this journal file (reads the file into memory)")
 	}
 }
@@ -1,23 +1,145 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvformat"
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+
 	"github.com/spf13/cobra"
 )
 
 func getDelimiter(cmd *cobra.Command) rune {
 	delimiterStr, _ := cmd.Flags().GetString("delimiter")
 
-	if delimiterStr == "" {
+	switch strings.ToLower(delimiterStr) {
+	case "":
 		return ','
+	case "\\t", "\t", "tab":
+		return '\t'
+	case "comma":
+		return ','
+	case "semicolon":
+		return ';'
+	case "pipe":
+		return '|'
+	case "space":
+		return ' '
 	}
 
-	if delimiterStr == "\\t" || delimiterStr == "\t" {
-		return '\t'
+	r, _ := utf8.DecodeRuneInString(delimiterStr)
+	return r
+}
+
+func getConfig(cmd *cobra.Command) *csvparser.Config {
+	config := csvparser.DefaultConfig()
+	config.Delimiter = getDelimiter(cmd)
+	config.Sniff = !cmd.Flags().Changed("delimiter")
+	config.Sheet, _ = cmd.Flags().GetString("sheet")
+	config.Encoding, _ = cmd.Flags().GetString("encoding")
+	config.NoHeader, _ = cmd.Flags().GetBool("no-header")
+	return config
+}
+
+func openInput(filename string, config *csvparser.Config) *csvparser.Reader {
+	reader, err := csvparser.OpenFileOrStdin(filename, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
+		os.Exit(1)
 	}
+	config.Delimiter = reader.Delimiter()
+	if encoding := reader.Encoding(); encoding != "" {
+		config.Encoding = encoding
+	}
+	return reader
+}
+
+func openOutput(cmd *cobra.Command, output string, config *csvparser.Config) *csvparser.Writer {
+	format, options, err := getOutputFormat(cmd, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !cmd.Flags().Changed("format") && csvparser.IsXLSX(output) {
+		format = "xlsx"
+	}
+
+	writer, err := csvformat.CreateFileOrStdout(format, output, options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
+		os.Exit(1)
+	}
+	return writer
+}
+
+func getOutputFormat(cmd *cobra.Command, config *csvparser.Config) (string, csvformat.Options, error) {
+	options := csvformat.DefaultOptions()
+	options.Delimiter = config.Delimiter
+	options.Encoding, _ = cmd.Flags().GetString("output-encoding")
+	options.BOM, _ = cmd.Flags().GetBool("bom")
 
-	if len(delimiterStr) > 0 {
-		return rune(delimiterStr[0])
+	format, _ := cmd.Flags().GetString("format")
+	if _, err := csvformat.Lookup(format); err != nil {
+		return "", options, err
 	}
 
-	return ','
+	options.Table, _ = cmd.Flags().GetString("table")
+	dialect, _ := cmd.Flags().GetString("dialect")
+	d, err := csvformat.ParseDialect(dialect)
+	if err != nil {
+		return "", options, err
+	}
+	options.Dialect = d
+
+	sheet, _ := cmd.Flags().GetString("sheet")
+	if _, err := strconv.Atoi(sheet); err != nil {
+		options.Sheet = sheet
+	}
+
+	return format, options, nil
+}
+
+func closeOutput(writer *csvparser.Writer) {
+	if err := writer.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func abortOutput(writer *csvparser.Writer) {
+	writer.Abort()
+}
+
+func isStdout(output string) bool {
+	return output == "" || output == "-"
+}
+
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimSuffix(s, "B")
+
+	multiplier := int64(1)
+	if len(s) > 0 {
+		switch s[len(s)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * multiplier, nil
 }
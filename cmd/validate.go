@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvschema"
+
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate a CSV file against a Table Schema",
+	Long: `Validate the contents of a CSV file against a Frictionless Table Schema
+(https://specs.frictionlessdata.io/table-schema/) in JSON or YAML.
+
+The header is checked for missing, extra and out-of-order columns (see the
+schema's fieldsMatch). Every value is checked against its field's type and
+format (string, integer, number, boolean, date, datetime, time, year, any)
+and constraints: required, unique, enum, pattern, minimum, maximum,
+minLength and maxLength. primaryKey values must be present and unique.
+Values listed in missingValues (default: the empty string) count as missing.
+
+Date formats use strftime directives such as %d/%m/%Y, or "any" to accept
+common layouts.
+
+Problems are reported with the line, column and record number like lint,
+and --report json, sarif or junit writes a machine-readable report. The exit
+code is 0 for a valid file and 2 otherwise.
+
+Examples:
+  csvtk validate data.csv --schema schema.json
+  csvtk validate data.csv --schema schema.json --max-errors 20
+  csvtk validate data.csv --schema schema.json --report junit -o results.xml`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var filename string
+		if len(args) > 0 {
+			filename = args[0]
+		}
+
+		schemaFile, _ := cmd.Flags().GetString("schema")
+		schema, err := csvschema.Load(schemaFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		write := getReportWriter(cmd)
+
+		var options csvschema.Options
+		options.MaxErrors, _ = cmd.Flags().GetInt("max-errors")
+		problems, err := csvschema.ValidateFile(filename, getConfig(cmd), schema, options)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error validating file: %v\n", err)
+			os.Exit(2)
+		}
+
+		name := filename
+		if isStdout(name) {
+			name = "stdin"
+		}
+		writeReport(cmd, write, csvschema.NewReport(name, options, problems))
+		if len(problems) > 0 {
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
+	validateCmd.Flags().StringP("schema", "s", "", "Table Schema file (JSON or YAML)")
+	validateCmd.Flags().Int("max-errors", 0, "Stop after this many problems (0 for no limit)")
+	validateCmd.Flags().String("report", "text", "Report format: text, json, sarif or junit")
+	validateCmd.Flags().StringP("output", "o", "", "Write the report to a file instead of stdout")
+	validateCmd.MarkFlagRequired("schema")
+}
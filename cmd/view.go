@@ -13,30 +13,58 @@ import (
 var viewCommand = &cobra.Command{
 	Use:   "view [file]",
 	Short: "View a CSV file in an interactive terminal viewer",
-	Long: `Open a CSV file in an interactive terminal viewer with keyboard navigation.
+	Long: `Open a CSV file in an interactive terminal viewer with keyboard navigation
+and in-place editing.
 
 Keyboard shortcuts:
-  ↑/k: Move up one row
-  ↓/j: Move down one row
-  PgUp: Move up one page
-  PgDn: Move down one page
-  g/home: Go to first row
-  G/end: Go to last row
-  q: Quit viewer`,
+  ↑/k, ↓/j: Move up/down one row
+  ←/h, →/l: Move left/right one column
+  PgUp/PgDn: Move up/down one page
+  g/home, G/end: Go to first/last row
+  enter/e: Edit the focused cell (Enter applies, Esc cancels)
+  E: Rename the focused column
+  o/O: Insert a row below/above
+  D: Delete the row
+  a: Insert a column to the right
+  X: Delete the column
+  u, ctrl+r: Undo, redo
+  ctrl+s, :w: Save to the file (:w FILE saves a copy)
+  :wq, :q, :q!: Save and quit, quit, quit discarding changes
+  c: Copy the row to the clipboard
+  |: Freeze the columns up to the focused one (press again to unfreeze)
+  >/<: Widen/narrow the focused column, =: back to automatic width
+  -: Hide the focused column, +: show all hidden columns
+  w: Toggle wrapping long cells instead of truncating them
+  v: Toggle a pane showing the selected row as name: value pairs
+  s: Sort by the focused column (press again to reverse), S: clear the sort
+  /: Search as you type (ctrl+r toggles regex, ctrl+t case-sensitivity)
+  n/N: Jump to the next/previous match, Esc: clear the search
+  f: Filter rows containing text in any column
+  F: Filter the focused column (stacks with other filters)
+  R: Drop the last column filter, r: clear all filters
+  q: Quit viewer (asks to save unsaved changes)
+
+Edits are saved with the file's detected delimiter. Excel workbooks can be
+edited but only saved to a CSV file with :w FILE.csv.
+
+Column filters take an operator and a value, as in the filter command:
+"text" (contains), "= text", "ne text", "starts-with text", "ends-with text",
+"=~ regex", and numeric ">10", ">=10", "<10", "<=10", "== 10", "!= 10".`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		filename := args[0]
 
-		config := csvparser.DefaultConfig()
-		config.Delimiter = getDelimiter(cmd)
+		config := getConfig(cmd)
 
-		csv, err := csvparser.ParseFile(filename, config)
+		reader := openInput(filename, config)
+		csv, err := csvparser.ReadAll(reader)
+		reader.Close()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing CSV: %v\n", err)
 			os.Exit(1)
 		}
 
-		err = csvviewer.Run(csv, filename)
+		err = csvviewer.Run(csv, filename, getSaveConfig(cmd, config))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error running viewer: %v\n", err)
 			os.Exit(1)
@@ -44,7 +72,17 @@ Keyboard shortcuts:
 	},
 }
 
+func getSaveConfig(cmd *cobra.Command, config *csvparser.Config) *csvparser.Config {
+	save := *config
+	save.Sniff = false
+	save.OutputEncoding = config.Encoding
+	if cmd.Flags().Changed("output-encoding") || save.OutputEncoding == "" {
+		save.OutputEncoding, _ = cmd.Flags().GetString("output-encoding")
+	}
+	return &save
+}
+
 func init() {
 	rootCmd.AddCommand(viewCommand)
-	viewCommand.Flags().StringP("delimiter", "d", ",", "Field delimiter")
+	viewCommand.Flags().StringP("delimiter", "d", "", "Field delimiter (auto-detected if not specified)")
 }
@@ -0,0 +1,435 @@
+package csveditor
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type NonNumericMode int
+
+const (
+	NonNumericSkip NonNumericMode = iota
+	NonNumericError
+)
+
+type Aggregation struct {
+	Function   string
+	ColumnName string
+	Percentile float64
+	Separator  string
+	As         string
+}
+
+type AggregateConfig struct {
+	GroupBy      []string
+	Aggregations []Aggregation
+	NonNumeric   NonNumericMode
+}
+
+var aggregateFunctions = map[string]bool{
+	"count":          true,
+	"count-distinct": true,
+	"sum":            true,
+	"mean":           true,
+	"min":            true,
+	"max":            true,
+	"median":         true,
+	"first":          true,
+	"last":           true,
+	"join":           true,
+}
+
+var percentileFunction = regexp.MustCompile(`^p[0-9]+(\.[0-9]+)?$`)
+
+func ParseAggregation(spec string) (Aggregation, error) {
+	var agg Aggregation
+
+	if name, rest, ok := strings.Cut(spec, "="); ok && !strings.Contains(name, ":") {
+		agg.As = name
+		spec = rest
+	}
+
+	parts := strings.SplitN(spec, ":", 3)
+	agg.Function = strings.ToLower(parts[0])
+	if len(parts) > 1 {
+		agg.ColumnName = parts[1]
+	}
+
+	switch agg.Function {
+	case "avg":
+		agg.Function = "mean"
+	case "distinct", "nunique":
+		agg.Function = "count-distinct"
+	case "concat":
+		agg.Function = "join"
+	}
+
+	if percentileFunction.MatchString(agg.Function) {
+		p, err := strconv.ParseFloat(agg.Function[1:], 64)
+		if err != nil || p < 0 || p > 100 {
+			return agg, fmt.Errorf("invalid aggregation %q: bad percentile %q", spec, agg.Function)
+		}
+		agg.Function = "percentile"
+		agg.Percentile = p
+	} else if !aggregateFunctions[agg.Function] {
+		return agg, fmt.Errorf("invalid aggregation %q: unknown function %q", spec, parts[0])
+	}
+
+	if agg.Function == "join" {
+		agg.Separator = ","
+		if len(parts) == 3 {
+			agg.Separator = parts[2]
+		}
+	} else if len(parts) == 3 {
+		return agg, fmt.Errorf("invalid aggregation %q: unexpected argument %q", spec, parts[2])
+	}
+
+	if agg.ColumnName == "" && agg.Function != "count" {
+		return agg, fmt.Errorf("invalid aggregation %q: %s requires a column", spec, agg.Function)
+	}
+
+	return agg, nil
+}
+
+func (a Aggregation) outputName() string {
+	if a.As != "" {
+		return a.As
+	}
+	name := a.Function
+	if a.Function == "percentile" {
+		name = "p" + formatNumber(a.Percentile)
+	}
+	if a.ColumnName == "" {
+		return name
+	}
+	return name + "_" + a.ColumnName
+}
+
+func (a Aggregation) numeric() bool {
+	switch a.Function {
+	case "sum", "mean", "min", "max", "median", "percentile":
+		return true
+	}
+	return false
+}
+
+type aggregator interface {
+	add(value string)
+	result() string
+}
+
+type countAggregator struct {
+	n         int
+	skipEmpty bool
+}
+
+func (a *countAggregator) add(value string) {
+	if a.skipEmpty && value == "" {
+		return
+	}
+	a.n++
+}
+
+func (a *countAggregator) result() string { return strconv.Itoa(a.n) }
+
+type distinctAggregator struct {
+	seen map[string]struct{}
+}
+
+func (a *distinctAggregator) add(value string) {
+	if value != "" {
+		a.seen[value] = struct{}{}
+	}
+}
+
+func (a *distinctAggregator) result() string { return strconv.Itoa(len(a.seen)) }
+
+type sumAggregator struct {
+	sum   float64
+	count int
+	mean  bool
+}
+
+func (a *sumAggregator) add(value string) {
+	f, _ := strconv.ParseFloat(value, 64)
+	a.sum += f
+	a.count++
+}
+
+func (a *sumAggregator) result() string {
+	if !a.mean {
+		return formatNumber(a.sum)
+	}
+	if a.count == 0 {
+		return ""
+	}
+	return formatNumber(a.sum / float64(a.count))
+}
+
+type extremeAggregator struct {
+	value float64
+	set   bool
+	max   bool
+}
+
+func (a *extremeAggregator) add(value string) {
+	f, _ := strconv.ParseFloat(value, 64)
+	if !a.set || (a.max && f > a.value) || (!a.max && f < a.value) {
+		a.value = f
+		a.set = true
+	}
+}
+
+func (a *extremeAggregator) result() string {
+	if !a.set {
+		return ""
+	}
+	return formatNumber(a.value)
+}
+
+type percentileAggregator struct {
+	values     []float64
+	percentile float64
+}
+
+func (a *percentileAggregator) add(value string) {
+	f, _ := strconv.ParseFloat(value, 64)
+	a.values = append(a.values, f)
+}
+
+func (a *percentileAggregator) result() string {
+	if len(a.values) == 0 {
+		return ""
+	}
+	slices.Sort(a.values)
+	return formatNumber(percentile(a.values, a.percentile))
+}
+
+type firstLastAggregator struct {
+	value string
+	set   bool
+	last  bool
+}
+
+func (a *firstLastAggregator) add(value string) {
+	if !a.set || a.last {
+		a.value = value
+		a.set = true
+	}
+}
+
+func (a *firstLastAggregator) result() string { return a.value }
+
+type joinAggregator struct {
+	values    []string
+	separator string
+}
+
+func (a *joinAggregator) add(value string) {
+	a.values = append(a.values, value)
+}
+
+func (a *joinAggregator) result() string { return strings.Join(a.values, a.separator) }
+
+func newAggregator(a Aggregation) aggregator {
+	switch a.Function {
+	case "count":
+		return &countAggregator{skipEmpty: a.ColumnName != ""}
+	case "count-distinct":
+		return &distinctAggregator{seen: make(map[string]struct{})}
+	case "sum":
+		return &sumAggregator{}
+	case "mean":
+		return &sumAggregator{mean: true}
+	case "min":
+		return &extremeAggregator{}
+	case "max":
+		return &extremeAggregator{max: true}
+	case "median":
+		return &percentileAggregator{percentile: 50}
+	case "first":
+		return &firstLastAggregator{}
+	case "last":
+		return &firstLastAggregator{last: true}
+	case "join":
+		return &joinAggregator{separator: a.Separator}
+	case "percentile":
+		return &percentileAggregator{percentile: a.Percentile}
+	}
+	return nil
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+type aggregateGroup struct {
+	keys        []string
+	aggregators []aggregator
+}
+
+type aggregateState struct {
+	config      AggregateConfig
+	groupIdx    []int
+	columnIdx   []int
+	groups      map[string]*aggregateGroup
+	order       []*aggregateGroup
+	header      []string
+	recordCount int
+}
+
+func newAggregateState(header []string, config AggregateConfig) (*aggregateState, error) {
+	if len(config.Aggregations) == 0 {
+		return nil, fmt.Errorf("at least one aggregation is required")
+	}
+
+	groupIdx, err := columnIndices(header, config.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &aggregateState{
+		config:   config,
+		groupIdx: groupIdx,
+		groups:   make(map[string]*aggregateGroup),
+		header:   append([]string{}, config.GroupBy...),
+	}
+
+	for _, agg := range config.Aggregations {
+		index := -1
+		if agg.ColumnName != "" {
+			index, err = columnIndex(header, agg.ColumnName)
+			if err != nil {
+				return nil, err
+			}
+		}
+		state.columnIdx = append(state.columnIdx, index)
+		state.header = append(state.header, agg.outputName())
+	}
+
+	return state, nil
+}
+
+func (s *aggregateState) add(record []string) error {
+	s.recordCount++
+
+	key := joinKey(record, s.groupIdx)
+	group, ok := s.groups[key]
+	if !ok {
+		group = s.newGroup(record)
+		s.groups[key] = group
+	}
+
+	for i, agg := range s.config.Aggregations {
+		var value string
+		if s.columnIdx[i] >= 0 {
+			value = cell(record, s.columnIdx[i])
+		}
+
+		if agg.numeric() {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				if s.config.NonNumeric == NonNumericError {
+					return fmt.Errorf("row %d: column %q: value %q is not numeric", s.recordCount, agg.ColumnName, value)
+				}
+				continue
+			}
+		}
+
+		group.aggregators[i].add(value)
+	}
+
+	return nil
+}
+
+func (s *aggregateState) results(emit func([]string) error) error {
+	if len(s.order) == 0 && len(s.groupIdx) == 0 {
+		s.newGroup(nil)
+	}
+
+	for _, group := range s.order {
+		row := append([]string{}, group.keys...)
+		for _, agg := range group.aggregators {
+			row = append(row, agg.result())
+		}
+		if err := emit(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *aggregateState) newGroup(record []string) *aggregateGroup {
+	group := &aggregateGroup{keys: pick(record, s.groupIdx)}
+	for _, agg := range s.config.Aggregations {
+		group.aggregators = append(group.aggregators, newAggregator(agg))
+	}
+	s.order = append(s.order, group)
+	return group
+}
+
+func Aggregate(csv *csvparser.CSV, config AggregateConfig) (*csvparser.CSV, error) {
+	state, err := newAggregateState(csv.Header, config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range csv.Records {
+		if err := state.add(record); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &csvparser.CSV{
+		Header:  state.header,
+		Records: [][]string{},
+	}
+	err = state.results(func(row []string) error {
+		result.Records = append(result.Records, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func AggregateStream(r *csvparser.Reader, w *csvparser.Writer, config AggregateConfig) error {
+	state, err := newAggregateState(r.Header(), config)
+	if err != nil {
+		return err
+	}
+
+	if err := r.ForEach(state.add); err != nil {
+		return err
+	}
+
+	if err := w.WriteHeader(state.header); err != nil {
+		return err
+	}
+
+	return state.results(w.Write)
+}
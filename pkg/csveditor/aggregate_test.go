@@ -0,0 +1,147 @@
+package csveditor
+
+import (
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+func TestParseAggregation(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Aggregation
+		wantErr bool
+	}{
+		{"count", Aggregation{Function: "count"}, false},
+		{"sum:Revenue", Aggregation{Function: "sum", ColumnName: "Revenue"}, false},
+		{"avg:Revenue", Aggregation{Function: "mean", ColumnName: "Revenue"}, false},
+		{"p95:Latency", Aggregation{Function: "percentile", ColumnName: "Latency", Percentile: 95}, false},
+		{"p99.9:Latency", Aggregation{Function: "percentile", ColumnName: "Latency", Percentile: 99.9}, false},
+		{"names=join:Name:; ", Aggregation{Function: "join", ColumnName: "Name", Separator: "; ", As: "names"}, false},
+		{"join:Name", Aggregation{Function: "join", ColumnName: "Name", Separator: ","}, false},
+		{"sum", Aggregation{}, true},
+		{"p101:Latency", Aggregation{}, true},
+		{"mode:Name", Aggregation{}, true},
+		{"sum:Revenue:extra", Aggregation{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAggregation(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAggregation(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseAggregation(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseAggregationErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"p101:Latency", `bad percentile "p101"`},
+		{"p99.9.9:Latency", `unknown function "p99.9.9"`},
+		{"product:Revenue", `unknown function "product"`},
+		{"p:Revenue", `unknown function "p"`},
+		{"pct:Revenue", `unknown function "pct"`},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseAggregation(tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseAggregation(%q) error = %v, want %s", tt.spec, err, tt.want)
+		}
+	}
+}
+
+func aggregateFixture() *csvparser.CSV {
+	return &csvparser.CSV{
+		Header: []string{"Region", "Rep", "Revenue"},
+		Records: [][]string{
+			{"EU", "a", "10"},
+			{"US", "b", "5"},
+			{"EU", "c", "20"},
+			{"EU", "a", "n/a"},
+			{"US", "d", ""},
+			{"EU", "e", "30"},
+		},
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	specs := []string{"count", "count-distinct:Rep", "sum:Revenue", "mean:Revenue", "min:Revenue",
+		"max:Revenue", "median:Revenue", "p25:Revenue", "first:Rep", "last:Rep", "join:Rep:;"}
+
+	config := AggregateConfig{GroupBy: []string{"Region"}}
+	for _, spec := range specs {
+		agg, err := ParseAggregation(spec)
+		if err != nil {
+			t.Fatalf("ParseAggregation(%q) error = %v", spec, err)
+		}
+		config.Aggregations = append(config.Aggregations, agg)
+	}
+
+	result, err := Aggregate(aggregateFixture(), config)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+
+	wantHeader := "Region,count,count-distinct_Rep,sum_Revenue,mean_Revenue,min_Revenue,max_Revenue,median_Revenue,p25_Revenue,first_Rep,last_Rep,join_Rep"
+	if got := strings.Join(result.Header, ","); got != wantHeader {
+		t.Errorf("Aggregate() header = %s, want %s", got, wantHeader)
+	}
+
+	want := []string{
+		"EU,4,3,60,20,10,30,20,15,a,e,a;c;a;e",
+		"US,2,2,5,5,5,5,5,5,b,d,b;d",
+	}
+	if len(result.Records) != len(want) {
+		t.Fatalf("Aggregate() got %d groups, want %d", len(result.Records), len(want))
+	}
+	for i, record := range result.Records {
+		if got := strings.Join(record, ","); got != want[i] {
+			t.Errorf("Aggregate() row %d = %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestAggregateNonNumericError(t *testing.T) {
+	config := AggregateConfig{
+		GroupBy:      []string{"Region"},
+		Aggregations: []Aggregation{{Function: "sum", ColumnName: "Revenue"}},
+		NonNumeric:   NonNumericError,
+	}
+
+	if _, err := Aggregate(aggregateFixture(), config); err == nil {
+		t.Error("Aggregate() expected error for non-numeric value, got nil")
+	}
+}
+
+func TestAggregateStreamWithoutGroups(t *testing.T) {
+	r, err := csvparser.NewReader(strings.NewReader("Revenue\n1\n2\n3\n"), nil)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	var buf strings.Builder
+	w := csvparser.NewWriter(&buf, nil)
+	config := AggregateConfig{Aggregations: []Aggregation{
+		{Function: "count"},
+		{Function: "sum", ColumnName: "Revenue", As: "total"},
+	}}
+	if err := AggregateStream(r, w, config); err != nil {
+		t.Fatalf("AggregateStream() error = %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	expected := "count,total\n3,6\n"
+	if buf.String() != expected {
+		t.Errorf("AggregateStream() output = %q, want %q", buf.String(), expected)
+	}
+}
@@ -63,6 +63,98 @@ func MoveRow(csv *csvparser.CSV, oldIndex, newIndex int) error {
 	return nil
 }
 
+func SetCell(csv *csvparser.CSV, row, col int, value string) (string, error) {
+	if row < 0 || row >= len(csv.Records) {
+		return "", fmt.Errorf("row index %d is out of range (0-%d)", row, len(csv.Records)-1)
+	}
+	if col < 0 || col >= len(csv.Header) {
+		return "", fmt.Errorf("column index %d is out of range (0-%d)", col, len(csv.Header)-1)
+	}
+
+	record := csv.Records[row]
+	for len(record) <= col {
+		record = append(record, "")
+	}
+	old := record[col]
+	record[col] = value
+	csv.Records[row] = record
+	return old, nil
+}
+
+func InsertRow(csv *csvparser.CSV, index int, record []string) error {
+	if index < 0 || index > len(csv.Records) {
+		return fmt.Errorf("row index %d is out of range (0-%d)", index, len(csv.Records))
+	}
+
+	if record == nil {
+		record = make([]string, len(csv.Header))
+	}
+	csv.Records = append(csv.Records[:index], append([][]string{record}, csv.Records[index:]...)...)
+	return nil
+}
+
+func DeleteRow(csv *csvparser.CSV, index int) ([]string, error) {
+	if index < 0 || index >= len(csv.Records) {
+		return nil, fmt.Errorf("row index %d is out of range (0-%d)", index, len(csv.Records)-1)
+	}
+
+	record := csv.Records[index]
+	csv.Records = append(csv.Records[:index], csv.Records[index+1:]...)
+	return record, nil
+}
+
+func InsertColumn(csv *csvparser.CSV, index int, name string, values []string) error {
+	if index < 0 || index > len(csv.Header) {
+		return fmt.Errorf("column index %d is out of range (0-%d)", index, len(csv.Header))
+	}
+	if values != nil && len(values) != len(csv.Records) {
+		return fmt.Errorf("got %d values for %d rows", len(values), len(csv.Records))
+	}
+
+	csv.Header = insertAt(csv.Header, index, name)
+	for i, record := range csv.Records {
+		value := ""
+		if values != nil {
+			value = values[i]
+		}
+		for len(record) < index {
+			record = append(record, "")
+		}
+		csv.Records[i] = insertAt(record, index, value)
+	}
+	return nil
+}
+
+func DeleteColumn(csv *csvparser.CSV, index int) (string, []string, error) {
+	if index < 0 || index >= len(csv.Header) {
+		return "", nil, fmt.Errorf("column index %d is out of range (0-%d)", index, len(csv.Header)-1)
+	}
+
+	name := csv.Header[index]
+	csv.Header = removeAt(csv.Header, index)
+	values := make([]string, len(csv.Records))
+	for i, record := range csv.Records {
+		if index < len(record) {
+			values[i] = record[index]
+			csv.Records[i] = removeAt(record, index)
+		}
+	}
+	return name, values, nil
+}
+
+func insertAt(s []string, index int, value string) []string {
+	out := make([]string, 0, len(s)+1)
+	out = append(out, s[:index]...)
+	out = append(out, value)
+	return append(out, s[index:]...)
+}
+
+func removeAt(s []string, index int) []string {
+	out := make([]string, 0, len(s)-1)
+	out = append(out, s[:index]...)
+	return append(out, s[index+1:]...)
+}
+
 type FilterConfig struct {
 	ColumnName string
 	Value      string
@@ -181,41 +273,3 @@ func SelectColumns(csv *csvparser.CSV, columnNames []string) (*csvparser.CSV, er
 
 	return selected, nil
 }
-
-type SortConfig struct {
-	ColumnName string
-	Descending bool
-}
-
-func Sort(csv *csvparser.CSV, config SortConfig) error {
-	columnIndex, err := csv.GetColumnIndex(config.ColumnName)
-	if err != nil {
-		return err
-	}
-
-	for i := 0; i < len(csv.Records); i++ {
-		for j := i + 1; j < len(csv.Records); j++ {
-			val1 := ""
-			val2 := ""
-			if columnIndex < len(csv.Records[i]) {
-				val1 = csv.Records[i][columnIndex]
-			}
-			if columnIndex < len(csv.Records[j]) {
-				val2 = csv.Records[j][columnIndex]
-			}
-
-			shouldSwap := false
-			if config.Descending {
-				shouldSwap = val1 < val2
-			} else {
-				shouldSwap = val1 > val2
-			}
-
-			if shouldSwap {
-				csv.Records[i], csv.Records[j] = csv.Records[j], csv.Records[i]
-			}
-		}
-	}
-
-	return nil
-}
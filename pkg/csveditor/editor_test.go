@@ -1,6 +1,7 @@
 package csveditor
 
 import (
+	"strings"
 	"testing"
 
 	"sean-stapleton-doyle/csvtk/pkg/csvparser"
@@ -56,6 +57,115 @@ func TestMoveRow(t *testing.T) {
 	}
 }
 
+func TestSetCell(t *testing.T) {
+	csv := &csvparser.CSV{
+		Header:  []string{"Name", "Age", "City"},
+		Records: [][]string{{"John", "30", "NYC"}, {"Jane"}},
+	}
+
+	old, err := SetCell(csv, 0, 1, "31")
+	if err != nil || old != "30" || csv.Records[0][1] != "31" {
+		t.Errorf("SetCell() = %q, %v; Records[0][1] = %s, want 30, nil and 31", old, err, csv.Records[0][1])
+	}
+
+	if _, err := SetCell(csv, 1, 2, "LA"); err != nil {
+		t.Fatalf("SetCell() error = %v", err)
+	}
+	if got := strings.Join(csv.Records[1], ","); got != "Jane,,LA" {
+		t.Errorf("Records[1] = %s, want Jane,,LA", got)
+	}
+
+	if _, err := SetCell(csv, 2, 0, "x"); err == nil {
+		t.Error("SetCell() out of range row: expected error")
+	}
+	if _, err := SetCell(csv, 0, 3, "x"); err == nil {
+		t.Error("SetCell() out of range column: expected error")
+	}
+}
+
+func TestInsertDeleteRow(t *testing.T) {
+	csv := &csvparser.CSV{
+		Header:  []string{"Name", "Age"},
+		Records: [][]string{{"John", "30"}, {"Bob", "35"}},
+	}
+
+	if err := InsertRow(csv, 1, []string{"Jane", "25"}); err != nil {
+		t.Fatalf("InsertRow() error = %v", err)
+	}
+	if err := InsertRow(csv, 3, nil); err != nil {
+		t.Fatalf("InsertRow() error = %v", err)
+	}
+	if got := joinRecords(csv.Records); got != "John,30;Jane,25;Bob,35;," {
+		t.Errorf("Records = %s, want John,30;Jane,25;Bob,35;,", got)
+	}
+
+	record, err := DeleteRow(csv, 0)
+	if err != nil || strings.Join(record, ",") != "John,30" {
+		t.Errorf("DeleteRow() = %v, %v, want [John 30]", record, err)
+	}
+	if got := joinRecords(csv.Records); got != "Jane,25;Bob,35;," {
+		t.Errorf("Records = %s, want Jane,25;Bob,35;,", got)
+	}
+
+	if err := InsertRow(csv, 5, nil); err == nil {
+		t.Error("InsertRow() out of range: expected error")
+	}
+	if _, err := DeleteRow(csv, 3); err == nil {
+		t.Error("DeleteRow() out of range: expected error")
+	}
+}
+
+func TestInsertDeleteColumn(t *testing.T) {
+	csv := &csvparser.CSV{
+		Header:  []string{"Name", "City"},
+		Records: [][]string{{"John", "NYC"}, {"Jane"}},
+	}
+	header := csv.Header
+
+	if err := InsertColumn(csv, 1, "Age", []string{"30", "25"}); err != nil {
+		t.Fatalf("InsertColumn() error = %v", err)
+	}
+	if got := strings.Join(csv.Header, ","); got != "Name,Age,City" {
+		t.Errorf("Header = %s, want Name,Age,City", got)
+	}
+	if got := joinRecords(csv.Records); got != "John,30,NYC;Jane,25" {
+		t.Errorf("Records = %s, want John,30,NYC;Jane,25", got)
+	}
+	if strings.Join(header, ",") != "Name,City" {
+		t.Errorf("InsertColumn() modified the original header slice: %v", header)
+	}
+
+	if err := InsertColumn(csv, 3, "Zip", nil); err != nil {
+		t.Fatalf("InsertColumn() error = %v", err)
+	}
+	if got := joinRecords(csv.Records); got != "John,30,NYC,;Jane,25,," {
+		t.Errorf("Records = %s, want John,30,NYC,;Jane,25,,", got)
+	}
+
+	name, values, err := DeleteColumn(csv, 1)
+	if err != nil || name != "Age" || strings.Join(values, ",") != "30,25" {
+		t.Errorf("DeleteColumn() = %q, %v, %v, want Age, [30 25]", name, values, err)
+	}
+	if got := strings.Join(csv.Header, ","); got != "Name,City,Zip" {
+		t.Errorf("Header = %s, want Name,City,Zip", got)
+	}
+
+	if err := InsertColumn(csv, 0, "ID", []string{"1"}); err == nil {
+		t.Error("InsertColumn() with wrong value count: expected error")
+	}
+	if _, _, err := DeleteColumn(csv, 3); err == nil {
+		t.Error("DeleteColumn() out of range: expected error")
+	}
+}
+
+func joinRecords(records [][]string) string {
+	rows := make([]string, len(records))
+	for i, record := range records {
+		rows[i] = strings.Join(record, ",")
+	}
+	return strings.Join(rows, ";")
+}
+
 func TestFilter(t *testing.T) {
 	csv := &csvparser.CSV{
 		Header: []string{"Name", "Age", "City"},
@@ -0,0 +1,598 @@
+package csveditor
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Expression struct {
+	expr string
+	root node
+}
+
+type node interface {
+	eval(record []string) (any, error)
+}
+
+func ParseExpression(expr string, header []string) (*Expression, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	return parseExpressionTokens(&exprParser{expr: expr, tokens: tokens, header: header})
+}
+
+func parseExpressionTokens(p *exprParser) (*Expression, error) {
+	if p.peek().kind == tokenEOF {
+		return nil, p.errorf(p.peek(), "empty expression")
+	}
+	root, err := p.valueTernary()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		if tok.kind == tokenRParen {
+			return nil, p.errorf(tok, "unmatched closing parenthesis")
+		}
+		return nil, p.errorf(tok, "expected an operator")
+	}
+	return &Expression{expr: p.expr, root: root}, nil
+}
+
+func (e *Expression) Eval(record []string) (any, error) {
+	return e.root.eval(record)
+}
+
+func (e *Expression) EvalString(record []string) (string, error) {
+	v, err := e.root.eval(record)
+	if err != nil {
+		return "", err
+	}
+	return formatValue(v), nil
+}
+
+func (e *Expression) String() string {
+	return e.expr
+}
+
+func (p *exprParser) punct(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokenPunct && tok.text == text
+}
+
+func (p *exprParser) valueTernary() (node, error) {
+	cond, err := p.valueOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.punct("?") {
+		return cond, nil
+	}
+	p.next()
+	then, err := p.valueTernary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.punct(":") {
+		return nil, p.errorf(p.peek(), "expected : in conditional expression")
+	}
+	p.next()
+	otherwise, err := p.valueTernary()
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+func (p *exprParser) valueOr() (node, error) {
+	left, err := p.valueAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.valueAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) valueAnd() (node, error) {
+	left, err := p.valueNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.valueNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) valueNot() (node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		x, err := p.valueNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{x: x}, nil
+	}
+	return p.valueComparison()
+}
+
+func (p *exprParser) valueComparison() (node, error) {
+	left, err := p.valueAdditive()
+	if err != nil {
+		return nil, err
+	}
+	op := p.peek()
+	if op.kind != tokenOperator {
+		return left, nil
+	}
+	p.next()
+	rightTok := p.peek()
+	right, err := p.valueAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	n := &binaryNode{op: op.val, left: left, right: right}
+	if op.val == "=~" || op.val == "!~" {
+		if lit, ok := right.(*literalNode); ok {
+			re, err := regexp.Compile(formatValue(lit.value))
+			if err != nil {
+				return nil, p.errorf(rightTok, "invalid regex: %v", err)
+			}
+			n.re = re
+		}
+	}
+	return n, nil
+}
+
+func (p *exprParser) valueAdditive() (node, error) {
+	left, err := p.valueMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.punct("+") || p.punct("-") {
+		op := p.next()
+		right, err := p.valueMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) valueMultiplicative() (node, error) {
+	left, err := p.valueUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.punct("*") || p.punct("/") || p.punct("%") {
+		op := p.next()
+		right, err := p.valueUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) valueUnary() (node, error) {
+	if p.punct("-") || p.punct("+") {
+		op := p.next()
+		x, err := p.valueUnary()
+		if err != nil {
+			return nil, err
+		}
+		if op.text == "+" {
+			return x, nil
+		}
+		return &binaryNode{op: "-", left: &literalNode{value: 0.0}, right: x}, nil
+	}
+	return p.valuePrimary()
+}
+
+func (p *exprParser) valuePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		f, _ := strconv.ParseFloat(tok.val, 64)
+		return &literalNode{value: f}, nil
+	case tokenString:
+		return &literalNode{value: tok.val}, nil
+	case tokenLParen:
+		x, err := p.valueTernary()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next.kind != tokenRParen {
+			if next.kind == tokenEOF {
+				return nil, p.errorf(tok, "missing closing parenthesis")
+			}
+			return nil, p.errorf(next, "expected )")
+		}
+		p.next()
+		return x, nil
+	case tokenIdent:
+		if p.peek().kind == tokenLParen {
+			return p.valueCall(tok)
+		}
+		switch strings.ToLower(tok.val) {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		return p.valueColumn(tok)
+	case tokenColumn:
+		return p.valueColumn(tok)
+	default:
+		return nil, p.errorf(tok, "expected a value")
+	}
+}
+
+func (p *exprParser) valueColumn(tok token) (node, error) {
+	for i, name := range p.header {
+		if name == tok.val {
+			return &columnNode{name: name, index: i}, nil
+		}
+	}
+	hint := ""
+	if tok.kind == tokenIdent {
+		hint = " (quote text values with \"...\")"
+	}
+	return nil, p.errorf(tok, "unknown column %q%s", tok.val, hint)
+}
+
+func (p *exprParser) valueCall(name token) (node, error) {
+	open := p.next()
+	var args []node
+	var argToks []token
+	if p.peek().kind != tokenRParen {
+		for {
+			argToks = append(argToks, p.peek())
+			arg, err := p.valueTernary()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.punct(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	if tok := p.peek(); tok.kind != tokenRParen {
+		if tok.kind == tokenEOF {
+			return nil, p.errorf(open, "missing closing parenthesis")
+		}
+		return nil, p.errorf(tok, "expected , or )")
+	}
+	p.next()
+
+	fname := strings.ToLower(name.val)
+	if fname == "if" {
+		if len(args) != 3 {
+			return nil, p.errorf(name, "if takes 3 arguments (condition, then, else), got %d", len(args))
+		}
+		return &conditionalNode{cond: args[0], then: args[1], otherwise: args[2]}, nil
+	}
+	fn, ok := functions[fname]
+	if !ok {
+		return nil, p.errorf(name, "unknown function %q (available: %s)", name.val, strings.Join(FunctionNames(), ", "))
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, p.errorf(name, "%s takes %s, got %d", fname, fn.arity(), len(args))
+	}
+	n := &callNode{name: fname, fn: fn, args: args}
+	for i, arg := range args {
+		if lit, ok := arg.(*literalNode); ok && fn.regexArg == i+1 {
+			re, err := regexp.Compile(formatValue(lit.value))
+			if err != nil {
+				return nil, p.errorf(argToks[i], "invalid regex: %v", err)
+			}
+			n.re = re
+		}
+	}
+	return n, nil
+}
+
+type literalNode struct {
+	value any
+}
+
+func (n *literalNode) eval([]string) (any, error) {
+	return n.value, nil
+}
+
+type columnNode struct {
+	name  string
+	index int
+}
+
+func (n *columnNode) eval(record []string) (any, error) {
+	if n.index < len(record) {
+		return record[n.index], nil
+	}
+	return "", nil
+}
+
+type notNode struct {
+	x node
+}
+
+func (n *notNode) eval(record []string) (any, error) {
+	v, err := n.x.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+type logicalNode struct {
+	and         bool
+	left, right node
+}
+
+func (n *logicalNode) eval(record []string) (any, error) {
+	l, err := n.left.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(l) != n.and {
+		return !n.and, nil
+	}
+	r, err := n.right.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(r), nil
+}
+
+type conditionalNode struct {
+	cond, then, otherwise node
+}
+
+func (n *conditionalNode) eval(record []string) (any, error) {
+	c, err := n.cond.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(c) {
+		return n.then.eval(record)
+	}
+	return n.otherwise.eval(record)
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+	re          *regexp.Regexp
+}
+
+func (n *binaryNode) eval(record []string) (any, error) {
+	l, err := n.left.eval(record)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(record)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "+":
+		a, aok := l.(float64)
+		b, bok := r.(float64)
+		switch {
+		case aok && bok:
+			return a + b, nil
+		case aok && isBlank(r), bok && isBlank(l):
+			return nil, nil
+		case aok || bok:
+			return arithmetic("+", l, r)
+		}
+		return formatValue(l) + formatValue(r), nil
+	case "-", "*", "/", "%":
+		return arithmetic(n.op, l, r)
+	case "==":
+		return compareValues(l, r) == 0, nil
+	case "!=":
+		return compareValues(l, r) != 0, nil
+	case "<":
+		return compareValues(l, r) < 0, nil
+	case ">":
+		return compareValues(l, r) > 0, nil
+	case "<=":
+		return compareValues(l, r) <= 0, nil
+	case ">=":
+		return compareValues(l, r) >= 0, nil
+	case "=~", "!~":
+		re := n.re
+		if re == nil {
+			if re, err = compileRegex(r); err != nil {
+				return nil, err
+			}
+		}
+		return re.MatchString(formatValue(l)) == (n.op == "=~"), nil
+	case "contains":
+		return strings.Contains(formatValue(l), formatValue(r)), nil
+	case "startswith":
+		return strings.HasPrefix(formatValue(l), formatValue(r)), nil
+	case "endswith":
+		return strings.HasSuffix(formatValue(l), formatValue(r)), nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", n.op)
+}
+
+func arithmetic(op string, l, r any) (any, error) {
+	if isBlank(l) || isBlank(r) {
+		return nil, nil
+	}
+	a, err := toNumber(l)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toNumber(r)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	default:
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(a, b), nil
+	}
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+	re   *regexp.Regexp
+}
+
+func (n *callNode) eval(record []string) (any, error) {
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(record)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	if n.re != nil {
+		args[n.fn.regexArg-1] = n.re
+	}
+	v, err := n.fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+func isBlank(v any) bool {
+	if v == nil {
+		return true
+	}
+	s, ok := v.(string)
+	return ok && strings.TrimSpace(s) == ""
+}
+
+func toNumber(v any) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot use %q as a number", x)
+		}
+		return f, nil
+	case nil:
+		return 0, fmt.Errorf("cannot use null as a number")
+	default:
+		return 0, fmt.Errorf("cannot use %s as a number", formatValue(v))
+	}
+}
+
+func numbers(l, r any) (float64, float64, bool) {
+	a, err := toNumber(l)
+	if err != nil {
+		return 0, 0, false
+	}
+	b, err := toNumber(r)
+	if err != nil {
+		return 0, 0, false
+	}
+	return a, b, true
+}
+
+func compareValues(l, r any) int {
+	if a, b, ok := numbers(l, r); ok {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	if a, ok := l.(time.Time); ok {
+		if b, ok := r.(time.Time); ok {
+			return a.Compare(b)
+		}
+	}
+	return strings.Compare(formatValue(l), formatValue(r))
+}
+
+func truthy(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case float64:
+		return x != 0
+	case string:
+		switch strings.ToLower(strings.TrimSpace(x)) {
+		case "", "0", "false", "no":
+			return false
+		}
+		return true
+	}
+	return true
+}
+
+func formatValue(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case bool:
+		return strconv.FormatBool(x)
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1e15 {
+			return strconv.FormatInt(int64(x), 10)
+		}
+		rounded, _ := strconv.ParseFloat(strconv.FormatFloat(x, 'g', 15, 64), 64)
+		return strconv.FormatFloat(rounded, 'f', -1, 64)
+	case time.Time:
+		if x.Hour() == 0 && x.Minute() == 0 && x.Second() == 0 && x.Nanosecond() == 0 && x.Location() == time.UTC {
+			return x.Format("2006-01-02")
+		}
+		return x.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,163 @@
+package csveditor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var evalHeader = []string{"Name", "Price", "Qty", "Created", "Email", "Note", "Full Name"}
+
+var evalRecord = []string{" ann ", "2.5", "4", "05/01/2024", "ann@example.com", "", "Ann Lee"}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`Price * Qty`, "10"},
+		{`Price * Qty + 1 - 0.5`, "10.5"},
+		{`(number(Price) + 1.5) * -Qty`, "-16"},
+		{`Qty / 8`, "0.5"},
+		{`Qty % 3`, "1"},
+		{`3-1`, "2"},
+		{`upper(trim(Name))`, "ANN"},
+		{`trim(Name) + " <" + Email + ">"`, "ann <ann@example.com>"},
+		{`Qty + Price`, "42.5"},
+		{`number(Qty) + number(Price)`, "6.5"},
+		{`"1" + "2"`, "12"},
+		{`"02134" + "12"`, "0213412"},
+		{`1 + 2`, "3"},
+		{`Qty + 1`, "5"},
+		{`Price * 2 + Qty`, "9"},
+		{`0.1 * 3`, "0.3"},
+		{`1 / 3`, "0.333333333333333"},
+		{`0.00001 * 3`, "0.00003"},
+		{`concat(Qty, Price)`, "42.5"},
+		{`Note + 1`, ""},
+		{`number(Note) + 1`, ""},
+		{`Note + "x"`, "x"},
+		{`Price * Note`, ""},
+		{`substr("abcdef", 2, 3)`, "bcd"},
+		{`substr("abcdef", -2)`, "ef"},
+		{`substr("héllo", 2, 1)`, "é"},
+		{`len("héllo")`, "5"},
+		{`replace(Email, "example", "test")`, "ann@test.com"},
+		{`round(2.345, 2)`, "2.35"},
+		{`round(Price)`, "3"},
+		{`floor(-2.5)`, "-3"},
+		{`ceil(Price)`, "3"},
+		{`abs(-Qty)`, "4"},
+		{`min(Qty, Price, 7)`, "2.5"},
+		{`max(Qty, Note, Price)`, "4"},
+		{`coalesce(Note, null, "n/a")`, "n/a"},
+		{`number(" 42 ") + 1`, "43"},
+		{`Qty > Price ? "more" : "less"`, "more"},
+		{`if(Qty < Price, "more", "less")`, "less"},
+		{`Qty > 3 && Name contains "nn"`, "true"},
+		{`!(Qty > 3) || Note == ""`, "true"},
+		{`Price == "2.50"`, "true"},
+		{`Email =~ "@example\\.com$"`, "true"},
+		{`Email !~ "^bob"`, "true"},
+		{`Note ? "set" : "empty"`, "empty"},
+		{`date(Created, "%d/%m/%Y")`, "2024-01-05"},
+		{`format_date(date(Created, "%d/%m/%Y"), "%Y-%m")`, "2024-01"},
+		{`year("2024-03-09") * 100 + month("2024-03-09")`, "202403"},
+		{`date("2024-03-09") < date("2024-10-01")`, "true"},
+		{`extract(Email, "@(.+)$")`, "example.com"},
+		{`extract(Email, "([a-z]+)@([a-z]+)", 2)`, "example"},
+		{`extract(Email, "^bob")`, ""},
+		{`extract(Email, Note + "@(.+)$")`, "example.com"},
+		{"`Full Name` + \"!\"", "Ann Lee!"},
+		{`true && !false`, "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := ParseExpression(tt.expr, evalHeader)
+			if err != nil {
+				t.Fatalf("ParseExpression() error = %v", err)
+			}
+			got, err := expr.EvalString(evalRecord)
+			if err != nil {
+				t.Fatalf("EvalString() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EvalString(%s) = %q, want %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		record  []string
+		message string
+	}{
+		{`Name * 2`, nil, `cannot use " ann " as a number`},
+		{`Qty / 0`, nil, "division by zero"},
+		{`Qty % (Price - 2.5)`, nil, "division by zero"},
+		{`date(Name)`, nil, `date: cannot use "ann" as a date`},
+		{`date(Created, "%Y-%m-%d")`, nil, "as a date"},
+		{`substr(Name, 1.5)`, nil, "substr: expected a whole number"},
+		{`extract(Email, "(a)", 2)`, nil, "group 2 out of range"},
+		{`Name =~ Email`, []string{"ann", "1", "1", "", "a(b"}, "invalid regex"},
+		{`Qty > 3 ? Name * 1 : 0`, nil, "as a number"},
+		{`Name + 1`, nil, `cannot use " ann " as a number`},
+		{`Price * Qty + " units"`, nil, `cannot use " units" as a number`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := ParseExpression(tt.expr, evalHeader)
+			if err != nil {
+				t.Fatalf("ParseExpression() error = %v", err)
+			}
+			record := tt.record
+			if record == nil {
+				record = evalRecord
+			}
+			_, err = expr.Eval(record)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Eval() error = %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		column  int
+		message string
+	}{
+		{``, 1, "empty expression"},
+		{`Price +`, 8, "expected a value"},
+		{`Price * Cost`, 9, `unknown column "Cost"`},
+		{`Qty > 1 ? "a"`, 14, "expected :"},
+		{`upper(Name`, 6, "missing closing parenthesis"},
+		{`upper(Name Qty)`, 12, "expected , or )"},
+		{`shout(Name)`, 1, `unknown function "shout"`},
+		{`upper(Name, Qty)`, 1, "upper takes 1 argument, got 2"},
+		{`substr(Name)`, 1, "substr takes 2 to 3 arguments"},
+		{`if(Qty, 1)`, 1, "if takes 3 arguments"},
+		{`extract(Email, "(")`, 16, "invalid regex"},
+		{`Email =~ "["`, 10, "invalid regex"},
+		{`Price Qty`, 7, "expected an operator"},
+		{`Price)`, 6, "unmatched closing parenthesis"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseExpression(tt.expr, evalHeader)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("ParseExpression() error = %v, want a *ParseError", err)
+			}
+			if perr.Column() != tt.column || !strings.Contains(perr.Message, tt.message) {
+				t.Errorf("ParseExpression() error = %v (column %d), want column %d and %q", err, perr.Column(), tt.column, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,520 @@
+package csveditor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type Condition interface {
+	MatchRecord(record []string) (bool, error)
+	String() string
+}
+
+type AndCondition struct {
+	Left, Right Condition
+}
+
+func (c *AndCondition) MatchRecord(record []string) (bool, error) {
+	ok, err := c.Left.MatchRecord(record)
+	if err != nil || !ok {
+		return false, err
+	}
+	return c.Right.MatchRecord(record)
+}
+
+func (c *AndCondition) String() string {
+	return "(" + c.Left.String() + " && " + c.Right.String() + ")"
+}
+
+type OrCondition struct {
+	Left, Right Condition
+}
+
+func (c *OrCondition) MatchRecord(record []string) (bool, error) {
+	ok, err := c.Left.MatchRecord(record)
+	if err != nil || ok {
+		return ok, err
+	}
+	return c.Right.MatchRecord(record)
+}
+
+func (c *OrCondition) String() string {
+	return "(" + c.Left.String() + " || " + c.Right.String() + ")"
+}
+
+type NotCondition struct {
+	Condition Condition
+}
+
+func (c *NotCondition) MatchRecord(record []string) (bool, error) {
+	ok, err := c.Condition.MatchRecord(record)
+	return !ok, err
+}
+
+func (c *NotCondition) String() string {
+	return "!(" + c.Condition.String() + ")"
+}
+
+type Operand struct {
+	Column string
+	Value  string
+	index  int
+}
+
+func (o Operand) value(record []string) string {
+	if o.Column == "" {
+		return o.Value
+	}
+	if o.index < len(record) {
+		return record[o.index]
+	}
+	return ""
+}
+
+func (o Operand) String() string {
+	if o.Column != "" {
+		return "`" + o.Column + "`"
+	}
+	return strconv.Quote(o.Value)
+}
+
+type Comparison struct {
+	Left     Operand
+	Operator string
+	Right    Operand
+	Strategy FilterStrategy
+}
+
+func (c *Comparison) MatchRecord(record []string) (bool, error) {
+	right := c.Right.value(record)
+	if _, numeric := c.Strategy.(*NumericComparisonStrategy); numeric && c.Right.Column != "" {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(right), 64); err != nil {
+			return false, nil
+		}
+	}
+	return c.Strategy.Match(c.Left.value(record), right)
+}
+
+func (c *Comparison) String() string {
+	return c.Left.String() + " " + c.Operator + " " + c.Right.String()
+}
+
+type AutoEqualsStrategy struct {
+	Negate bool
+}
+
+func (s *AutoEqualsStrategy) Match(value string, pattern string) (bool, error) {
+	equal := value == pattern
+	a, aerr := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	b, berr := strconv.ParseFloat(strings.TrimSpace(pattern), 64)
+	if aerr == nil && berr == nil {
+		equal = a == b
+	}
+	return equal != s.Negate, nil
+}
+
+func (s *AutoEqualsStrategy) Name() string {
+	if s.Negate {
+		return "!="
+	}
+	return "=="
+}
+
+type ParseError struct {
+	Expr    string
+	Pos     int
+	Token   string
+	Message string
+}
+
+func (e *ParseError) Column() int {
+	return utf8.RuneCountInString(e.Expr[:e.Pos]) + 1
+}
+
+func (e *ParseError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("column %d: %s", e.Column(), e.Message)
+	}
+	return fmt.Sprintf("column %d near %q: %s", e.Column(), e.Token, e.Message)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenColumn
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	val  string
+	pos  int
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		r, size := utf8.DecodeRuneInString(expr[i:])
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			continue
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == '"' || r == '\'':
+			value, end, ok := scanString(expr, i)
+			if !ok {
+				return nil, &ParseError{Expr: expr, Pos: start, Token: expr[start:], Message: "unterminated string"}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: expr[start:end], val: value, pos: start})
+			i = end
+		case r == '`':
+			end := strings.IndexByte(expr[i+1:], '`')
+			if end < 0 {
+				return nil, &ParseError{Expr: expr, Pos: start, Token: expr[start:], Message: "unterminated column name"}
+			}
+			i += end + 2
+			tokens = append(tokens, token{kind: tokenColumn, text: expr[start:i], val: expr[start+1 : i-1], pos: start})
+		case strings.ContainsRune("=!<>&|", r):
+			op := expr[i : i+1]
+			if i+1 < len(expr) {
+				if two := expr[i : i+2]; two == "==" || two == "!=" || two == ">=" || two == "<=" || two == "=~" || two == "!~" || two == "&&" || two == "||" {
+					op = two
+				}
+			}
+			i += len(op)
+			switch op {
+			case "&&":
+				tokens = append(tokens, token{kind: tokenAnd, text: op, pos: start})
+			case "||":
+				tokens = append(tokens, token{kind: tokenOr, text: op, pos: start})
+			case "!":
+				tokens = append(tokens, token{kind: tokenNot, text: op, pos: start})
+			case "=":
+				tokens = append(tokens, token{kind: tokenOperator, text: op, val: "==", pos: start})
+			case "&", "|":
+				return nil, &ParseError{Expr: expr, Pos: start, Token: op, Message: fmt.Sprintf("unknown operator, did you mean %q?", op+op)}
+			default:
+				tokens = append(tokens, token{kind: tokenOperator, text: op, val: op, pos: start})
+			}
+		case unicode.IsDigit(r) || ((r == '-' || r == '+' || r == '.') && !afterValue(tokens) && i+1 < len(expr) && (unicode.IsDigit(rune(expr[i+1])) || expr[i+1] == '.')):
+			i++
+			for i < len(expr) && (unicode.IsDigit(rune(expr[i])) || strings.IndexByte(".eE", expr[i]) >= 0 ||
+				((expr[i] == '-' || expr[i] == '+') && (expr[i-1] == 'e' || expr[i-1] == 'E'))) {
+				i++
+			}
+			text := expr[start:i]
+			if _, err := strconv.ParseFloat(text, 64); err != nil {
+				return nil, &ParseError{Expr: expr, Pos: start, Token: text, Message: "invalid number"}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, val: text, pos: start})
+		case r == '_' || unicode.IsLetter(r):
+			for i < len(expr) {
+				r, size := utf8.DecodeRuneInString(expr[i:])
+				if r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			text := expr[start:i]
+			switch strings.ToLower(text) {
+			case "and":
+				tokens = append(tokens, token{kind: tokenAnd, text: text, pos: start})
+			case "or":
+				tokens = append(tokens, token{kind: tokenOr, text: text, pos: start})
+			case "not":
+				tokens = append(tokens, token{kind: tokenNot, text: text, pos: start})
+			case "contains", "startswith", "endswith":
+				tokens = append(tokens, token{kind: tokenOperator, text: text, val: strings.ToLower(text), pos: start})
+			default:
+				tokens = append(tokens, token{kind: tokenIdent, text: text, val: text, pos: start})
+			}
+		case strings.ContainsRune("+-*/%,?:", r):
+			tokens = append(tokens, token{kind: tokenPunct, text: string(r), pos: start})
+			i++
+		default:
+			return nil, &ParseError{Expr: expr, Pos: start, Token: string(r), Message: "unexpected character"}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+func afterValue(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	switch tokens[len(tokens)-1].kind {
+	case tokenIdent, tokenColumn, tokenString, tokenNumber, tokenRParen:
+		return true
+	}
+	return false
+}
+
+func scanString(expr string, start int) (string, int, bool) {
+	quote := expr[start]
+	var b strings.Builder
+	for i := start + 1; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == quote:
+			return b.String(), i + 1, true
+		case c == '\\' && i+1 < len(expr):
+			i++
+			switch expr[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(expr[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", len(expr), false
+}
+
+type exprParser struct {
+	expr   string
+	tokens []token
+	pos    int
+	header []string
+}
+
+func ParseFilter(expr string, header []string) (Condition, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{expr: expr, tokens: tokens, header: header}
+	if p.peek().kind == tokenEOF {
+		return nil, p.errorf(p.peek(), "empty expression")
+	}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		if tok.kind == tokenRParen {
+			return nil, p.errorf(tok, "unmatched closing parenthesis")
+		}
+		return nil, p.errorf(tok, "expected && or || between conditions")
+	}
+	return cond, nil
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) errorf(tok token, format string, args ...any) error {
+	text := tok.text
+	if tok.kind == tokenEOF {
+		text = ""
+	}
+	return &ParseError{Expr: p.expr, Pos: tok.pos, Token: text, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *exprParser) parseOr() (Condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &OrCondition{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (Condition, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &AndCondition{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (Condition, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		cond, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotCondition{Condition: cond}, nil
+	}
+	if open := p.peek(); open.kind == tokenLParen {
+		p.next()
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.peek(); tok.kind != tokenRParen {
+			if tok.kind == tokenEOF {
+				return nil, p.errorf(open, "missing closing parenthesis")
+			}
+			return nil, p.errorf(tok, "expected ) or && or ||")
+		}
+		p.next()
+		return cond, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (Condition, error) {
+	left, leftTok, err := p.parseOperand("a column or value")
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	if op.kind != tokenOperator {
+		if op.kind == tokenEOF {
+			return nil, p.errorf(op, "expected a comparison operator after %s", leftTok.text)
+		}
+		return nil, p.errorf(op, "expected a comparison operator (==, !=, >, <, >=, <=, =~, !~, contains, startswith, endswith)")
+	}
+	right, rightTok, err := p.parseOperand(fmt.Sprintf("a column or value after %s", op.text))
+	if err != nil {
+		return nil, err
+	}
+
+	var strategy FilterStrategy
+	switch op.val {
+	case "==", "!=":
+		if leftTok.kind == tokenString || rightTok.kind == tokenString {
+			strategy = NewFilterStrategy(map[string]string{"==": "equals", "!=": "not-equals"}[op.val])
+		} else {
+			strategy = &AutoEqualsStrategy{Negate: op.val == "!="}
+		}
+	case "=~", "!~":
+		if right.Column == "" {
+			if _, err := regexp.Compile(right.Value); err != nil {
+				return nil, p.errorf(rightTok, "invalid regex: %v", err)
+			}
+		}
+		strategy = &RegexStrategy{}
+	case ">", "<", ">=", "<=":
+		for _, tok := range []token{leftTok, rightTok} {
+			if tok.kind == tokenString {
+				if _, err := strconv.ParseFloat(strings.TrimSpace(tok.val), 64); err != nil {
+					return nil, p.errorf(tok, "%s compares numbers, but %s is not a number", op.text, tok.text)
+				}
+			}
+		}
+		strategy = NewFilterStrategy(op.val)
+	default:
+		strategy = NewFilterStrategy(op.val)
+	}
+
+	operator := op.val
+	if operator == "!~" {
+		operator = "=~"
+	}
+	var cond Condition = &Comparison{Left: left, Operator: operator, Right: right, Strategy: strategy}
+	if op.val == "!~" {
+		cond = &NotCondition{Condition: cond}
+	}
+	return cond, nil
+}
+
+func (p *exprParser) parseOperand(want string) (Operand, token, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString, tokenNumber:
+		return Operand{Value: tok.val}, tok, nil
+	case tokenIdent, tokenColumn:
+		for i, name := range p.header {
+			if name == tok.val {
+				return Operand{Column: name, index: i}, tok, nil
+			}
+		}
+		hint := ""
+		if tok.kind == tokenIdent {
+			hint = " (quote text values with \"...\")"
+		}
+		return Operand{}, tok, p.errorf(tok, "unknown column %q%s", tok.val, hint)
+	default:
+		return Operand{}, tok, p.errorf(tok, "expected %s", want)
+	}
+}
+
+func FilterExprStream(r *csvparser.Reader, w *csvparser.Writer, cond Condition) (int, error) {
+	if err := w.WriteHeader(r.Header()); err != nil {
+		return 0, err
+	}
+
+	matched := 0
+	err := r.ForEach(func(record []string) error {
+		match, err := cond.MatchRecord(record)
+		if err != nil {
+			return fmt.Errorf("filter error on row: %w", err)
+		}
+		if match {
+			matched++
+			return w.Write(record)
+		}
+		return nil
+	})
+	return matched, err
+}
+
+func FilterExpr(csv *csvparser.CSV, cond Condition) (*csvparser.CSV, error) {
+	filtered := &csvparser.CSV{
+		Header:  csv.Header,
+		Records: [][]string{},
+	}
+
+	for _, record := range csv.Records {
+		match, err := cond.MatchRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("filter error on row: %w", err)
+		}
+		if match {
+			filtered.Records = append(filtered.Records, record)
+		}
+	}
+	return filtered, nil
+}
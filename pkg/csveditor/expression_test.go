@@ -0,0 +1,110 @@
+package csveditor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+var exprHeader = []string{"Name", "Country", "Age", "VIP", "Price", "Cost", "First Name"}
+
+var exprRecords = [][]string{
+	{"Ann", "US", "35", "no", "10", "5", "A"},
+	{"Bob", "US", "25", "yes", "3", "4", "B"},
+	{"Cy", "UK", "40", "yes", "8", "x", "C"},
+	{"Di", "US", "20", "no", "1.0", "1", "D"},
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`Country == "US" && (Age > 30 || VIP == "yes")`, "Ann,Bob"},
+		{`Country = 'US' and not VIP == "yes"`, "Ann,Di"},
+		{`!(Country == "US") || Age < 21`, "Cy,Di"},
+		{`Price > Cost`, "Ann"},
+		{`Price == Cost`, "Di"},
+		{`Price != 1`, "Ann,Bob,Cy"},
+		{`Name =~ "^[AB]" && Name !~ "b$"`, "Ann"},
+		{`Name contains "y" or Name startswith "D" or Name endswith "nn"`, "Ann,Cy,Di"},
+		{"`First Name` == \"C\"", "Cy"},
+		{`Age >= "35"`, "Ann,Cy"},
+		{`Country == "US" || Country == "UK" && Age > 100`, "Ann,Bob,Di"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cond, err := ParseFilter(tt.expr, exprHeader)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+
+			filtered, err := FilterExpr(&csvparser.CSV{Header: exprHeader, Records: exprRecords}, cond)
+			if err != nil {
+				t.Fatalf("FilterExpr() error = %v", err)
+			}
+			var names []string
+			for _, record := range filtered.Records {
+				names = append(names, record[0])
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("FilterExpr(%s) = %s, want %s", cond, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		column  int
+		message string
+	}{
+		{``, 1, "empty expression"},
+		{`Country == `, 12, "expected a column or value after =="},
+		{`Country == US`, 12, `unknown column "US"`},
+		{`(Age > 3`, 1, "missing closing parenthesis"},
+		{`Age > 3)`, 8, "unmatched closing parenthesis"},
+		{`Age > 3 Name`, 9, "expected && or ||"},
+		{`Age 3`, 5, "expected a comparison operator"},
+		{`Age > "old"`, 7, "not a number"},
+		{`Name =~ "["`, 9, "invalid regex"},
+		{`Name == "Ann`, 9, "unterminated string"},
+		{`Age > 3 & VIP == "yes"`, 9, `did you mean "&&"`},
+		{`Age > 3 && Ñame == 1`, 12, `unknown column "Ñame"`},
+		{`Age # 3`, 5, "unexpected character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseFilter(tt.expr, exprHeader)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("ParseFilter() error = %v, want a *ParseError", err)
+			}
+			if perr.Column() != tt.column || !strings.Contains(perr.Message, tt.message) {
+				t.Errorf("ParseFilter() error = %v (column %d), want column %d and %q", err, perr.Column(), tt.column, tt.message)
+			}
+		})
+	}
+}
+
+func TestFilterExprStream(t *testing.T) {
+	var matched int
+	got := runStream(t, func(r *csvparser.Reader, w *csvparser.Writer) error {
+		cond, err := ParseFilter(`City == "New York" && Age < 28`, r.Header())
+		if err != nil {
+			return err
+		}
+		matched, err = FilterExprStream(r, w, cond)
+		return err
+	})
+
+	expected := "Name,Age,City\nJane,25,New York\n"
+	if got != expected || matched != 1 {
+		t.Errorf("FilterExprStream() = %q (%d), want %q (1)", got, matched, expected)
+	}
+}
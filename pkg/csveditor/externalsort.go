@@ -0,0 +1,262 @@
+package csveditor
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+const (
+	DefaultSortMemoryLimit = 256 << 20
+	maxMergeFanIn          = 64
+)
+
+type ExternalSortOptions struct {
+	MemoryLimit int64
+	TempDir     string
+}
+
+func ExternalSort(r *csvparser.Reader, w *csvparser.Writer, config SortConfig, options ExternalSortOptions) error {
+	s, err := newSorter(r.Header(), config)
+	if err != nil {
+		return err
+	}
+
+	if options.MemoryLimit <= 0 {
+		options.MemoryLimit = DefaultSortMemoryLimit
+	}
+
+	var runs []string
+	defer func() {
+		for _, run := range runs {
+			os.Remove(run)
+		}
+	}()
+
+	var chunk [][]string
+	var chunkSize int64
+	err = r.ForEach(func(record []string) error {
+		chunk = append(chunk, record)
+		chunkSize += recordSize(record)
+		if chunkSize < options.MemoryLimit {
+			return nil
+		}
+
+		s.sort(chunk)
+		run, err := spillRun(chunk, options.TempDir)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+		chunk = nil
+		chunkSize = 0
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteHeader(r.Header()); err != nil {
+		return err
+	}
+
+	s.sort(chunk)
+	if len(runs) == 0 {
+		for _, record := range chunk {
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(chunk) > 0 {
+		run, err := spillRun(chunk, options.TempDir)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+		chunk = nil
+	}
+
+	for len(runs) > maxMergeFanIn {
+		var merged []string
+		for start := 0; start < len(runs); start += maxMergeFanIn {
+			end := min(start+maxMergeFanIn, len(runs))
+			run, err := mergeToRun(runs[start:end], s, options.TempDir)
+			if err != nil {
+				return err
+			}
+			merged = append(merged, run)
+		}
+		for _, run := range runs {
+			os.Remove(run)
+		}
+		runs = merged
+	}
+
+	return mergeRuns(runs, s, w.Write)
+}
+
+func recordSize(record []string) int64 {
+	size := int64(24)
+	for _, field := range record {
+		size += int64(16 + len(field))
+	}
+	return size
+}
+
+func spillRun(records [][]string, dir string) (string, error) {
+	file, err := os.CreateTemp(dir, "csvtk-sort-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	buf := bufio.NewWriter(file)
+	enc := gob.NewEncoder(buf)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			return "", fmt.Errorf("failed to write temp file: %w", err)
+		}
+	}
+
+	if err := buf.Flush(); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	return file.Name(), nil
+}
+
+func mergeToRun(runs []string, s *sorter, dir string) (string, error) {
+	file, err := os.CreateTemp(dir, "csvtk-sort-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	buf := bufio.NewWriter(file)
+	enc := gob.NewEncoder(buf)
+	err = mergeRuns(runs, s, func(record []string) error {
+		return enc.Encode(record)
+	})
+	if err == nil {
+		err = buf.Flush()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	return file.Name(), nil
+}
+
+type runReader struct {
+	file *os.File
+	dec  *gob.Decoder
+}
+
+type mergeItem struct {
+	keyedRecord
+	source int
+}
+
+type mergeHeap struct {
+	items  []mergeItem
+	sorter *sorter
+}
+
+func (h *mergeHeap) Len() int { return len(h.items) }
+
+func (h *mergeHeap) Less(i, j int) bool {
+	c := h.sorter.compare(h.items[i].keyedRecord, h.items[j].keyedRecord)
+	if c != 0 {
+		return c < 0
+	}
+	return h.items[i].source < h.items[j].source
+}
+
+func (h *mergeHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *mergeHeap) Push(x any) { h.items = append(h.items, x.(mergeItem)) }
+
+func (h *mergeHeap) Pop() any {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return item
+}
+
+func mergeRuns(runs []string, s *sorter, emit func(record []string) error) error {
+	readers := make([]*runReader, len(runs))
+	defer func() {
+		for _, rr := range readers {
+			if rr != nil {
+				rr.file.Close()
+			}
+		}
+	}()
+
+	h := &mergeHeap{sorter: s}
+	for i, run := range runs {
+		file, err := os.Open(run)
+		if err != nil {
+			return fmt.Errorf("failed to open temp file: %w", err)
+		}
+		readers[i] = &runReader{file: file, dec: gob.NewDecoder(bufio.NewReader(file))}
+
+		record, err := readers[i].next()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		h.items = append(h.items, mergeItem{keyedRecord: s.keyed(record), source: i})
+	}
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		item := h.items[0]
+		if err := emit(item.record); err != nil {
+			return err
+		}
+
+		record, err := readers[item.source].next()
+		if err == io.EOF {
+			heap.Pop(h)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		h.items[0] = mergeItem{keyedRecord: s.keyed(record), source: item.source}
+		heap.Fix(h, 0)
+	}
+
+	return nil
+}
+
+func (rr *runReader) next() ([]string, error) {
+	var record []string
+	if err := rr.dec.Decode(&record); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read temp file: %w", err)
+	}
+	return record, nil
+}
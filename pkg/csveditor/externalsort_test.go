@@ -0,0 +1,99 @@
+package csveditor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+func sortInput(rows int) string {
+	var b strings.Builder
+	b.WriteString("Key,Seq\n")
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&b, "k%02d,%d\n", (i*37)%23, i)
+	}
+	return b.String()
+}
+
+func TestSortStable(t *testing.T) {
+	csv := &csvparser.CSV{
+		Header: []string{"Name", "Seq"},
+		Records: [][]string{
+			{"b", "1"},
+			{"a", "2"},
+			{"b", "3"},
+			{"a", "4"},
+		},
+	}
+
+	if err := Sort(csv, SortConfig{ColumnName: "Name", Descending: true}); err != nil {
+		t.Fatalf("Sort() error = %v", err)
+	}
+
+	expected := []string{"1", "3", "2", "4"}
+	for i, want := range expected {
+		if csv.Records[i][1] != want {
+			t.Errorf("Records[%d][1] = %s, want %s", i, csv.Records[i][1], want)
+		}
+	}
+}
+
+func TestExternalSortMatchesInMemory(t *testing.T) {
+	input := sortInput(500)
+
+	tests := []struct {
+		name        string
+		memoryLimit int64
+		descending  bool
+	}{
+		{"in memory", 0, false},
+		{"spilled", 2048, false},
+		{"spilled descending", 2048, true},
+		{"multi-pass merge", 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := SortConfig{ColumnName: "Key", Descending: tt.descending}
+
+			csv, err := csvparser.Parse(strings.NewReader(input), nil)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if err := Sort(csv, config); err != nil {
+				t.Fatalf("Sort() error = %v", err)
+			}
+			var want strings.Builder
+			if err := csv.Write(&want, nil); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			tempDir := t.TempDir()
+			r, err := csvparser.NewReader(strings.NewReader(input), nil)
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+			var got strings.Builder
+			w := csvparser.NewWriter(&got, nil)
+			options := ExternalSortOptions{MemoryLimit: tt.memoryLimit, TempDir: tempDir}
+			if err := ExternalSort(r, w, config, options); err != nil {
+				t.Fatalf("ExternalSort() error = %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+
+			if got.String() != want.String() {
+				t.Errorf("ExternalSort() output differs from Sort()")
+			}
+
+			entries, _ := os.ReadDir(tempDir)
+			if len(entries) != 0 {
+				t.Errorf("ExternalSort() left %d temp files behind", len(entries))
+			}
+		})
+	}
+}
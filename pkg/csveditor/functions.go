@@ -0,0 +1,300 @@
+package csveditor
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+)
+
+type function struct {
+	minArgs  int
+	maxArgs  int
+	regexArg int
+	call     func(args []any) (any, error)
+}
+
+func (f function) arity() string {
+	switch {
+	case f.minArgs == f.maxArgs && f.minArgs == 1:
+		return "1 argument"
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprintf("%d arguments", f.minArgs)
+	case f.maxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", f.minArgs)
+	default:
+		return fmt.Sprintf("%d to %d arguments", f.minArgs, f.maxArgs)
+	}
+}
+
+var functions = map[string]function{
+	"upper":       {minArgs: 1, maxArgs: 1, call: stringFunc(strings.ToUpper)},
+	"lower":       {minArgs: 1, maxArgs: 1, call: stringFunc(strings.ToLower)},
+	"trim":        {minArgs: 1, maxArgs: 1, call: stringFunc(strings.TrimSpace)},
+	"len":         {minArgs: 1, maxArgs: 1, call: fnLen},
+	"substr":      {minArgs: 2, maxArgs: 3, call: fnSubstr},
+	"replace":     {minArgs: 3, maxArgs: 3, call: fnReplace},
+	"concat":      {minArgs: 1, maxArgs: -1, call: fnConcat},
+	"round":       {minArgs: 1, maxArgs: 2, call: fnRound},
+	"floor":       {minArgs: 1, maxArgs: 1, call: mathFunc(math.Floor)},
+	"ceil":        {minArgs: 1, maxArgs: 1, call: mathFunc(math.Ceil)},
+	"abs":         {minArgs: 1, maxArgs: 1, call: mathFunc(math.Abs)},
+	"min":         {minArgs: 1, maxArgs: -1, call: extremeFunc(-1)},
+	"max":         {minArgs: 1, maxArgs: -1, call: extremeFunc(1)},
+	"coalesce":    {minArgs: 1, maxArgs: -1, call: fnCoalesce},
+	"number":      {minArgs: 1, maxArgs: 1, call: fnNumber},
+	"date":        {minArgs: 1, maxArgs: 2, call: fnDate},
+	"format_date": {minArgs: 2, maxArgs: 2, call: fnFormatDate},
+	"year":        {minArgs: 1, maxArgs: 1, call: datePart(func(t time.Time) int { return t.Year() })},
+	"month":       {minArgs: 1, maxArgs: 1, call: datePart(func(t time.Time) int { return int(t.Month()) })},
+	"day":         {minArgs: 1, maxArgs: 1, call: datePart(func(t time.Time) int { return t.Day() })},
+	"extract":     {minArgs: 2, maxArgs: 3, regexArg: 2, call: fnExtract},
+}
+
+func FunctionNames() []string {
+	names := []string{"if"}
+	for name := range functions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+var dateLayouts = append(slices.Clone(csvstats.DateTimeLayouts), csvstats.DateLayouts...)
+
+func compileRegex(v any) (*regexp.Regexp, error) {
+	if re, ok := v.(*regexp.Regexp); ok {
+		return re, nil
+	}
+	pattern := formatValue(v)
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+func stringFunc(fn func(string) string) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		return fn(formatValue(args[0])), nil
+	}
+}
+
+func mathFunc(fn func(float64) float64) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		if isBlank(args[0]) {
+			return nil, nil
+		}
+		x, err := toNumber(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return fn(x), nil
+	}
+}
+
+func extremeFunc(sign int) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		var best any
+		for _, arg := range args {
+			if isBlank(arg) {
+				continue
+			}
+			if best == nil || compareValues(arg, best)*sign > 0 {
+				best = arg
+			}
+		}
+		return best, nil
+	}
+}
+
+func datePart(fn func(time.Time) int) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		if isBlank(args[0]) {
+			return nil, nil
+		}
+		t, err := toTime(args[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		return float64(fn(t)), nil
+	}
+}
+
+func fnLen(args []any) (any, error) {
+	return float64(utf8.RuneCountInString(formatValue(args[0]))), nil
+}
+
+func fnSubstr(args []any) (any, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	runes := []rune(formatValue(args[0]))
+	start, err := toInt(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 {
+		start += len(runes) + 1
+	}
+	start = max(start, 1) - 1
+	if start > len(runes) {
+		return "", nil
+	}
+	end := len(runes)
+	if len(args) > 2 {
+		n, err := toInt(args[2])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("length must not be negative, got %d", n)
+		}
+		end = min(start+n, len(runes))
+	}
+	return string(runes[start:end]), nil
+}
+
+func fnReplace(args []any) (any, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	return strings.ReplaceAll(formatValue(args[0]), formatValue(args[1]), formatValue(args[2])), nil
+}
+
+func fnConcat(args []any) (any, error) {
+	var b strings.Builder
+	for _, arg := range args {
+		b.WriteString(formatValue(arg))
+	}
+	return b.String(), nil
+}
+
+func fnRound(args []any) (any, error) {
+	if isBlank(args[0]) {
+		return nil, nil
+	}
+	x, err := toNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+	digits := 0
+	if len(args) > 1 {
+		if digits, err = toInt(args[1]); err != nil {
+			return nil, err
+		}
+	}
+	scale := math.Pow(10, float64(digits))
+	return math.Round(x*scale) / scale, nil
+}
+
+func fnCoalesce(args []any) (any, error) {
+	for _, arg := range args {
+		if !isBlank(arg) {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+func fnNumber(args []any) (any, error) {
+	if isBlank(args[0]) {
+		return nil, nil
+	}
+	x, err := toNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func fnDate(args []any) (any, error) {
+	if isBlank(args[0]) {
+		return nil, nil
+	}
+	var layouts []string
+	if len(args) > 1 {
+		layout, err := csvstats.Layout(formatValue(args[1]))
+		if err != nil {
+			return nil, err
+		}
+		layouts = []string{layout}
+	}
+	return toTime(args[0], layouts)
+}
+
+func fnFormatDate(args []any) (any, error) {
+	if isBlank(args[0]) {
+		return nil, nil
+	}
+	t, err := toTime(args[0], nil)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := csvstats.Layout(formatValue(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	return t.Format(layout), nil
+}
+
+func fnExtract(args []any) (any, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	re, err := compileRegex(args[1])
+	if err != nil {
+		return nil, err
+	}
+	group := 0
+	if len(args) > 2 {
+		if group, err = toInt(args[2]); err != nil {
+			return nil, err
+		}
+	} else if re.NumSubexp() > 0 {
+		group = 1
+	}
+	if group < 0 || group > re.NumSubexp() {
+		return nil, fmt.Errorf("group %d out of range, pattern has %d", group, re.NumSubexp())
+	}
+	match := re.FindStringSubmatch(formatValue(args[0]))
+	if match == nil {
+		return nil, nil
+	}
+	return match[group], nil
+}
+
+func toInt(v any) (int, error) {
+	f, err := toNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("expected a whole number, got %s", formatValue(v))
+	}
+	return int(f), nil
+}
+
+func toTime(v any, layouts []string) (time.Time, error) {
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+	s := strings.TrimSpace(formatValue(v))
+	if layouts == nil {
+		layouts = dateLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot use %q as a date", s)
+}
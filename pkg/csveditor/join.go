@@ -0,0 +1,304 @@
+package csveditor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type JoinType int
+
+const (
+	InnerJoin JoinType = iota
+	LeftJoin
+	RightJoin
+	FullJoin
+	SemiJoin
+	AntiJoin
+)
+
+func ParseJoinType(name string) (JoinType, error) {
+	switch strings.ToLower(name) {
+	case "inner", "":
+		return InnerJoin, nil
+	case "left", "left-outer":
+		return LeftJoin, nil
+	case "right", "right-outer":
+		return RightJoin, nil
+	case "full", "outer", "full-outer":
+		return FullJoin, nil
+	case "semi", "left-semi":
+		return SemiJoin, nil
+	case "anti", "left-anti":
+		return AntiJoin, nil
+	default:
+		return InnerJoin, fmt.Errorf("unknown join type %q", name)
+	}
+}
+
+type JoinConfig struct {
+	Type        JoinType
+	LeftKeys    []string
+	RightKeys   []string
+	LeftPrefix  string
+	LeftSuffix  string
+	RightPrefix string
+	RightSuffix string
+	BuildLeft   bool
+}
+
+type joinPlan struct {
+	leftKeys  []int
+	rightKeys []int
+	rightKeep []int
+	leftWidth int
+	header    []string
+}
+
+func planJoin(leftHeader, rightHeader []string, config JoinConfig) (*joinPlan, error) {
+	rightKeyNames := config.RightKeys
+	if len(rightKeyNames) == 0 {
+		rightKeyNames = config.LeftKeys
+	}
+	if len(config.LeftKeys) == 0 {
+		return nil, fmt.Errorf("at least one join key is required")
+	}
+	if len(config.LeftKeys) != len(rightKeyNames) {
+		return nil, fmt.Errorf("left has %d key columns but right has %d", len(config.LeftKeys), len(rightKeyNames))
+	}
+
+	leftKeys, err := columnIndices(leftHeader, config.LeftKeys)
+	if err != nil {
+		return nil, fmt.Errorf("left: %w", err)
+	}
+	rightKeys, err := columnIndices(rightHeader, rightKeyNames)
+	if err != nil {
+		return nil, fmt.Errorf("right: %w", err)
+	}
+
+	plan := &joinPlan{
+		leftKeys:  leftKeys,
+		rightKeys: rightKeys,
+		leftWidth: len(leftHeader),
+	}
+
+	if config.Type == SemiJoin || config.Type == AntiJoin {
+		plan.header = append([]string{}, leftHeader...)
+		return plan, nil
+	}
+
+	isRightKey := make(map[int]bool)
+	for _, index := range rightKeys {
+		isRightKey[index] = true
+	}
+	isLeftKey := make(map[int]bool)
+	for _, index := range leftKeys {
+		isLeftKey[index] = true
+	}
+
+	leftNames := make(map[string]bool)
+	for _, name := range leftHeader {
+		leftNames[name] = true
+	}
+	rightNames := make(map[string]bool)
+	for i, name := range rightHeader {
+		if !isRightKey[i] {
+			plan.rightKeep = append(plan.rightKeep, i)
+			rightNames[name] = true
+		}
+	}
+
+	for i, name := range leftHeader {
+		if !isLeftKey[i] && rightNames[name] {
+			name = config.LeftPrefix + name + config.LeftSuffix
+		}
+		plan.header = append(plan.header, name)
+	}
+	for _, i := range plan.rightKeep {
+		name := rightHeader[i]
+		if leftNames[name] {
+			name = config.RightPrefix + name + config.RightSuffix
+		}
+		plan.header = append(plan.header, name)
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range plan.header {
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate column %q in join result; set a prefix or suffix", name)
+		}
+		seen[name] = true
+	}
+
+	return plan, nil
+}
+
+func (p *joinPlan) combine(left, right []string) []string {
+	out := make([]string, p.leftWidth, len(p.header))
+	if left != nil {
+		copy(out, left)
+	} else {
+		for i, index := range p.leftKeys {
+			out[index] = cell(right, p.rightKeys[i])
+		}
+	}
+
+	for _, index := range p.rightKeep {
+		if right != nil {
+			out = append(out, cell(right, index))
+		} else {
+			out = append(out, "")
+		}
+	}
+	return out
+}
+
+func joinKey(record []string, indices []int) string {
+	var b strings.Builder
+	for _, index := range indices {
+		value := cell(record, index)
+		b.WriteString(strconv.Itoa(len(value)))
+		b.WriteByte(':')
+		b.WriteString(value)
+	}
+	return b.String()
+}
+
+func hashJoin(plan *joinPlan, joinType JoinType, build [][]string, buildIsLeft bool, probe func(func([]string) error) error, emit func([]string) error) error {
+	buildKeys, probeKeys := plan.rightKeys, plan.leftKeys
+	if buildIsLeft {
+		buildKeys, probeKeys = plan.leftKeys, plan.rightKeys
+	}
+
+	index := make(map[string][]int)
+	for i, record := range build {
+		key := joinKey(record, buildKeys)
+		index[key] = append(index[key], i)
+	}
+	matched := make([]bool, len(build))
+
+	err := probe(func(record []string) error {
+		matches := index[joinKey(record, probeKeys)]
+		for _, m := range matches {
+			matched[m] = true
+		}
+
+		switch {
+		case joinType == SemiJoin || joinType == AntiJoin:
+			if buildIsLeft {
+				return nil
+			}
+			if (len(matches) > 0) == (joinType == SemiJoin) {
+				return emit(record)
+			}
+			return nil
+		case buildIsLeft:
+			for _, m := range matches {
+				if err := emit(plan.combine(build[m], record)); err != nil {
+					return err
+				}
+			}
+			if len(matches) == 0 && (joinType == RightJoin || joinType == FullJoin) {
+				return emit(plan.combine(nil, record))
+			}
+		default:
+			for _, m := range matches {
+				if err := emit(plan.combine(record, build[m])); err != nil {
+					return err
+				}
+			}
+			if len(matches) == 0 && (joinType == LeftJoin || joinType == FullJoin) {
+				return emit(plan.combine(record, nil))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, record := range build {
+		var out []string
+		switch {
+		case buildIsLeft && joinType == SemiJoin && matched[i]:
+			out = record
+		case buildIsLeft && joinType == AntiJoin && !matched[i]:
+			out = record
+		case buildIsLeft && !matched[i] && (joinType == LeftJoin || joinType == FullJoin):
+			out = plan.combine(record, nil)
+		case !buildIsLeft && !matched[i] && (joinType == RightJoin || joinType == FullJoin):
+			out = plan.combine(nil, record)
+		default:
+			continue
+		}
+		if err := emit(out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Join(left, right *csvparser.CSV, config JoinConfig) (*csvparser.CSV, error) {
+	plan, err := planJoin(left.Header, right.Header, config)
+	if err != nil {
+		return nil, err
+	}
+
+	build, probeRecords := right.Records, left.Records
+	if config.BuildLeft {
+		build, probeRecords = left.Records, right.Records
+	}
+
+	probe := func(fn func([]string) error) error {
+		for _, record := range probeRecords {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	joined := &csvparser.CSV{
+		Header:  plan.header,
+		Records: [][]string{},
+	}
+	err = hashJoin(plan, config.Type, build, config.BuildLeft, probe, func(record []string) error {
+		joined.Records = append(joined.Records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return joined, nil
+}
+
+func JoinStream(left, right *csvparser.Reader, w *csvparser.Writer, config JoinConfig) error {
+	plan, err := planJoin(left.Header(), right.Header(), config)
+	if err != nil {
+		return err
+	}
+
+	buildReader, probeReader := right, left
+	if config.BuildLeft {
+		buildReader, probeReader = left, right
+	}
+
+	var build [][]string
+	err = buildReader.ForEach(func(record []string) error {
+		build = append(build, record)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteHeader(plan.header); err != nil {
+		return err
+	}
+
+	return hashJoin(plan, config.Type, build, config.BuildLeft, probeReader.ForEach, w.Write)
+}
@@ -0,0 +1,146 @@
+package csveditor
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+func joinFixtures() (*csvparser.CSV, *csvparser.CSV) {
+	customers := &csvparser.CSV{
+		Header: []string{"id", "name", "city"},
+		Records: [][]string{
+			{"1", "Ann", "NYC"},
+			{"2", "Bob", "LA"},
+			{"3", "Cy", "SF"},
+		},
+	}
+	orders := &csvparser.CSV{
+		Header: []string{"order", "customer_id", "city"},
+		Records: [][]string{
+			{"10", "1", "Boston"},
+			{"11", "1", "NYC"},
+			{"12", "4", "Paris"},
+		},
+	}
+	return customers, orders
+}
+
+func joinedRows(csv *csvparser.CSV) []string {
+	rows := make([]string, len(csv.Records))
+	for i, record := range csv.Records {
+		rows[i] = strings.Join(record, "|")
+	}
+	slices.Sort(rows)
+	return rows
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		joinType   JoinType
+		wantHeader string
+		wantRows   []string
+	}{
+		{InnerJoin, "id|name|city|order|city_right", []string{"1|Ann|NYC|10|Boston", "1|Ann|NYC|11|NYC"}},
+		{LeftJoin, "id|name|city|order|city_right", []string{"1|Ann|NYC|10|Boston", "1|Ann|NYC|11|NYC", "2|Bob|LA||", "3|Cy|SF||"}},
+		{RightJoin, "id|name|city|order|city_right", []string{"1|Ann|NYC|10|Boston", "1|Ann|NYC|11|NYC", "4|||12|Paris"}},
+		{FullJoin, "id|name|city|order|city_right", []string{"1|Ann|NYC|10|Boston", "1|Ann|NYC|11|NYC", "2|Bob|LA||", "3|Cy|SF||", "4|||12|Paris"}},
+		{SemiJoin, "id|name|city", []string{"1|Ann|NYC"}},
+		{AntiJoin, "id|name|city", []string{"2|Bob|LA", "3|Cy|SF"}},
+	}
+
+	for _, tt := range tests {
+		for _, buildLeft := range []bool{false, true} {
+			customers, orders := joinFixtures()
+			config := JoinConfig{
+				Type:        tt.joinType,
+				LeftKeys:    []string{"id"},
+				RightKeys:   []string{"customer_id"},
+				RightSuffix: "_right",
+				BuildLeft:   buildLeft,
+			}
+
+			joined, err := Join(customers, orders, config)
+			if err != nil {
+				t.Fatalf("Join(%v) error = %v", tt.joinType, err)
+			}
+
+			if got := strings.Join(joined.Header, "|"); got != tt.wantHeader {
+				t.Errorf("Join(%v, buildLeft=%v) header = %s, want %s", tt.joinType, buildLeft, got, tt.wantHeader)
+			}
+			if got := joinedRows(joined); !slices.Equal(got, tt.wantRows) {
+				t.Errorf("Join(%v, buildLeft=%v) rows = %v, want %v", tt.joinType, buildLeft, got, tt.wantRows)
+			}
+		}
+	}
+}
+
+func TestJoinMultipleKeys(t *testing.T) {
+	left := &csvparser.CSV{
+		Header:  []string{"Region", "Year", "Sales"},
+		Records: [][]string{{"EU", "2023", "5"}, {"EU", "2024", "6"}, {"US", "2024", "7"}},
+	}
+	right := &csvparser.CSV{
+		Header:  []string{"Region", "Year", "Target"},
+		Records: [][]string{{"EU", "2024", "10"}, {"US", "2023", "20"}},
+	}
+
+	joined, err := Join(left, right, JoinConfig{LeftKeys: []string{"Region", "Year"}})
+	if err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+
+	want := []string{"EU|2024|6|10"}
+	if got := joinedRows(joined); !slices.Equal(got, want) {
+		t.Errorf("Join() rows = %v, want %v", got, want)
+	}
+}
+
+func TestJoinDuplicateColumns(t *testing.T) {
+	customers, orders := joinFixtures()
+
+	_, err := Join(customers, orders, JoinConfig{LeftKeys: []string{"id"}, RightKeys: []string{"customer_id"}})
+	if err == nil {
+		t.Error("Join() expected error for duplicate column names, got nil")
+	}
+
+	joined, err := Join(customers, orders, JoinConfig{
+		LeftKeys:    []string{"id"},
+		RightKeys:   []string{"customer_id"},
+		LeftPrefix:  "c_",
+		RightPrefix: "o_",
+	})
+	if err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	if got := strings.Join(joined.Header, ","); got != "id,name,c_city,order,o_city" {
+		t.Errorf("Join() header = %s, want id,name,c_city,order,o_city", got)
+	}
+}
+
+func TestJoinStream(t *testing.T) {
+	left, err := csvparser.NewReader(strings.NewReader("id,name\n1,Ann\n2,Bob\n"), nil)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	right, err := csvparser.NewReader(strings.NewReader("id,total\n2,7\n1,5\n"), nil)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	var buf strings.Builder
+	w := csvparser.NewWriter(&buf, nil)
+	if err := JoinStream(left, right, w, JoinConfig{LeftKeys: []string{"id"}}); err != nil {
+		t.Fatalf("JoinStream() error = %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	expected := "id,name,total\n1,Ann,5\n2,Bob,7\n"
+	if buf.String() != expected {
+		t.Errorf("JoinStream() output = %q, want %q", buf.String(), expected)
+	}
+}
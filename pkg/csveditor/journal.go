@@ -0,0 +1,432 @@
+package csveditor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type Operation interface {
+	Apply(csv *csvparser.CSV) (Operation, error)
+	Kind() string
+	String() string
+}
+
+type SetCellOp struct {
+	Row    int    `json:"row"`
+	Column string `json:"column"`
+	Value  string `json:"value"`
+}
+
+func (op *SetCellOp) Apply(csv *csvparser.CSV) (Operation, error) {
+	col, err := columnIndex(csv.Header, op.Column)
+	if err != nil {
+		return nil, err
+	}
+	old, err := SetCell(csv, op.Row, col, op.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &SetCellOp{Row: op.Row, Column: op.Column, Value: old}, nil
+}
+
+func (op *SetCellOp) Kind() string { return "set-cell" }
+
+func (op *SetCellOp) String() string {
+	return fmt.Sprintf("set row %d of %q to %q", op.Row+1, op.Column, op.Value)
+}
+
+type SetColumnOp struct {
+	Column string   `json:"column"`
+	Values []string `json:"values"`
+}
+
+func (op *SetColumnOp) Apply(csv *csvparser.CSV) (Operation, error) {
+	col, err := columnIndex(csv.Header, op.Column)
+	if err != nil {
+		return nil, err
+	}
+	if len(op.Values) != len(csv.Records) {
+		return nil, fmt.Errorf("got %d values for %d rows", len(op.Values), len(csv.Records))
+	}
+
+	old := make([]string, len(csv.Records))
+	for i, value := range op.Values {
+		if col >= len(csv.Records[i]) && value == "" {
+			continue
+		}
+		old[i], _ = SetCell(csv, i, col, value)
+	}
+	return &SetColumnOp{Column: op.Column, Values: old}, nil
+}
+
+func (op *SetColumnOp) Kind() string { return "set-column" }
+
+func (op *SetColumnOp) String() string {
+	return fmt.Sprintf("set column %q", op.Column)
+}
+
+type TransformOp struct {
+	Column string   `json:"column"`
+	Func   string   `json:"func"`
+	Args   []string `json:"args,omitempty"`
+}
+
+func (op *TransformOp) Apply(csv *csvparser.CSV) (Operation, error) {
+	transform, err := NamedTransform(op.Func, op.Args...)
+	if err != nil {
+		return nil, err
+	}
+	col, err := columnIndex(csv.Header, op.Column)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(csv.Records))
+	for i, record := range csv.Records {
+		if col < len(record) {
+			values[i] = transform(record[col])
+		}
+	}
+	return (&SetColumnOp{Column: op.Column, Values: values}).Apply(csv)
+}
+
+func (op *TransformOp) Kind() string { return "transform" }
+
+func (op *TransformOp) String() string {
+	return fmt.Sprintf("%s column %q", op.Func, op.Column)
+}
+
+type InsertRowOp struct {
+	Index  int      `json:"index"`
+	Record []string `json:"record,omitempty"`
+}
+
+func (op *InsertRowOp) Apply(csv *csvparser.CSV) (Operation, error) {
+	if err := InsertRow(csv, op.Index, slices.Clone(op.Record)); err != nil {
+		return nil, err
+	}
+	return &DeleteRowOp{Index: op.Index}, nil
+}
+
+func (op *InsertRowOp) Kind() string { return "insert-row" }
+
+func (op *InsertRowOp) String() string {
+	return fmt.Sprintf("insert row %d", op.Index+1)
+}
+
+type DeleteRowOp struct {
+	Index int `json:"index"`
+}
+
+func (op *DeleteRowOp) Apply(csv *csvparser.CSV) (Operation, error) {
+	record, err := DeleteRow(csv, op.Index)
+	if err != nil {
+		return nil, err
+	}
+	return &InsertRowOp{Index: op.Index, Record: record}, nil
+}
+
+func (op *DeleteRowOp) Kind() string { return "delete-row" }
+
+func (op *DeleteRowOp) String() string {
+	return fmt.Sprintf("delete row %d", op.Index+1)
+}
+
+type InsertColumnOp struct {
+	Index  int      `json:"index"`
+	Name   string   `json:"name"`
+	Values []string `json:"values,omitempty"`
+}
+
+func (op *InsertColumnOp) Apply(csv *csvparser.CSV) (Operation, error) {
+	if slices.Contains(csv.Header, op.Name) {
+		return nil, fmt.Errorf("column %q already exists", op.Name)
+	}
+	if err := InsertColumn(csv, op.Index, op.Name, op.Values); err != nil {
+		return nil, err
+	}
+	return &DeleteColumnOp{Column: op.Name}, nil
+}
+
+func (op *InsertColumnOp) Kind() string { return "insert-column" }
+
+func (op *InsertColumnOp) String() string {
+	return fmt.Sprintf("insert column %q", op.Name)
+}
+
+type DeleteColumnOp struct {
+	Column string `json:"column"`
+}
+
+func (op *DeleteColumnOp) Apply(csv *csvparser.CSV) (Operation, error) {
+	index, err := columnIndex(csv.Header, op.Column)
+	if err != nil {
+		return nil, err
+	}
+	name, values, err := DeleteColumn(csv, index)
+	if err != nil {
+		return nil, err
+	}
+	return &InsertColumnOp{Index: index, Name: name, Values: values}, nil
+}
+
+func (op *DeleteColumnOp) Kind() string { return "delete-column" }
+
+func (op *DeleteColumnOp) String() string {
+	return fmt.Sprintf("delete column %q", op.Column)
+}
+
+type MoveRowOp struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+func (op *MoveRowOp) Apply(csv *csvparser.CSV) (Operation, error) {
+	if err := MoveRow(csv, op.From, op.To); err != nil {
+		return nil, err
+	}
+	return &MoveRowOp{From: op.To, To: op.From}, nil
+}
+
+func (op *MoveRowOp) Kind() string { return "move-row" }
+
+func (op *MoveRowOp) String() string {
+	return fmt.Sprintf("move row %d to %d", op.From+1, op.To+1)
+}
+
+type MoveColumnOp struct {
+	Column string `json:"column"`
+	To     int    `json:"to"`
+}
+
+func (op *MoveColumnOp) Apply(csv *csvparser.CSV) (Operation, error) {
+	from, err := columnIndex(csv.Header, op.Column)
+	if err != nil {
+		return nil, err
+	}
+	for i, record := range csv.Records {
+		if len(record) < len(csv.Header) {
+			padded := make([]string, len(csv.Header))
+			copy(padded, record)
+			csv.Records[i] = padded
+		}
+	}
+	if err := MoveColumn(csv, op.Column, op.To); err != nil {
+		return nil, err
+	}
+	return &MoveColumnOp{Column: op.Column, To: from}, nil
+}
+
+func (op *MoveColumnOp) Kind() string { return "move-column" }
+
+func (op *MoveColumnOp) String() string {
+	return fmt.Sprintf("move column %q to %d", op.Column, op.To+1)
+}
+
+type RenameColumnOp struct {
+	Column string `json:"column"`
+	Name   string `json:"name"`
+}
+
+func (op *RenameColumnOp) Apply(csv *csvparser.CSV) (Operation, error) {
+	header, err := renamedHeader(csv.Header, op.Column, op.Name)
+	if err != nil {
+		return nil, err
+	}
+	csv.Header = header
+	return &RenameColumnOp{Column: op.Name, Name: op.Column}, nil
+}
+
+func (op *RenameColumnOp) Kind() string { return "rename-column" }
+
+func (op *RenameColumnOp) String() string {
+	return fmt.Sprintf("rename column %q to %q", op.Column, op.Name)
+}
+
+var operations = map[string]func() Operation{
+	"set-cell":      func() Operation { return &SetCellOp{} },
+	"set-column":    func() Operation { return &SetColumnOp{} },
+	"transform":     func() Operation { return &TransformOp{} },
+	"insert-row":    func() Operation { return &InsertRowOp{} },
+	"delete-row":    func() Operation { return &DeleteRowOp{} },
+	"insert-column": func() Operation { return &InsertColumnOp{} },
+	"delete-column": func() Operation { return &DeleteColumnOp{} },
+	"move-row":      func() Operation { return &MoveRowOp{} },
+	"move-column":   func() Operation { return &MoveColumnOp{} },
+	"rename-column": func() Operation { return &RenameColumnOp{} },
+}
+
+type Entry struct {
+	ID   int
+	Do   Operation
+	Undo Operation
+}
+
+type Journal struct {
+	csv    *csvparser.CSV
+	undo   []Entry
+	redo   []Entry
+	nextID int
+}
+
+func NewJournal(csv *csvparser.CSV) *Journal {
+	return &Journal{csv: csv, nextID: 1}
+}
+
+func (j *Journal) Apply(op Operation) (Entry, error) {
+	inverse, err := op.Apply(j.csv)
+	if err != nil {
+		return Entry{}, err
+	}
+	entry := Entry{ID: j.nextID, Do: op, Undo: inverse}
+	j.nextID++
+	j.undo = append(j.undo, entry)
+	j.redo = nil
+	return entry, nil
+}
+
+func (j *Journal) Undo() (Entry, bool, error) {
+	if len(j.undo) == 0 {
+		return Entry{}, false, nil
+	}
+	entry := j.undo[len(j.undo)-1]
+	redo, err := entry.Undo.Apply(j.csv)
+	if err != nil {
+		return entry, false, err
+	}
+	entry.Do = redo
+	j.undo = j.undo[:len(j.undo)-1]
+	j.redo = append(j.redo, entry)
+	return entry, true, nil
+}
+
+func (j *Journal) Redo() (Entry, bool, error) {
+	if len(j.redo) == 0 {
+		return Entry{}, false, nil
+	}
+	entry := j.redo[len(j.redo)-1]
+	undo, err := entry.Do.Apply(j.csv)
+	if err != nil {
+		return entry, false, err
+	}
+	entry.Undo = undo
+	j.redo = j.redo[:len(j.redo)-1]
+	j.undo = append(j.undo, entry)
+	return entry, true, nil
+}
+
+func (j *Journal) Entries() []Entry {
+	return j.undo
+}
+
+func (j *Journal) Version() int {
+	if len(j.undo) == 0 {
+		return 0
+	}
+	return j.undo[len(j.undo)-1].ID
+}
+
+func Replay(csv *csvparser.CSV, entries []Entry) error {
+	for i, entry := range entries {
+		if _, err := entry.Do.Apply(csv); err != nil {
+			return fmt.Errorf("journal entry %d (%s): %w", i+1, entry.Do, err)
+		}
+	}
+	return nil
+}
+
+func Rollback(csv *csvparser.CSV, entries []Entry) error {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if _, err := entries[i].Undo.Apply(csv); err != nil {
+			return fmt.Errorf("journal entry %d (undo %s): %w", i+1, entries[i].Do, err)
+		}
+	}
+	return nil
+}
+
+type journalLine struct {
+	Do   json.RawMessage `json:"do"`
+	Undo json.RawMessage `json:"undo"`
+}
+
+func WriteJournal(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		do, err := marshalOperation(entry.Do)
+		if err != nil {
+			return err
+		}
+		undo, err := marshalOperation(entry.Undo)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(journalLine{Do: do, Undo: undo}); err != nil {
+			return fmt.Errorf("failed to write journal: %w", err)
+		}
+	}
+	return nil
+}
+
+func ReadJournal(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<30)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var line journalLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("invalid journal line %d: %w", n, err)
+		}
+		do, err := unmarshalOperation(line.Do)
+		if err != nil {
+			return nil, fmt.Errorf("invalid journal line %d: %w", n, err)
+		}
+		undo, err := unmarshalOperation(line.Undo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid journal line %d: %w", n, err)
+		}
+		entries = append(entries, Entry{ID: len(entries) + 1, Do: do, Undo: undo})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+func marshalOperation(op Operation) (json.RawMessage, error) {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["op"], _ = json.Marshal(op.Kind())
+	return json.Marshal(fields)
+}
+
+func unmarshalOperation(data json.RawMessage) (Operation, error) {
+	var kind struct {
+		Op string `json:"op"`
+	}
+	if err := json.Unmarshal(data, &kind); err != nil {
+		return nil, err
+	}
+	newOp, ok := operations[kind.Op]
+	if !ok {
+		return nil, fmt.Errorf("unknown operation %q", kind.Op)
+	}
+	op := newOp()
+	if err := json.Unmarshal(data, op); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
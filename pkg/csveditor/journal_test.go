@@ -0,0 +1,201 @@
+package csveditor
+
+import (
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+func journalCSV() *csvparser.CSV {
+	return &csvparser.CSV{
+		Header:  []string{"Name", "Age", "City"},
+		Records: [][]string{{"John", "30", "NYC"}, {"Jane", "25", "LA"}, {"Bob", "35"}},
+	}
+}
+
+func csvString(csv *csvparser.CSV) string {
+	return strings.Join(csv.Header, ",") + "|" + joinRecords(csv.Records)
+}
+
+func TestOperationInverse(t *testing.T) {
+	original := csvString(journalCSV())
+	tests := []struct {
+		op   Operation
+		want string
+	}{
+		{&SetCellOp{Row: 1, Column: "City", Value: "SF"}, "Name,Age,City|John,30,NYC;Jane,25,SF;Bob,35"},
+		{&SetColumnOp{Column: "Name", Values: []string{"a", "b", "c"}}, "Name,Age,City|a,30,NYC;b,25,LA;c,35"},
+		{&TransformOp{Column: "City", Func: "lower"}, "Name,Age,City|John,30,nyc;Jane,25,la;Bob,35"},
+		{&InsertRowOp{Index: 1, Record: []string{"Ann", "40", "SF"}}, "Name,Age,City|John,30,NYC;Ann,40,SF;Jane,25,LA;Bob,35"},
+		{&DeleteRowOp{Index: 0}, "Name,Age,City|Jane,25,LA;Bob,35"},
+		{&InsertColumnOp{Index: 1, Name: "ID"}, "Name,ID,Age,City|John,,30,NYC;Jane,,25,LA;Bob,,35"},
+		{&DeleteColumnOp{Column: "Age"}, "Name,City|John,NYC;Jane,LA;Bob"},
+		{&MoveRowOp{From: 0, To: 2}, "Name,Age,City|Jane,25,LA;Bob,35;John,30,NYC"},
+		{&MoveColumnOp{Column: "City", To: 0}, "City,Name,Age|NYC,John,30;LA,Jane,25;,Bob,35"},
+		{&RenameColumnOp{Column: "Age", Name: "Years"}, "Name,Years,City|John,30,NYC;Jane,25,LA;Bob,35"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.op.String(), func(t *testing.T) {
+			csv := journalCSV()
+			inverse, err := tt.op.Apply(csv)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if got := csvString(csv); got != tt.want {
+				t.Errorf("Apply() = %s, want %s", got, tt.want)
+			}
+			if _, err := inverse.Apply(csv); err != nil {
+				t.Fatalf("inverse Apply() error = %v", err)
+			}
+			if got := strings.TrimSuffix(csvString(csv), ","); got != original {
+				t.Errorf("inverse Apply() = %s, want %s", got, original)
+			}
+		})
+	}
+}
+
+func TestJournalUndoRedo(t *testing.T) {
+	csv := journalCSV()
+	original := csvString(csv)
+	j := NewJournal(csv)
+
+	for _, op := range []Operation{
+		&SetCellOp{Row: 0, Column: "Age", Value: "31"},
+		&DeleteRowOp{Index: 2},
+		&InsertColumnOp{Index: 3, Name: "Zip", Values: []string{"10001", "90001"}},
+	} {
+		if _, err := j.Apply(op); err != nil {
+			t.Fatalf("Apply(%s) error = %v", op, err)
+		}
+	}
+	edited := csvString(csv)
+	if edited != "Name,Age,City,Zip|John,31,NYC,10001;Jane,25,LA,90001" {
+		t.Fatalf("after edits = %s", edited)
+	}
+	if j.Version() != 3 {
+		t.Errorf("Version() = %d, want 3", j.Version())
+	}
+
+	for range 3 {
+		if _, ok, err := j.Undo(); !ok || err != nil {
+			t.Fatalf("Undo() = %v, %v", ok, err)
+		}
+	}
+	if got := csvString(csv); got != original {
+		t.Errorf("after undo = %s, want %s", got, original)
+	}
+	if _, ok, _ := j.Undo(); ok {
+		t.Error("Undo() with empty history = true, want false")
+	}
+	if j.Version() != 0 {
+		t.Errorf("Version() = %d, want 0", j.Version())
+	}
+
+	for range 3 {
+		if _, ok, err := j.Redo(); !ok || err != nil {
+			t.Fatalf("Redo() = %v, %v", ok, err)
+		}
+	}
+	if got := csvString(csv); got != edited {
+		t.Errorf("after redo = %s, want %s", got, edited)
+	}
+
+	j.Undo()
+	j.Apply(&RenameColumnOp{Column: "Name", Name: "Who"})
+	if _, ok, _ := j.Redo(); ok {
+		t.Error("Redo() after a new edit = true, want false")
+	}
+	if j.Version() != 4 {
+		t.Errorf("Version() = %d, want 4", j.Version())
+	}
+}
+
+func TestJournalReplayRollback(t *testing.T) {
+	csv := journalCSV()
+	j := NewJournal(csv)
+	for _, op := range []Operation{
+		&MoveColumnOp{Column: "City", To: 0},
+		&TransformOp{Column: "Name", Func: "upper"},
+		&InsertRowOp{Index: 3, Record: []string{"LA", "Ann", "40"}},
+		&RenameColumnOp{Column: "City", Name: "Town"},
+	} {
+		if _, err := j.Apply(op); err != nil {
+			t.Fatalf("Apply(%s) error = %v", op, err)
+		}
+	}
+	edited := csvString(csv)
+
+	var buf strings.Builder
+	if err := WriteJournal(&buf, j.Entries()); err != nil {
+		t.Fatalf("WriteJournal() error = %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 4 {
+		t.Errorf("WriteJournal() wrote %d lines, want 4:\n%s", lines, buf.String())
+	}
+	entries, err := ReadJournal(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("ReadJournal() error = %v", err)
+	}
+
+	replayed := journalCSV()
+	if err := Replay(replayed, entries); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if got := csvString(replayed); got != edited {
+		t.Errorf("Replay() = %s, want %s", got, edited)
+	}
+
+	if err := Rollback(replayed, entries); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if got, want := csvString(replayed), "Name,Age,City|John,30,NYC;Jane,25,LA;Bob,35,"; got != want {
+		t.Errorf("Rollback() = %s, want %s", got, want)
+	}
+
+	if _, err := ReadJournal(strings.NewReader(`{"do":{"op":"explode"},"undo":{"op":"set-cell"}}`)); err == nil {
+		t.Error("ReadJournal() with unknown operation: expected error")
+	}
+}
+
+func TestJournalReplayByName(t *testing.T) {
+	var buf strings.Builder
+	j := NewJournal(journalCSV())
+	for _, op := range []Operation{
+		&TransformOp{Column: "City", Func: "replace", Args: []string{"A", "a"}},
+		&RenameColumnOp{Column: "Age", Name: "Years"},
+		&DeleteColumnOp{Column: "Name"},
+	} {
+		if _, err := j.Apply(op); err != nil {
+			t.Fatalf("Apply(%s) error = %v", op, err)
+		}
+	}
+	if err := WriteJournal(&buf, j.Entries()); err != nil {
+		t.Fatalf("WriteJournal() error = %v", err)
+	}
+	first, _, _ := strings.Cut(buf.String(), "\n")
+	if want := `{"do":{"args":["A","a"],"column":"City","func":"replace","op":"transform"},"undo":{"column":"City","op":"set-column","values":["NYC","LA",""]}}`; first != want {
+		t.Errorf("transform entry = %s, want %s", first, want)
+	}
+	entries, err := ReadJournal(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("ReadJournal() error = %v", err)
+	}
+
+	other := &csvparser.CSV{
+		Header:  []string{"City", "Age", "Name", "Zip"},
+		Records: [][]string{{"SAN ANTONIO", "40", "Ann", "78201"}},
+	}
+	if err := Replay(other, entries); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if got, want := csvString(other), "City,Years,Zip|SaN aNTONIO,40,78201"; got != want {
+		t.Errorf("Replay() = %s, want %s", got, want)
+	}
+
+	missing := &csvparser.CSV{Header: []string{"Town"}, Records: [][]string{{"NYC"}}}
+	if err := Replay(missing, entries); err == nil || !strings.Contains(err.Error(), `column "City" not found`) {
+		t.Errorf("Replay() without the column error = %v", err)
+	}
+}
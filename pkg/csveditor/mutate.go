@@ -0,0 +1,176 @@
+package csveditor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type ErrorMode int
+
+const (
+	ErrorFail ErrorMode = iota
+	ErrorNull
+	ErrorSkip
+)
+
+var errorModes = []string{"fail", "null", "skip"}
+
+func ParseErrorMode(name string) (ErrorMode, error) {
+	switch strings.ToLower(name) {
+	case "", "fail":
+		return ErrorFail, nil
+	case "null":
+		return ErrorNull, nil
+	case "skip":
+		return ErrorSkip, nil
+	}
+	return ErrorFail, fmt.Errorf("unknown error mode %q (available: %s)", name, strings.Join(errorModes, ", "))
+}
+
+type Mutation struct {
+	Column string
+	Expr   *Expression
+}
+
+func ParseMutation(assignment string, header []string) (Mutation, error) {
+	tokens, err := tokenize(assignment)
+	if err != nil {
+		return Mutation{}, err
+	}
+	p := &exprParser{expr: assignment, tokens: tokens, header: header}
+	name := p.next()
+	if name.kind != tokenIdent && name.kind != tokenColumn && name.kind != tokenString {
+		return Mutation{}, p.errorf(name, "expected a column name (use \"column = expression\")")
+	}
+	if eq := p.next(); eq.kind != tokenOperator || eq.text != "=" {
+		return Mutation{}, p.errorf(eq, "expected = after the column name (use \"column = expression\")")
+	}
+	expr, err := parseExpressionTokens(p)
+	if err != nil {
+		return Mutation{}, err
+	}
+	return Mutation{Column: name.val, Expr: expr}, nil
+}
+
+type RowError struct {
+	Row    int
+	Column string
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d, column %q: %v", e.Row, e.Column, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+type Mutator struct {
+	header    []string
+	mutations []Mutation
+	indices   []int
+	mode      ErrorMode
+}
+
+func NewMutator(header []string, assignments []string, mode ErrorMode) (*Mutator, error) {
+	m := &Mutator{header: slices.Clone(header), mode: mode}
+	for _, assignment := range assignments {
+		mutation, err := ParseMutation(assignment, m.header)
+		if err != nil {
+			return nil, err
+		}
+		index := slices.Index(m.header, mutation.Column)
+		if index < 0 {
+			index = len(m.header)
+			m.header = append(m.header, mutation.Column)
+		}
+		m.mutations = append(m.mutations, mutation)
+		m.indices = append(m.indices, index)
+	}
+	return m, nil
+}
+
+func (m *Mutator) Header() []string {
+	return m.header
+}
+
+func (m *Mutator) Apply(record []string, row int) ([]string, error) {
+	out := make([]string, len(m.header))
+	copy(out, record)
+	var rowErr error
+	for i, mutation := range m.mutations {
+		value, err := mutation.Expr.EvalString(out)
+		if err != nil {
+			err = &RowError{Row: row, Column: mutation.Column, Err: err}
+			if m.mode != ErrorNull {
+				return nil, err
+			}
+			if rowErr == nil {
+				rowErr = err
+			}
+		}
+		out[m.indices[i]] = value
+	}
+	return out, rowErr
+}
+
+func (m *Mutator) apply(record []string, row int, onError func(error)) ([]string, error) {
+	out, err := m.Apply(record, row)
+	if err == nil {
+		return out, nil
+	}
+	if m.mode == ErrorFail {
+		return nil, err
+	}
+	if onError != nil {
+		onError(err)
+	}
+	return out, nil
+}
+
+func MutateStream(r *csvparser.Reader, w *csvparser.Writer, m *Mutator, onError func(error)) (int, error) {
+	if err := w.WriteHeader(m.Header()); err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	row := 0
+	err := r.ForEach(func(record []string) error {
+		row++
+		out, err := m.apply(record, row, func(err error) {
+			failed++
+			if onError != nil {
+				onError(err)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		if out == nil {
+			return nil
+		}
+		return w.Write(out)
+	})
+	return failed, err
+}
+
+func Mutate(csv *csvparser.CSV, m *Mutator, onError func(error)) (*csvparser.CSV, error) {
+	mutated := &csvparser.CSV{
+		Header:  m.Header(),
+		Records: [][]string{},
+	}
+	for i, record := range csv.Records {
+		out, err := m.apply(record, i+1, onError)
+		if err != nil {
+			return nil, err
+		}
+		if out != nil {
+			mutated.Records = append(mutated.Records, out)
+		}
+	}
+	return mutated, nil
+}
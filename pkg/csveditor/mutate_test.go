@@ -0,0 +1,137 @@
+package csveditor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+func TestParseMutation(t *testing.T) {
+	header := []string{"Price", "Qty"}
+	tests := []struct {
+		assignment string
+		column     string
+		err        string
+	}{
+		{`Total = Price * Qty`, "Total", ""},
+		{"`Unit Price` = Price", "Unit Price", ""},
+		{`Price = round(Price, 1)`, "Price", ""},
+		{`Total == Price`, "", "expected = after the column name"},
+		{`Price * Qty`, "", "expected = after the column name"},
+		{`= Price`, "", "expected a column name"},
+		{`Total =`, "", "empty expression"},
+		{`Total = Cost`, "", `unknown column "Cost"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.assignment, func(t *testing.T) {
+			m, err := ParseMutation(tt.assignment, header)
+			if tt.err != "" {
+				var perr *ParseError
+				if !errors.As(err, &perr) || !strings.Contains(perr.Message, tt.err) {
+					t.Fatalf("ParseMutation() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMutation() error = %v", err)
+			}
+			if m.Column != tt.column {
+				t.Errorf("ParseMutation() column = %q, want %q", m.Column, tt.column)
+			}
+		})
+	}
+}
+
+func TestMutate(t *testing.T) {
+	csv := &csvparser.CSV{
+		Header: []string{"Name", "Price", "Qty"},
+		Records: [][]string{
+			{"apple", "1.5", "4"},
+			{"pear", "n/a", "2"},
+			{"plum", "2", ""},
+		},
+	}
+	assignments := []string{`Total = Price * Qty`, `Name = upper(Name)`, `Label = Name + ": " + Total`}
+
+	tests := []struct {
+		mode    ErrorMode
+		want    [][]string
+		errors  int
+		failure string
+	}{
+		{ErrorFail, nil, 0, `row 2, column "Total": cannot use "n/a" as a number`},
+		{ErrorNull, [][]string{
+			{"APPLE", "1.5", "4", "6", "APPLE: 6"},
+			{"PEAR", "n/a", "2", "", "PEAR: "},
+			{"PLUM", "2", "", "", "PLUM: "},
+		}, 1, ""},
+		{ErrorSkip, [][]string{
+			{"APPLE", "1.5", "4", "6", "APPLE: 6"},
+			{"PLUM", "2", "", "", "PLUM: "},
+		}, 1, ""},
+	}
+
+	for _, tt := range tests {
+		m, err := NewMutator(csv.Header, assignments, tt.mode)
+		if err != nil {
+			t.Fatalf("NewMutator() error = %v", err)
+		}
+		if got := strings.Join(m.Header(), ","); got != "Name,Price,Qty,Total,Label" {
+			t.Errorf("Header() = %s, want Name,Price,Qty,Total,Label", got)
+		}
+
+		var errs []error
+		mutated, err := Mutate(csv, m, func(err error) { errs = append(errs, err) })
+		if tt.failure != "" {
+			var rerr *RowError
+			if !errors.As(err, &rerr) || err.Error() != tt.failure {
+				t.Errorf("Mutate(mode %d) error = %v, want %q", tt.mode, err, tt.failure)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Mutate(mode %d) error = %v", tt.mode, err)
+		}
+		if len(errs) != tt.errors {
+			t.Errorf("Mutate(mode %d) reported %d errors, want %d", tt.mode, len(errs), tt.errors)
+		}
+		if len(mutated.Records) != len(tt.want) {
+			t.Fatalf("Mutate(mode %d) = %v, want %v", tt.mode, mutated.Records, tt.want)
+		}
+		for i := range tt.want {
+			if got, want := strings.Join(mutated.Records[i], ","), strings.Join(tt.want[i], ","); got != want {
+				t.Errorf("Mutate(mode %d) row %d = %s, want %s", tt.mode, i+1, got, want)
+			}
+		}
+	}
+}
+
+func TestMutateStream(t *testing.T) {
+	got := runStream(t, func(r *csvparser.Reader, w *csvparser.Writer) error {
+		m, err := NewMutator(r.Header(), []string{`Age = number(Age) + 1`, `Senior = Age > 30 ? "yes" : "no"`}, ErrorFail)
+		if err != nil {
+			return err
+		}
+		_, err = MutateStream(r, w, m, nil)
+		return err
+	})
+
+	expected := "Name,Age,City,Senior\nJohn,31,New York,yes\nJane,26,New York,no\nBob,36,Chicago,yes\n"
+	if got != expected {
+		t.Errorf("MutateStream() = %q, want %q", got, expected)
+	}
+}
+
+func TestParseErrorMode(t *testing.T) {
+	for name, want := range map[string]ErrorMode{"": ErrorFail, "fail": ErrorFail, "NULL": ErrorNull, "skip": ErrorSkip} {
+		if got, err := ParseErrorMode(name); err != nil || got != want {
+			t.Errorf("ParseErrorMode(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseErrorMode("ignore"); err == nil {
+		t.Error("ParseErrorMode(ignore) error = nil, want an error")
+	}
+}
@@ -0,0 +1,364 @@
+package csveditor
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type SortType int
+
+const (
+	SortString SortType = iota
+	SortCaseInsensitive
+	SortNumeric
+	SortNatural
+	SortDate
+)
+
+type EmptyPlacement int
+
+const (
+	EmptyDefault EmptyPlacement = iota
+	EmptyLast
+	EmptyFirst
+)
+
+type SortKey struct {
+	ColumnName string
+	Descending bool
+	Type       SortType
+	Layout     string
+	Empty      EmptyPlacement
+}
+
+type SortConfig struct {
+	ColumnName string
+	Descending bool
+	Keys       []SortKey
+	Empty      EmptyPlacement
+}
+
+var isoDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func ParseSortKey(spec string) (SortKey, error) {
+	var modifiers []string
+	name := spec
+	for {
+		i := strings.LastIndex(name, ":")
+		if i < 0 {
+			break
+		}
+		if part := name[i+1:]; isSortModifier(part) {
+			modifiers = append(modifiers, part)
+			name = name[:i]
+			continue
+		}
+		if j := strings.LastIndex(strings.ToLower(name), ":date="); j >= 0 {
+			modifiers = append(modifiers, name[j+1:])
+			name = name[:j]
+			continue
+		}
+		break
+	}
+
+	key := SortKey{ColumnName: name}
+	if key.ColumnName == "" {
+		return key, fmt.Errorf("invalid sort key %q: missing column name", spec)
+	}
+
+	for i := len(modifiers) - 1; i >= 0; i-- {
+		part := strings.ToLower(modifiers[i])
+		switch part {
+		case "asc":
+			key.Descending = false
+		case "desc", "r":
+			key.Descending = true
+		case "str", "string":
+			key.Type = SortString
+		case "istr", "nocase", "i":
+			key.Type = SortCaseInsensitive
+		case "num", "numeric", "n":
+			key.Type = SortNumeric
+		case "natural", "nat", "version", "v":
+			key.Type = SortNatural
+		case "date":
+			key.Type = SortDate
+		case "empty-first":
+			key.Empty = EmptyFirst
+		case "empty-last":
+			key.Empty = EmptyLast
+		default:
+			key.Type = SortDate
+			key.Layout = modifiers[i][len("date="):]
+			if key.Layout == "" {
+				return key, fmt.Errorf("invalid sort key %q: empty date layout", spec)
+			}
+		}
+	}
+
+	return key, nil
+}
+
+func isSortModifier(part string) bool {
+	switch strings.ToLower(part) {
+	case "asc", "desc", "r", "str", "string", "istr", "nocase", "i", "num", "numeric", "n",
+		"natural", "nat", "version", "v", "date", "empty-first", "empty-last":
+		return true
+	}
+	return false
+}
+
+func (c SortConfig) keys() []SortKey {
+	if len(c.Keys) > 0 {
+		return c.Keys
+	}
+	return []SortKey{{ColumnName: c.ColumnName, Descending: c.Descending}}
+}
+
+type sortValue struct {
+	ok  bool
+	num float64
+	str string
+	t   time.Time
+}
+
+type keyComparator struct {
+	index      int
+	descending bool
+	emptyFirst bool
+	parse      func(string) sortValue
+	compare    func(a, b sortValue) int
+}
+
+type keyedRecord struct {
+	record []string
+	keys   []sortValue
+}
+
+type sorter struct {
+	keys []keyComparator
+}
+
+func newSorter(header []string, config SortConfig) (*sorter, error) {
+	s := &sorter{}
+	for _, key := range config.keys() {
+		index, err := columnIndex(header, key.ColumnName)
+		if err != nil {
+			if strings.Contains(key.ColumnName, ":") {
+				err = fmt.Errorf("%w (sort modifiers are asc, desc, str, istr, num, natural, date, date=LAYOUT, empty-first and empty-last)", err)
+			}
+			return nil, err
+		}
+
+		if key.Empty == EmptyDefault {
+			key.Empty = config.Empty
+		}
+		s.keys = append(s.keys, newKeyComparator(index, key))
+	}
+	return s, nil
+}
+
+func newKeyComparator(index int, key SortKey) keyComparator {
+	kc := keyComparator{
+		index:      index,
+		descending: key.Descending,
+		emptyFirst: key.Empty == EmptyFirst,
+	}
+	kc.parse, kc.compare = sortFuncs(key)
+	return kc
+}
+
+func (s *sorter) keyed(record []string) keyedRecord {
+	keys := make([]sortValue, len(s.keys))
+	for i, kc := range s.keys {
+		keys[i] = kc.parse(cell(record, kc.index))
+	}
+	return keyedRecord{record: record, keys: keys}
+}
+
+func (s *sorter) compare(a, b keyedRecord) int {
+	for i, kc := range s.keys {
+		if c := kc.compareValues(a.keys[i], b.keys[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (s *sorter) sort(records [][]string) {
+	keyed := make([]keyedRecord, len(records))
+	for i, record := range records {
+		keyed[i] = s.keyed(record)
+	}
+	slices.SortStableFunc(keyed, s.compare)
+	for i := range keyed {
+		records[i] = keyed[i].record
+	}
+}
+
+func SortIndices(records [][]string, indices []int, column int, key SortKey) {
+	kc := newKeyComparator(column, key)
+	type keyedIndex struct {
+		index int
+		value sortValue
+	}
+	keyed := make([]keyedIndex, len(indices))
+	for i, index := range indices {
+		keyed[i] = keyedIndex{index: index, value: kc.parse(cell(records[index], column))}
+	}
+	slices.SortFunc(keyed, func(a, b keyedIndex) int {
+		if c := kc.compareValues(a.value, b.value); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.index, b.index)
+	})
+	for i := range keyed {
+		indices[i] = keyed[i].index
+	}
+}
+
+func (kc keyComparator) compareValues(a, b sortValue) int {
+	switch {
+	case !a.ok && !b.ok:
+		return 0
+	case !a.ok:
+		if kc.emptyFirst {
+			return -1
+		}
+		return 1
+	case !b.ok:
+		if kc.emptyFirst {
+			return 1
+		}
+		return -1
+	}
+
+	c := kc.compare(a, b)
+	if kc.descending {
+		return -c
+	}
+	return c
+}
+
+func sortFuncs(key SortKey) (func(string) sortValue, func(a, b sortValue) int) {
+	compareStrings := func(a, b sortValue) int {
+		return strings.Compare(a.str, b.str)
+	}
+
+	switch key.Type {
+	case SortCaseInsensitive:
+		return func(s string) sortValue {
+			return sortValue{ok: s != "", str: strings.ToLower(s)}
+		}, compareStrings
+	case SortNumeric:
+		return func(s string) sortValue {
+				f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+				return sortValue{ok: err == nil && !math.IsNaN(f), num: f}
+			}, func(a, b sortValue) int {
+				return cmp.Compare(a.num, b.num)
+			}
+	case SortNatural:
+		return func(s string) sortValue {
+				return sortValue{ok: s != "", str: s}
+			}, func(a, b sortValue) int {
+				return compareNatural(a.str, b.str)
+			}
+	case SortDate:
+		layouts := isoDateLayouts
+		if key.Layout != "" {
+			layouts = []string{key.Layout}
+		}
+		return func(s string) sortValue {
+				s = strings.TrimSpace(s)
+				for _, layout := range layouts {
+					if t, err := time.Parse(layout, s); err == nil {
+						return sortValue{ok: true, t: t}
+					}
+				}
+				return sortValue{}
+			}, func(a, b sortValue) int {
+				return a.t.Compare(b.t)
+			}
+	default:
+		return func(s string) sortValue {
+			return sortValue{ok: s != "", str: s}
+		}, compareStrings
+	}
+}
+
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		chunkA, restA := naturalChunk(a)
+		chunkB, restB := naturalChunk(b)
+
+		digitsA := isDigit(chunkA[0])
+		digitsB := isDigit(chunkB[0])
+
+		var c int
+		if digitsA && digitsB {
+			trimmedA := strings.TrimLeft(chunkA, "0")
+			trimmedB := strings.TrimLeft(chunkB, "0")
+			if len(trimmedA) != len(trimmedB) {
+				c = len(trimmedA) - len(trimmedB)
+			} else {
+				c = strings.Compare(trimmedA, trimmedB)
+			}
+		} else {
+			c = strings.Compare(chunkA, chunkB)
+		}
+
+		if c != 0 {
+			if c < 0 {
+				return -1
+			}
+			return 1
+		}
+		a, b = restA, restB
+	}
+
+	return strings.Compare(a, b)
+}
+
+func naturalChunk(s string) (string, string) {
+	digits := isDigit(s[0])
+	i := 1
+	for i < len(s) && isDigit(s[i]) == digits {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func Sort(csv *csvparser.CSV, config SortConfig) error {
+	s, err := newSorter(csv.Header, config)
+	if err != nil {
+		return err
+	}
+
+	s.sort(csv.Records)
+	return nil
+}
+
+func cell(record []string, index int) string {
+	if index < len(record) {
+		return record[index]
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package csveditor
+
+import (
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+func TestParseSortKey(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    SortKey
+		wantErr bool
+	}{
+		{"Name", SortKey{ColumnName: "Name"}, false},
+		{"Revenue:num:desc", SortKey{ColumnName: "Revenue", Type: SortNumeric, Descending: true}, false},
+		{"Version:natural", SortKey{ColumnName: "Version", Type: SortNatural}, false},
+		{"Name:istr:empty-first", SortKey{ColumnName: "Name", Type: SortCaseInsensitive, Empty: EmptyFirst}, false},
+		{"Date:date", SortKey{ColumnName: "Date", Type: SortDate}, false},
+		{"Created:date=02/01/2006 15:04:desc", SortKey{ColumnName: "Created", Type: SortDate, Layout: "02/01/2006 15:04", Descending: true}, false},
+		{":num", SortKey{}, true},
+		{"Name:bogus", SortKey{ColumnName: "Name:bogus"}, false},
+		{"Time:Zone:num", SortKey{ColumnName: "Time:Zone", Type: SortNumeric}, false},
+		{"a:b:date=15:04:desc", SortKey{ColumnName: "a:b", Type: SortDate, Layout: "15:04", Descending: true}, false},
+		{"Created:date=", SortKey{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSortKey(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSortKey(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseSortKey(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func sortedColumn(t *testing.T, values []string, config SortConfig) []string {
+	t.Helper()
+
+	csv := &csvparser.CSV{Header: []string{"Value"}}
+	for _, v := range values {
+		csv.Records = append(csv.Records, []string{v})
+	}
+
+	if err := Sort(csv, config); err != nil {
+		t.Fatalf("Sort() error = %v", err)
+	}
+
+	result := make([]string, len(csv.Records))
+	for i, record := range csv.Records {
+		result[i] = record[0]
+	}
+	return result
+}
+
+func TestSortTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		key    SortKey
+		empty  EmptyPlacement
+		want   []string
+	}{
+		{
+			name:   "numeric",
+			values: []string{"10", "9", "100", "-1.5"},
+			key:    SortKey{ColumnName: "Value", Type: SortNumeric},
+			want:   []string{"-1.5", "9", "10", "100"},
+		},
+		{
+			name:   "numeric with unparseable last",
+			values: []string{"n/a", "2", "", "1"},
+			key:    SortKey{ColumnName: "Value", Type: SortNumeric},
+			want:   []string{"1", "2", "n/a", ""},
+		},
+		{
+			name:   "numeric descending with empty first",
+			values: []string{"1", "", "3", "2"},
+			key:    SortKey{ColumnName: "Value", Type: SortNumeric, Descending: true},
+			empty:  EmptyFirst,
+			want:   []string{"", "3", "2", "1"},
+		},
+		{
+			name:   "case insensitive",
+			values: []string{"banana", "Apple", "cherry"},
+			key:    SortKey{ColumnName: "Value", Type: SortCaseInsensitive},
+			want:   []string{"Apple", "banana", "cherry"},
+		},
+		{
+			name:   "natural",
+			values: []string{"file10", "file2", "1.10.0", "1.9.3", "file02b"},
+			key:    SortKey{ColumnName: "Value", Type: SortNatural},
+			want:   []string{"1.9.3", "1.10.0", "file2", "file02b", "file10"},
+		},
+		{
+			name:   "iso date",
+			values: []string{"2024-03-01", "2023-12-31T23:59:59Z", "2024-01-15 08:00:00"},
+			key:    SortKey{ColumnName: "Value", Type: SortDate},
+			want:   []string{"2023-12-31T23:59:59Z", "2024-01-15 08:00:00", "2024-03-01"},
+		},
+		{
+			name:   "custom date layout",
+			values: []string{"01/02/2024", "15/01/2024", "31/12/2023"},
+			key:    SortKey{ColumnName: "Value", Type: SortDate, Layout: "02/01/2006"},
+			want:   []string{"31/12/2023", "15/01/2024", "01/02/2024"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sortedColumn(t, tt.values, SortConfig{Keys: []SortKey{tt.key}, Empty: tt.empty})
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Sort() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSortMultipleKeys(t *testing.T) {
+	csv := &csvparser.CSV{
+		Header: []string{"Region", "Revenue"},
+		Records: [][]string{
+			{"US", "5"},
+			{"EU", "9"},
+			{"US", "50"},
+			{"EU", "10"},
+		},
+	}
+
+	config := SortConfig{Keys: []SortKey{
+		{ColumnName: "Region"},
+		{ColumnName: "Revenue", Type: SortNumeric, Descending: true},
+	}}
+	if err := Sort(csv, config); err != nil {
+		t.Fatalf("Sort() error = %v", err)
+	}
+
+	expected := [][]string{{"EU", "10"}, {"EU", "9"}, {"US", "50"}, {"US", "5"}}
+	for i, want := range expected {
+		if csv.Records[i][0] != want[0] || csv.Records[i][1] != want[1] {
+			t.Errorf("Records[%d] = %v, want %v", i, csv.Records[i], want)
+		}
+	}
+}
+
+func TestSortIndices(t *testing.T) {
+	records := [][]string{{"b", "10"}, {"a", ""}, {"c", "9"}, {"d", "-1"}, {"e", "9"}}
+
+	tests := []struct {
+		key  SortKey
+		want string
+	}{
+		{SortKey{Type: SortNumeric}, "d,c,e,b,a"},
+		{SortKey{Type: SortNumeric, Descending: true}, "b,c,e,d,a"},
+		{SortKey{Type: SortNumeric, Empty: EmptyFirst}, "a,d,c,e,b"},
+		{SortKey{}, "d,b,c,e,a"},
+	}
+
+	for _, tt := range tests {
+		indices := []int{4, 3, 2, 1, 0}
+		SortIndices(records, indices, 1, tt.key)
+		var got []string
+		for _, index := range indices {
+			got = append(got, records[index][0])
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("SortIndices(%+v) order = %v, want %s", tt.key, got, tt.want)
+		}
+	}
+}
@@ -62,14 +62,20 @@ func (s *StringNotEqualsStrategy) Name() string {
 	return "not-equals"
 }
 
-type RegexStrategy struct{}
+type RegexStrategy struct {
+	pattern string
+	re      *regexp.Regexp
+}
 
 func (s *RegexStrategy) Match(value string, pattern string) (bool, error) {
-	matched, err := regexp.MatchString(pattern, value)
-	if err != nil {
-		return false, fmt.Errorf("invalid regex pattern: %w", err)
+	if s.re == nil || s.pattern != pattern {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid regex pattern: %w", err)
+		}
+		s.pattern, s.re = pattern, re
 	}
-	return matched, nil
+	return s.re.MatchString(value), nil
 }
 
 func (s *RegexStrategy) Name() string {
@@ -0,0 +1,153 @@
+package csveditor
+
+import (
+	"fmt"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+func FilterStream(r *csvparser.Reader, w *csvparser.Writer, columnName string, pattern string, strategy FilterStrategy) (int, error) {
+	columnIndex, err := r.GetColumnIndex(columnName)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.WriteHeader(r.Header()); err != nil {
+		return 0, err
+	}
+
+	matched := 0
+	err = r.ForEach(func(record []string) error {
+		if len(record) <= columnIndex {
+			return nil
+		}
+
+		match, err := strategy.Match(record[columnIndex], pattern)
+		if err != nil {
+			return fmt.Errorf("filter error on row: %w", err)
+		}
+
+		if match {
+			matched++
+			return w.Write(record)
+		}
+		return nil
+	})
+	return matched, err
+}
+
+func SelectColumnsStream(r *csvparser.Reader, w *csvparser.Writer, columnNames []string) error {
+	indices, err := columnIndices(r.Header(), columnNames)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteHeader(pick(r.Header(), indices)); err != nil {
+		return err
+	}
+
+	return r.ForEach(func(record []string) error {
+		return w.Write(pick(record, indices))
+	})
+}
+
+func TransformColumnStream(r *csvparser.Reader, w *csvparser.Writer, columnName string, transform TransformFunc) error {
+	columnIndex, err := r.GetColumnIndex(columnName)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteHeader(r.Header()); err != nil {
+		return err
+	}
+
+	return r.ForEach(func(record []string) error {
+		if columnIndex < len(record) {
+			record[columnIndex] = transform(record[columnIndex])
+		}
+		return w.Write(record)
+	})
+}
+
+func TransformAllStream(r *csvparser.Reader, w *csvparser.Writer, transform TransformFunc) error {
+	if err := w.WriteHeader(r.Header()); err != nil {
+		return err
+	}
+
+	return r.ForEach(func(record []string) error {
+		for j := range record {
+			record[j] = transform(record[j])
+		}
+		return w.Write(record)
+	})
+}
+
+func RenameHeaderStream(r *csvparser.Reader, w *csvparser.Writer, oldName, newName string) error {
+	header, err := renamedHeader(r.Header(), oldName, newName)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteHeader(header); err != nil {
+		return err
+	}
+
+	return r.ForEach(w.Write)
+}
+
+func Copy(r *csvparser.Reader, w *csvparser.Writer) error {
+	if err := w.WriteHeader(r.Header()); err != nil {
+		return err
+	}
+
+	return r.ForEach(w.Write)
+}
+
+func columnIndex(header []string, columnName string) (int, error) {
+	for i, name := range header {
+		if name == columnName {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("column %q not found", columnName)
+}
+
+func columnIndices(header []string, columnNames []string) ([]int, error) {
+	indices := make([]int, len(columnNames))
+	for i, name := range columnNames {
+		index, err := columnIndex(header, name)
+		if err != nil {
+			return nil, err
+		}
+		indices[i] = index
+	}
+	return indices, nil
+}
+
+func pick(record []string, indices []int) []string {
+	selected := make([]string, len(indices))
+	for j, index := range indices {
+		if index < len(record) {
+			selected[j] = record[index]
+		}
+	}
+	return selected
+}
+
+func renamedHeader(header []string, oldName, newName string) ([]string, error) {
+	index, err := columnIndex(header, oldName)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, name := range header {
+		if i != index && name == newName {
+			return nil, fmt.Errorf("column %q already exists", newName)
+		}
+	}
+
+	renamed := make([]string, len(header))
+	copy(renamed, header)
+	renamed[index] = newName
+	return renamed, nil
+}
@@ -0,0 +1,78 @@
+package csveditor
+
+import (
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+const streamInput = "Name,Age,City\nJohn,30,New York\nJane,25,New York\nBob,35,Chicago\n"
+
+func runStream(t *testing.T, fn func(r *csvparser.Reader, w *csvparser.Writer) error) string {
+	t.Helper()
+
+	r, err := csvparser.NewReader(strings.NewReader(streamInput), nil)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	var buf strings.Builder
+	w := csvparser.NewWriter(&buf, nil)
+	if err := fn(r, w); err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestFilterStream(t *testing.T) {
+	var matched int
+	got := runStream(t, func(r *csvparser.Reader, w *csvparser.Writer) error {
+		var err error
+		matched, err = FilterStream(r, w, "Age", "28", NewFilterStrategy(">"))
+		return err
+	})
+
+	expected := "Name,Age,City\nJohn,30,New York\nBob,35,Chicago\n"
+	if got != expected {
+		t.Errorf("FilterStream() output = %q, want %q", got, expected)
+	}
+	if matched != 2 {
+		t.Errorf("FilterStream() matched = %d, want 2", matched)
+	}
+}
+
+func TestSelectColumnsStream(t *testing.T) {
+	got := runStream(t, func(r *csvparser.Reader, w *csvparser.Writer) error {
+		return SelectColumnsStream(r, w, []string{"City", "Name"})
+	})
+
+	expected := "City,Name\nNew York,John\nNew York,Jane\nChicago,Bob\n"
+	if got != expected {
+		t.Errorf("SelectColumnsStream() output = %q, want %q", got, expected)
+	}
+}
+
+func TestTransformColumnStream(t *testing.T) {
+	got := runStream(t, func(r *csvparser.Reader, w *csvparser.Writer) error {
+		return TransformColumnStream(r, w, "City", ToUpper)
+	})
+
+	expected := "Name,Age,City\nJohn,30,NEW YORK\nJane,25,NEW YORK\nBob,35,CHICAGO\n"
+	if got != expected {
+		t.Errorf("TransformColumnStream() output = %q, want %q", got, expected)
+	}
+}
+
+func TestRenameHeaderStream(t *testing.T) {
+	got := runStream(t, func(r *csvparser.Reader, w *csvparser.Writer) error {
+		return RenameHeaderStream(r, w, "City", "Location")
+	})
+
+	if !strings.HasPrefix(got, "Name,Age,Location\n") {
+		t.Errorf("RenameHeaderStream() header = %q, want Name,Age,Location", strings.SplitN(got, "\n", 2)[0])
+	}
+}
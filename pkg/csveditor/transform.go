@@ -63,18 +63,36 @@ func TrimSpace(s string) string {
 	return strings.TrimSpace(s)
 }
 
+func NamedTransform(name string, args ...string) (TransformFunc, error) {
+	want := 0
+	var transform TransformFunc
+	switch name {
+	case "lower":
+		transform = ToLower
+	case "upper":
+		transform = ToUpper
+	case "trim":
+		transform = TrimSpace
+	case "replace":
+		want = 2
+		if len(args) == want {
+			transform = ReplaceAll(args[0], args[1])
+		}
+	default:
+		return nil, fmt.Errorf("unknown transform %q", name)
+	}
+	if len(args) != want {
+		return nil, fmt.Errorf("transform %q takes %d arguments, got %d", name, want, len(args))
+	}
+	return transform, nil
+}
+
 func RenameHeader(csv *csvparser.CSV, oldName, newName string) error {
-	index, err := csv.GetColumnIndex(oldName)
+	header, err := renamedHeader(csv.Header, oldName, newName)
 	if err != nil {
 		return err
 	}
 
-	for i, name := range csv.Header {
-		if i != index && name == newName {
-			return fmt.Errorf("column %q already exists", newName)
-		}
-	}
-
-	csv.Header[index] = newName
+	csv.Header = header
 	return nil
 }
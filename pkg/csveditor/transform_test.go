@@ -111,3 +111,34 @@ func TestRenameHeaderErrors(t *testing.T) {
 		t.Error("RenameHeader() expected error for non-existent column, got nil")
 	}
 }
+
+func TestNamedTransform(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"lower", nil, " new york ", false},
+		{"upper", nil, " NEW YORK ", false},
+		{"trim", nil, "New York", false},
+		{"replace", []string{"New", "Old"}, " Old York ", false},
+		{"replace", []string{"New"}, "", true},
+		{"upper", []string{"x"}, "", true},
+		{"reverse", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transform, err := NamedTransform(tt.name, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NamedTransform() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if got := transform(" New York "); got != tt.want {
+					t.Errorf("transform() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
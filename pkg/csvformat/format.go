@@ -0,0 +1,115 @@
+package csvformat
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+)
+
+type Options struct {
+	Delimiter rune
+	Table     string
+	Dialect   Dialect
+	BatchSize int
+	Sheet     string
+	Encoding  string
+	BOM       bool
+	Types     map[string]csvstats.ValueType
+	Layouts   map[string]string
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Delimiter: ',',
+		Table:     "data",
+		Dialect:   SQLite,
+		BatchSize: 100,
+	}
+}
+
+type Factory func(w io.Writer, options Options) csvparser.Encoder
+
+var (
+	registry = make(map[string]Factory)
+	aliases  = make(map[string]string)
+	binary   = make(map[string]bool)
+)
+
+func Register(name string, factory Factory, alias ...string) {
+	registry[name] = factory
+	for _, a := range alias {
+		aliases[a] = name
+	}
+}
+
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func canonicalName(name string) string {
+	name = strings.ToLower(name)
+	if canonical, ok := aliases[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+func Lookup(name string) (Factory, error) {
+	name = canonicalName(name)
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory, nil
+}
+
+func IsBinary(name string) bool {
+	return binary[canonicalName(name)]
+}
+
+func NewWriter(format string, w io.Writer, options Options) (*csvparser.Writer, error) {
+	factory, err := Lookup(format)
+	if err != nil {
+		return nil, err
+	}
+	return csvparser.NewEncoderWriter(factory(w, options), nil), nil
+}
+
+func CreateFileOrStdout(format, filename string, options Options) (*csvparser.Writer, error) {
+	factory, err := Lookup(format)
+	if err != nil {
+		return nil, err
+	}
+
+	if IsBinary(format) {
+		options.Encoding, options.BOM = "", false
+	}
+
+	out, closer, err := csvparser.CreateOutput(filename, options.Encoding, options.BOM)
+	if err != nil {
+		return nil, err
+	}
+	return csvparser.NewEncoderWriter(factory(out, options), closer), nil
+}
+
+func init() {
+	Register("csv", func(w io.Writer, options Options) csvparser.Encoder {
+		delimiter := options.Delimiter
+		if delimiter == 0 {
+			delimiter = ','
+		}
+		return csvparser.NewDelimitedEncoder(w, &csvparser.Config{Delimiter: delimiter})
+	})
+	Register("tsv", func(w io.Writer, options Options) csvparser.Encoder {
+		return csvparser.NewDelimitedEncoder(w, &csvparser.Config{Delimiter: '\t'})
+	})
+}
@@ -0,0 +1,96 @@
+package csvformat
+
+import (
+	"bytes"
+	"testing"
+)
+
+func render(t *testing.T, format string, options Options, header []string, records ...[]string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, options)
+	if err != nil {
+		t.Fatalf("NewWriter(%q) error = %v", format, err)
+	}
+	if err := w.WriteHeader(header); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"csv", "tsv", "markdown", "md", "HTML", "latex", "tex", "sql"} {
+		if _, err := Lookup(name); err != nil {
+			t.Errorf("Lookup(%q) error = %v", name, err)
+		}
+	}
+	if _, err := Lookup("xml"); err == nil {
+		t.Error("Lookup(\"xml\") expected error")
+	}
+}
+
+func TestDelimited(t *testing.T) {
+	header := []string{"a", "b"}
+	record := []string{"1", "x,y"}
+
+	if got, want := render(t, "csv", DefaultOptions(), header, record), "a,b\n1,\"x,y\"\n"; got != want {
+		t.Errorf("csv = %q, want %q", got, want)
+	}
+	if got, want := render(t, "tsv", DefaultOptions(), header, record), "a\tb\n1\tx,y\n"; got != want {
+		t.Errorf("tsv = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	got := render(t, "markdown", DefaultOptions(),
+		[]string{"name", "qty"},
+		[]string{"a|b", "5"},
+		[]string{"line\nbreak", "12"},
+	)
+	want := "" +
+		"| name          | qty |\n" +
+		"| ------------- | --: |\n" +
+		"| a\\|b          |   5 |\n" +
+		"| line<br>break |  12 |\n"
+	if got != want {
+		t.Errorf("markdown =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	got := render(t, "html", DefaultOptions(), []string{"a"}, []string{"<b>&"})
+	want := "<table>\n" +
+		"  <thead>\n" +
+		"    <tr><th>a</th></tr>\n" +
+		"  </thead>\n" +
+		"  <tbody>\n" +
+		"    <tr><td>&lt;b&gt;&amp;</td></tr>\n" +
+		"  </tbody>\n" +
+		"</table>\n"
+	if got != want {
+		t.Errorf("html =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLaTeX(t *testing.T) {
+	got := render(t, "latex", DefaultOptions(), []string{"item", "cost"}, []string{"a_b & c", "$5 50%"})
+	want := "\\begin{tabular}{ll}\n" +
+		"\\hline\n" +
+		"item & cost \\\\\n" +
+		"\\hline\n" +
+		"a\\_b \\& c & \\$5 50\\% \\\\\n" +
+		"\\hline\n" +
+		"\\end{tabular}\n"
+	if got != want {
+		t.Errorf("latex =\n%s\nwant\n%s", got, want)
+	}
+}
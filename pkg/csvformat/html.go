@@ -0,0 +1,61 @@
+package csvformat
+
+import (
+	"bufio"
+	"html"
+	"io"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type htmlEncoder struct {
+	w       *bufio.Writer
+	started bool
+}
+
+func newHTMLEncoder(w io.Writer, options Options) csvparser.Encoder {
+	return &htmlEncoder{w: bufio.NewWriter(w)}
+}
+
+func (e *htmlEncoder) WriteHeader(header []string) error {
+	e.w.WriteString("<table>\n  <thead>\n")
+	e.writeRow("th", header)
+	_, err := e.w.WriteString("  </thead>\n  <tbody>\n")
+	e.started = true
+	return err
+}
+
+func (e *htmlEncoder) Write(record []string) error {
+	if !e.started {
+		e.w.WriteString("<table>\n  <tbody>\n")
+		e.started = true
+	}
+	return e.writeRow("td", record)
+}
+
+func (e *htmlEncoder) writeRow(tag string, row []string) error {
+	e.w.WriteString("    <tr>")
+	for _, value := range row {
+		e.w.WriteString("<" + tag + ">")
+		e.w.WriteString(html.EscapeString(value))
+		e.w.WriteString("</" + tag + ">")
+	}
+	_, err := e.w.WriteString("</tr>\n")
+	return err
+}
+
+func (e *htmlEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *htmlEncoder) Close() error {
+	if !e.started {
+		e.w.WriteString("<table>\n  <tbody>\n")
+	}
+	e.w.WriteString("  </tbody>\n</table>\n")
+	return e.w.Flush()
+}
+
+func init() {
+	Register("html", newHTMLEncoder)
+}
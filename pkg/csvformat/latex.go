@@ -0,0 +1,77 @@
+package csvformat
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type latexEncoder struct {
+	w       *bufio.Writer
+	started bool
+}
+
+func newLaTeXEncoder(w io.Writer, options Options) csvparser.Encoder {
+	return &latexEncoder{w: bufio.NewWriter(w)}
+}
+
+func (e *latexEncoder) begin(columns int) {
+	e.w.WriteString("\\begin{tabular}{" + strings.Repeat("l", columns) + "}\n\\hline\n")
+	e.started = true
+}
+
+func (e *latexEncoder) WriteHeader(header []string) error {
+	e.begin(len(header))
+	e.writeRow(header)
+	_, err := e.w.WriteString("\\hline\n")
+	return err
+}
+
+func (e *latexEncoder) Write(record []string) error {
+	if !e.started {
+		e.begin(len(record))
+	}
+	return e.writeRow(record)
+}
+
+func (e *latexEncoder) writeRow(row []string) error {
+	escaped := make([]string, len(row))
+	for i, value := range row {
+		escaped[i] = latexReplacer.Replace(value)
+	}
+	_, err := e.w.WriteString(strings.Join(escaped, " & ") + " \\\\\n")
+	return err
+}
+
+func (e *latexEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *latexEncoder) Close() error {
+	if !e.started {
+		return e.w.Flush()
+	}
+	e.w.WriteString("\\hline\n\\end{tabular}\n")
+	return e.w.Flush()
+}
+
+var latexReplacer = strings.NewReplacer(
+	"\\", "\\textbackslash{}",
+	"&", "\\&",
+	"%", "\\%",
+	"$", "\\$",
+	"#", "\\#",
+	"_", "\\_",
+	"{", "\\{",
+	"}", "\\}",
+	"~", "\\textasciitilde{}",
+	"^", "\\textasciicircum{}",
+	"\r\n", " ",
+	"\n", " ",
+)
+
+func init() {
+	Register("latex", newLaTeXEncoder, "tex")
+}
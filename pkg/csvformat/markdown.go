@@ -0,0 +1,129 @@
+package csvformat
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+
+	"github.com/mattn/go-runewidth"
+)
+
+type markdownEncoder struct {
+	w         io.Writer
+	header    []string
+	records   [][]string
+	inferrers []csvstats.TypeInferrer
+}
+
+func newMarkdownEncoder(w io.Writer, options Options) csvparser.Encoder {
+	return &markdownEncoder{w: w}
+}
+
+func (e *markdownEncoder) WriteHeader(header []string) error {
+	e.header = escapeMarkdownRow(header)
+	return nil
+}
+
+func (e *markdownEncoder) Write(record []string) error {
+	for i, value := range record {
+		if i >= len(e.inferrers) {
+			e.inferrers = append(e.inferrers, csvstats.TypeInferrer{})
+		}
+		e.inferrers[i].Add(value)
+	}
+	e.records = append(e.records, escapeMarkdownRow(record))
+	return nil
+}
+
+func (e *markdownEncoder) Flush() error {
+	return nil
+}
+
+func (e *markdownEncoder) Close() error {
+	columns := len(e.header)
+	for _, record := range e.records {
+		columns = max(columns, len(record))
+	}
+	if columns == 0 {
+		return nil
+	}
+
+	header := e.header
+	if header == nil {
+		header = make([]string, columns)
+	}
+
+	widths := make([]int, columns)
+	for i := range widths {
+		widths[i] = 3
+	}
+	for _, row := range append([][]string{header}, e.records...) {
+		for i, value := range row {
+			widths[i] = max(widths[i], runewidth.StringWidth(value))
+		}
+	}
+
+	numeric := make([]bool, columns)
+	for i, ti := range e.inferrers {
+		numeric[i] = ti.Type == csvstats.TypeInteger || ti.Type == csvstats.TypeNumber
+	}
+
+	bw := bufio.NewWriter(e.w)
+	writeMarkdownRow(bw, header, widths, numeric)
+
+	bw.WriteString("|")
+	for i, width := range widths {
+		if numeric[i] {
+			bw.WriteString(" " + strings.Repeat("-", width-1) + ": |")
+		} else {
+			bw.WriteString(" " + strings.Repeat("-", width) + " |")
+		}
+	}
+	bw.WriteString("\n")
+
+	for _, record := range e.records {
+		writeMarkdownRow(bw, record, widths, numeric)
+	}
+	return bw.Flush()
+}
+
+func writeMarkdownRow(bw *bufio.Writer, row []string, widths []int, numeric []bool) {
+	bw.WriteString("|")
+	for i, width := range widths {
+		value := ""
+		if i < len(row) {
+			value = row[i]
+		}
+		padding := strings.Repeat(" ", width-runewidth.StringWidth(value))
+		if numeric[i] {
+			bw.WriteString(" " + padding + value + " |")
+		} else {
+			bw.WriteString(" " + value + padding + " |")
+		}
+	}
+	bw.WriteString("\n")
+}
+
+var markdownReplacer = strings.NewReplacer(
+	"\\", "\\\\",
+	"|", "\\|",
+	"\r\n", "<br>",
+	"\n", "<br>",
+	"\r", "<br>",
+)
+
+func escapeMarkdownRow(row []string) []string {
+	escaped := slices.Clone(row)
+	for i, value := range escaped {
+		escaped[i] = markdownReplacer.Replace(value)
+	}
+	return escaped
+}
+
+func init() {
+	Register("markdown", newMarkdownEncoder, "md")
+}
@@ -0,0 +1,271 @@
+package csvformat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+)
+
+type Dialect int
+
+const (
+	SQLite Dialect = iota
+	Postgres
+	MySQL
+)
+
+func ParseDialect(name string) (Dialect, error) {
+	switch strings.ToLower(name) {
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	case "postgres", "postgresql", "pg":
+		return Postgres, nil
+	case "mysql", "mariadb":
+		return MySQL, nil
+	}
+	return SQLite, fmt.Errorf("unknown SQL dialect %q (use sqlite, postgres or mysql)", name)
+}
+
+func (d Dialect) String() string {
+	switch d {
+	case Postgres:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	default:
+		return "sqlite"
+	}
+}
+
+func (d Dialect) QuoteIdentifier(name string) string {
+	if d == MySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (d Dialect) QuoteString(value string) string {
+	value = strings.ReplaceAll(value, "'", "''")
+	if d == MySQL {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+	}
+	return "'" + value + "'"
+}
+
+func (d Dialect) ColumnType(t csvstats.ValueType) string {
+	switch t {
+	case csvstats.TypeBoolean:
+		return "BOOLEAN"
+	case csvstats.TypeInteger:
+		if d == SQLite {
+			return "INTEGER"
+		}
+		return "BIGINT"
+	case csvstats.TypeNumber:
+		switch d {
+		case Postgres:
+			return "DOUBLE PRECISION"
+		case MySQL:
+			return "DOUBLE"
+		}
+		return "REAL"
+	case csvstats.TypeDate:
+		return "DATE"
+	case csvstats.TypeDateTime:
+		switch d {
+		case Postgres:
+			return "TIMESTAMP"
+		case MySQL:
+			return "DATETIME"
+		}
+		return "TEXT"
+	default:
+		return "TEXT"
+	}
+}
+
+func (d Dialect) Literal(value string, t csvstats.ValueType, layout string) string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return "NULL"
+	}
+
+	switch t {
+	case csvstats.TypeBoolean:
+		if b, ok := csvstats.ParseBool(trimmed); ok {
+			return strings.ToUpper(strconv.FormatBool(b))
+		}
+	case csvstats.TypeInteger:
+		if n, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+	case csvstats.TypeNumber:
+		if f, err := strconv.ParseFloat(trimmed, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	case csvstats.TypeDate:
+		if date, err := time.Parse(layout, trimmed); err == nil && layout != "" {
+			return d.QuoteString(date.Format("2006-01-02"))
+		}
+	case csvstats.TypeDateTime:
+		if date, err := time.Parse(layout, trimmed); err == nil && layout != "" {
+			return d.QuoteString(date.UTC().Format("2006-01-02 15:04:05.999999"))
+		}
+	}
+	return d.QuoteString(value)
+}
+
+type sqlEncoder struct {
+	w         io.Writer
+	options   Options
+	header    []string
+	records   [][]string
+	inferrers []csvstats.TypeInferrer
+}
+
+func newSQLEncoder(w io.Writer, options Options) csvparser.Encoder {
+	if options.Table == "" {
+		options.Table = "data"
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultOptions().BatchSize
+	}
+	return &sqlEncoder{w: w, options: options}
+}
+
+func (e *sqlEncoder) WriteHeader(header []string) error {
+	e.header = slices.Clone(header)
+	return nil
+}
+
+func (e *sqlEncoder) Write(record []string) error {
+	for i, value := range record {
+		if i >= len(e.inferrers) {
+			e.inferrers = append(e.inferrers, csvstats.TypeInferrer{})
+		}
+		e.inferrers[i].Add(value)
+	}
+	e.records = append(e.records, slices.Clone(record))
+	return nil
+}
+
+func (e *sqlEncoder) Flush() error {
+	return nil
+}
+
+func (e *sqlEncoder) Close() error {
+	columns := sqlColumns(e.header, len(e.inferrers))
+	if len(columns) == 0 {
+		return nil
+	}
+
+	types := make([]csvstats.ValueType, len(columns))
+	layouts := make([]string, len(columns))
+	for i := range types {
+		types[i] = csvstats.TypeString
+		if t, ok := e.options.Types[columns[i]]; ok {
+			types[i] = t
+		} else if i < len(e.inferrers) {
+			types[i] = e.inferrers[i].Type
+		}
+		if types[i] == csvstats.TypeDate || types[i] == csvstats.TypeDateTime {
+			if layouts[i] = e.layout(i, columns[i]); layouts[i] == "" {
+				types[i] = csvstats.TypeString
+			}
+		}
+	}
+
+	d := e.options.Dialect
+	table := d.QuoteIdentifier(e.options.Table)
+	quoted := make([]string, len(columns))
+	for i, name := range columns {
+		quoted[i] = d.QuoteIdentifier(name)
+	}
+
+	bw := bufio.NewWriter(e.w)
+	fmt.Fprintf(bw, "CREATE TABLE IF NOT EXISTS %s (\n", table)
+	for i, name := range quoted {
+		fmt.Fprintf(bw, "  %s %s", name, d.ColumnType(types[i]))
+		if i < len(quoted)-1 {
+			bw.WriteString(",")
+		}
+		bw.WriteString("\n")
+	}
+	bw.WriteString(");\n")
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", table, strings.Join(quoted, ", "))
+	for start := 0; start < len(e.records); start += e.options.BatchSize {
+		batch := e.records[start:min(start+e.options.BatchSize, len(e.records))]
+		bw.WriteString(insert)
+		for i, record := range batch {
+			values := make([]string, len(columns))
+			for j := range columns {
+				value := ""
+				if j < len(record) {
+					value = record[j]
+				}
+				values[j] = d.Literal(value, types[j], layouts[j])
+			}
+			bw.WriteString("  (" + strings.Join(values, ", ") + ")")
+			if i < len(batch)-1 {
+				bw.WriteString(",\n")
+			} else {
+				bw.WriteString(";\n")
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+var swappedLayouts = map[string]string{
+	"02/01/2006": "01/02/2006",
+	"01/02/2006": "02/01/2006",
+}
+
+func (e *sqlEncoder) layout(col int, name string) string {
+	if layout, ok := e.options.Layouts[name]; ok {
+		return layout
+	}
+	if col >= len(e.inferrers) {
+		return ""
+	}
+	layout := e.inferrers[col].Layout
+	swapped, ok := swappedLayouts[layout]
+	if !ok {
+		return layout
+	}
+	for _, record := range e.records {
+		if col >= len(record) || strings.TrimSpace(record[col]) == "" {
+			continue
+		}
+		if _, err := time.Parse(swapped, strings.TrimSpace(record[col])); err != nil {
+			return layout
+		}
+	}
+	return ""
+}
+
+func sqlColumns(header []string, columns int) []string {
+	names := slices.Clone(header)
+	for i := len(names); i < columns; i++ {
+		names = append(names, fmt.Sprintf("column%d", i+1))
+	}
+	for i, name := range names {
+		if strings.TrimSpace(name) == "" {
+			names[i] = fmt.Sprintf("column%d", i+1)
+		}
+	}
+	return names
+}
+
+func init() {
+	Register("sql", newSQLEncoder)
+}
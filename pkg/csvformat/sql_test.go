@@ -0,0 +1,148 @@
+package csvformat
+
+import (
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+)
+
+func TestSQL(t *testing.T) {
+	header := []string{"id", "name", "price", "active", "added"}
+	records := [][]string{
+		{"1", "O'Brien", "2.50", "yes", "31/01/2024"},
+		{"2", `back\slash`, "", "no", "01/02/2024"},
+		{"3", "", "10", "true", ""},
+	}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		want    string
+	}{
+		{
+			name:    "sqlite",
+			dialect: SQLite,
+			want: `CREATE TABLE IF NOT EXISTS "items" (
+  "id" INTEGER,
+  "name" TEXT,
+  "price" REAL,
+  "active" BOOLEAN,
+  "added" DATE
+);
+INSERT INTO "items" ("id", "name", "price", "active", "added") VALUES
+  (1, 'O''Brien', 2.5, TRUE, '2024-01-31'),
+  (2, 'back\slash', NULL, FALSE, '2024-02-01');
+INSERT INTO "items" ("id", "name", "price", "active", "added") VALUES
+  (3, NULL, 10, TRUE, NULL);
+`,
+		},
+		{
+			name:    "mysql",
+			dialect: MySQL,
+			want: "CREATE TABLE IF NOT EXISTS `items` (\n" +
+				"  `id` BIGINT,\n" +
+				"  `name` TEXT,\n" +
+				"  `price` DOUBLE,\n" +
+				"  `active` BOOLEAN,\n" +
+				"  `added` DATE\n" +
+				");\n" +
+				"INSERT INTO `items` (`id`, `name`, `price`, `active`, `added`) VALUES\n" +
+				"  (1, 'O''Brien', 2.5, TRUE, '2024-01-31'),\n" +
+				"  (2, 'back\\\\slash', NULL, FALSE, '2024-02-01');\n" +
+				"INSERT INTO `items` (`id`, `name`, `price`, `active`, `added`) VALUES\n" +
+				"  (3, NULL, 10, TRUE, NULL);\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultOptions()
+			options.Table = "items"
+			options.Dialect = tt.dialect
+			options.BatchSize = 2
+
+			got := render(t, "sql", options, header, records...)
+			if got != tt.want {
+				t.Errorf("sql =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLPostgresTypes(t *testing.T) {
+	options := DefaultOptions()
+	options.Dialect = Postgres
+
+	got := render(t, "sql", options,
+		[]string{"n", "x", "ts"},
+		[]string{"1", "1.5", "2024-01-31T10:00:00+02:00"},
+	)
+	for _, want := range []string{
+		`"n" BIGINT`,
+		`"x" DOUBLE PRECISION`,
+		`"ts" TIMESTAMP`,
+		`(1, 1.5, '2024-01-31 08:00:00')`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("sql missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestSQLDateLayouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		layouts map[string]string
+		values  []string
+		want    []string
+	}{
+		{"day first", nil, []string{"31/01/2024", "01/02/2024"}, []string{`"d" DATE`, `('2024-01-31')`, `('2024-02-01')`}},
+		{"month first", nil, []string{"01/31/2024", "02/13/2024"}, []string{`"d" DATE`, `('2024-01-31')`, `('2024-02-13')`}},
+		{"ambiguous", nil, []string{"03/01/2024", "01/02/2024"}, []string{`"d" TEXT`, `('03/01/2024')`, `('01/02/2024')`}},
+		{"mixed", nil, []string{"31/01/2024", "2024-02-01"}, []string{`"d" TEXT`, `('31/01/2024')`, `('2024-02-01')`}},
+		{"schema layout", map[string]string{"d": "01/02/2006"}, []string{"03/01/2024", "01/02/2024"}, []string{`"d" DATE`, `('2024-03-01')`, `('2024-01-02')`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultOptions()
+			options.Layouts = tt.layouts
+			var records [][]string
+			for _, v := range tt.values {
+				records = append(records, []string{v})
+			}
+			got := render(t, "sql", options, []string{"d"}, records...)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("sql missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLColumnTypes(t *testing.T) {
+	options := DefaultOptions()
+	options.Types = map[string]csvstats.ValueType{"code": csvstats.TypeString}
+
+	got := render(t, "sql", options, []string{"code", "n"}, []string{"1", "2"})
+	for _, want := range []string{`"code" TEXT`, `"n" INTEGER`, `('1', 2)`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("sql missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	tests := map[string]Dialect{"sqlite": SQLite, "PostgreSQL": Postgres, "pg": Postgres, "mysql": MySQL}
+	for name, want := range tests {
+		got, err := ParseDialect(name)
+		if err != nil || got != want {
+			t.Errorf("ParseDialect(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseDialect("oracle"); err == nil {
+		t.Error("ParseDialect(\"oracle\") expected error")
+	}
+}
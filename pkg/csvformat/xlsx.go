@@ -0,0 +1,200 @@
+package csvformat
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>
+<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>
+</styleSheet>`
+
+	xlsxSheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+	xlsxSheetFooter = `</sheetData></worksheet>`
+)
+
+type xlsxEncoder struct {
+	zw      *zip.Writer
+	sheet   *bufio.Writer
+	options Options
+	row     int
+	err     error
+}
+
+func newXLSXEncoder(w io.Writer, options Options) csvparser.Encoder {
+	return &xlsxEncoder{zw: zip.NewWriter(w), options: options}
+}
+
+func (e *xlsxEncoder) start() error {
+	if e.sheet != nil || e.err != nil {
+		return e.err
+	}
+
+	sheetName := e.options.Sheet
+	if sheetName == "" {
+		sheetName = "Sheet1"
+	}
+
+	var workbook strings.Builder
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`)
+	xml.EscapeText(&workbook, []byte(sheetName))
+	workbook.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		f, err := e.zw.Create(part.name)
+		if err == nil {
+			_, err = io.WriteString(f, part.content)
+		}
+		if err != nil {
+			e.err = err
+			return err
+		}
+	}
+
+	f, err := e.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		e.err = err
+		return err
+	}
+	e.sheet = bufio.NewWriter(f)
+	_, e.err = e.sheet.WriteString(xlsxSheetHeader)
+	return e.err
+}
+
+func (e *xlsxEncoder) WriteHeader(header []string) error {
+	return e.writeRow(header, true)
+}
+
+func (e *xlsxEncoder) Write(record []string) error {
+	return e.writeRow(record, false)
+}
+
+func (e *xlsxEncoder) writeRow(record []string, bold bool) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	e.row++
+	row := strconv.Itoa(e.row)
+	e.sheet.WriteString(`<row r="` + row + `">`)
+	for i, value := range record {
+		if value == "" {
+			continue
+		}
+
+		e.sheet.WriteString(`<c r="` + columnLetters(i) + row + `"`)
+		if bold {
+			e.sheet.WriteString(` s="1"`)
+		}
+
+		if number, ok := xlsxNumber(value); ok && !bold {
+			e.sheet.WriteString(`><v>` + number + `</v></c>`)
+			continue
+		}
+
+		e.sheet.WriteString(` t="inlineStr"><is><t`)
+		if strings.TrimSpace(value) != value {
+			e.sheet.WriteString(` xml:space="preserve"`)
+		}
+		e.sheet.WriteString(`>`)
+		xml.EscapeText(e.sheet, []byte(value))
+		e.sheet.WriteString(`</t></is></c>`)
+	}
+	_, e.err = e.sheet.WriteString(`</row>`)
+	return e.err
+}
+
+func (e *xlsxEncoder) Flush() error {
+	if e.sheet == nil || e.err != nil {
+		return e.err
+	}
+	return e.sheet.Flush()
+}
+
+func (e *xlsxEncoder) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	e.sheet.WriteString(xlsxSheetFooter)
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	return e.zw.Close()
+}
+
+func xlsxNumber(value string) (string, bool) {
+	if value != strings.TrimSpace(value) || !csvstats.IsNumber(value) {
+		return "", false
+	}
+
+	digits := 0
+	for _, c := range value {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+		if c == 'e' || c == 'E' {
+			break
+		}
+	}
+	if digits > 15 {
+		return "", false
+	}
+	return strings.TrimPrefix(value, "+"), true
+}
+
+func columnLetters(index int) string {
+	var letters []byte
+	for index++; index > 0; index = (index - 1) / 26 {
+		letters = append([]byte{byte('A' + (index-1)%26)}, letters...)
+	}
+	return string(letters)
+}
+
+func init() {
+	Register("xlsx", newXLSXEncoder)
+	binary["xlsx"] = true
+}
@@ -0,0 +1,75 @@
+package csvformat
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+func TestXLSXRoundTrip(t *testing.T) {
+	header := []string{"id", "name", "amount", "zip"}
+	records := [][]string{
+		{"1", "<Ann & co>", "12.50", "02134"},
+		{"2", " padded ", "", "12345678901234567890"},
+	}
+
+	options := DefaultOptions()
+	options.Sheet = "People"
+	data := render(t, "xlsx", options, header, records...)
+
+	r, err := csvparser.ReadXLSX(strings.NewReader(data), int64(len(data)), &csvparser.Config{Sheet: "People"})
+	if err != nil {
+		t.Fatalf("ReadXLSX() error = %v", err)
+	}
+	csv, err := csvparser.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(csv.Header, header) {
+		t.Errorf("Header = %v, want %v", csv.Header, header)
+	}
+	if !reflect.DeepEqual(csv.Records, records) {
+		t.Errorf("Records = %q, want %q", csv.Records, records)
+	}
+
+	for _, want := range []string{`<c r="C2"><v>12.50</v></c>`, `<c r="D2" t="inlineStr">`, `<t xml:space="preserve"> padded </t>`} {
+		if !bytes.Contains(unzipSheet(t, data), []byte(want)) {
+			t.Errorf("sheet XML missing %q", want)
+		}
+	}
+}
+
+func TestColumnLetters(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for index, want := range tests {
+		if got := columnLetters(index); got != want {
+			t.Errorf("columnLetters(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func unzipSheet(t *testing.T, data string) []byte {
+	t.Helper()
+
+	zr, err := zip.NewReader(strings.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
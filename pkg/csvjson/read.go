@@ -0,0 +1,288 @@
+package csvjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type ArrayMode int
+
+const (
+	ArrayJoin ArrayMode = iota
+	ArrayExplode
+)
+
+type ReadOptions struct {
+	Arrays    ArrayMode
+	Separator string
+}
+
+func DefaultReadOptions() ReadOptions {
+	return ReadOptions{
+		Arrays:    ArrayJoin,
+		Separator: ";",
+	}
+}
+
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+type field struct {
+	key   string
+	value string
+}
+
+func Read(reader io.Reader, options ReadOptions) (*csvparser.CSV, error) {
+	dec := json.NewDecoder(reader)
+	dec.UseNumber()
+
+	var rows [][]field
+	addValue := func(v any) {
+		rows = append(rows, flatten("", v, options)...)
+	}
+
+	first := true
+	for {
+		v, err := decodeValue(dec, first)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JSON: %w", err)
+		}
+
+		if arr, ok := v.(topLevelArray); ok && first {
+			for _, item := range arr {
+				addValue(item)
+			}
+		} else {
+			addValue(v)
+		}
+		first = false
+	}
+
+	return toCSV(rows), nil
+}
+
+type topLevelArray []any
+
+func decodeValue(dec *json.Decoder, topLevel bool) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := &object{values: make(map[string]any)}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := keyTok.(string)
+				value, err := decodeValue(dec, false)
+				if err != nil {
+					return nil, err
+				}
+				if _, exists := obj.values[key]; !exists {
+					obj.keys = append(obj.keys, key)
+				}
+				obj.values[key] = value
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return obj, nil
+		case '[':
+			var arr []any
+			for dec.More() {
+				value, err := decodeValue(dec, false)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, value)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			if topLevel {
+				return topLevelArray(arr), nil
+			}
+			return arr, nil
+		}
+		return nil, fmt.Errorf("unexpected delimiter %v", t)
+	default:
+		return tok, nil
+	}
+}
+
+func flatten(prefix string, v any, options ReadOptions) [][]field {
+	switch val := v.(type) {
+	case *object:
+		rows := [][]field{{}}
+		for _, key := range val.keys {
+			name := key
+			if prefix != "" {
+				name = prefix + "." + key
+			}
+			rows = cartesian(rows, flatten(name, val.values[key], options))
+		}
+		return rows
+	case []any:
+		if options.Arrays == ArrayExplode {
+			if len(val) == 0 {
+				return [][]field{{{key: columnName(prefix), value: ""}}}
+			}
+			var rows [][]field
+			for _, item := range val {
+				rows = append(rows, flatten(prefix, item, options)...)
+			}
+			return rows
+		}
+
+		parts := make([]string, len(val))
+		for i, item := range val {
+			if isComposite(item) {
+				return [][]field{{{key: columnName(prefix), value: encodeCompact(val)}}}
+			}
+			parts[i] = scalarString(item)
+		}
+		return [][]field{{{key: columnName(prefix), value: strings.Join(parts, options.Separator)}}}
+	default:
+		return [][]field{{{key: columnName(prefix), value: scalarString(val)}}}
+	}
+}
+
+func columnName(prefix string) string {
+	if prefix == "" {
+		return "value"
+	}
+	return prefix
+}
+
+func cartesian(left, right [][]field) [][]field {
+	if len(right) == 1 {
+		for i := range left {
+			left[i] = append(left[i], right[0]...)
+		}
+		return left
+	}
+
+	result := make([][]field, 0, len(left)*len(right))
+	for _, l := range left {
+		for _, r := range right {
+			row := make([]field, 0, len(l)+len(r))
+			row = append(row, l...)
+			row = append(row, r...)
+			result = append(result, row)
+		}
+	}
+	return result
+}
+
+func isComposite(v any) bool {
+	switch v.(type) {
+	case *object, []any:
+		return true
+	}
+	return false
+}
+
+func scalarString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		if val {
+			return "true"
+		}
+		return "false"
+	default:
+		return encodeCompact(val)
+	}
+}
+
+func encodeCompact(v any) string {
+	var buf bytes.Buffer
+	writeCompact(&buf, v)
+	return buf.String()
+}
+
+func writeCompact(buf *bytes.Buffer, v any) {
+	switch val := v.(type) {
+	case *object:
+		buf.WriteByte('{')
+		for i, key := range val.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeString(buf, key)
+			buf.WriteByte(':')
+			writeCompact(buf, val.values[key])
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range val {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCompact(buf, item)
+		}
+		buf.WriteByte(']')
+	case string:
+		writeString(buf, val)
+	case nil:
+		buf.WriteString("null")
+	default:
+		buf.WriteString(scalarString(val))
+	}
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	buf.Truncate(buf.Len() - 1)
+}
+
+func toCSV(rows [][]field) *csvparser.CSV {
+	result := &csvparser.CSV{
+		Header:  []string{},
+		Records: [][]string{},
+	}
+
+	index := make(map[string]int)
+	for _, row := range rows {
+		for _, f := range row {
+			if _, ok := index[f.key]; !ok {
+				index[f.key] = len(result.Header)
+				result.Header = append(result.Header, f.key)
+			}
+		}
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(result.Header))
+		for _, f := range row {
+			record[index[f.key]] = f.value
+		}
+		result.Records = append(result.Records, record)
+	}
+
+	return result
+}
@@ -0,0 +1,88 @@
+package csvjson
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		options     ReadOptions
+		wantHeader  []string
+		wantRecords [][]string
+	}{
+		{
+			name:        "array of objects",
+			input:       `[{"b":1,"a":"x"},{"a":"y","c":true}]`,
+			options:     DefaultReadOptions(),
+			wantHeader:  []string{"b", "a", "c"},
+			wantRecords: [][]string{{"1", "x", ""}, {"", "y", "true"}},
+		},
+		{
+			name:        "ndjson",
+			input:       "{\"id\":1}\n{\"id\":2}\n",
+			options:     DefaultReadOptions(),
+			wantHeader:  []string{"id"},
+			wantRecords: [][]string{{"1"}, {"2"}},
+		},
+		{
+			name:        "nested objects are flattened",
+			input:       `{"id":1,"user":{"name":"Ann","address":{"city":"Oslo"}},"note":null}`,
+			options:     DefaultReadOptions(),
+			wantHeader:  []string{"id", "user.name", "user.address.city", "note"},
+			wantRecords: [][]string{{"1", "Ann", "Oslo", ""}},
+		},
+		{
+			name:        "arrays are joined",
+			input:       `[{"id":1,"tags":["a","b"],"items":[{"n":1}]}]`,
+			options:     DefaultReadOptions(),
+			wantHeader:  []string{"id", "tags", "items"},
+			wantRecords: [][]string{{"1", "a;b", `[{"n":1}]`}},
+		},
+		{
+			name:        "arrays are exploded",
+			input:       `[{"id":1,"tags":["a","b"]},{"id":2,"tags":[]}]`,
+			options:     ReadOptions{Arrays: ArrayExplode},
+			wantHeader:  []string{"id", "tags"},
+			wantRecords: [][]string{{"1", "a"}, {"1", "b"}, {"2", ""}},
+		},
+		{
+			name:        "exploded arrays of objects",
+			input:       `{"id":1,"items":[{"sku":"A","qty":2},{"sku":"B","qty":1}]}`,
+			options:     ReadOptions{Arrays: ArrayExplode},
+			wantHeader:  []string{"id", "items.sku", "items.qty"},
+			wantRecords: [][]string{{"1", "A", "2"}, {"1", "B", "1"}},
+		},
+		{
+			name:        "large numbers keep their text",
+			input:       `{"n":12345678901234567890,"f":1.50}`,
+			options:     DefaultReadOptions(),
+			wantHeader:  []string{"n", "f"},
+			wantRecords: [][]string{{"12345678901234567890", "1.50"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csv, err := Read(strings.NewReader(tt.input), tt.options)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !reflect.DeepEqual(csv.Header, tt.wantHeader) {
+				t.Errorf("Header = %v, want %v", csv.Header, tt.wantHeader)
+			}
+			if !reflect.DeepEqual(csv.Records, tt.wantRecords) {
+				t.Errorf("Records = %v, want %v", csv.Records, tt.wantRecords)
+			}
+		})
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	if _, err := Read(strings.NewReader(`[{"a":1}`), DefaultReadOptions()); err == nil {
+		t.Error("Read() expected error for truncated JSON")
+	}
+}
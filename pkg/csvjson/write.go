@@ -0,0 +1,176 @@
+package csvjson
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+)
+
+type WriteOptions struct {
+	NDJSON     bool
+	InferTypes bool
+	Types      map[string]csvstats.ValueType
+}
+
+func Write(r *csvparser.Reader, writer io.Writer, options WriteOptions) error {
+	if options.Types != nil {
+		return write(r.Header(), columnTypes(r.Header(), options.Types), r.ForEach, writer, options)
+	}
+	if !options.InferTypes {
+		return write(r.Header(), nil, r.ForEach, writer, options)
+	}
+
+	csv, err := csvparser.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return WriteCSV(csv, writer, options)
+}
+
+func WriteCSV(csv *csvparser.CSV, writer io.Writer, options WriteOptions) error {
+	var types []csvstats.ValueType
+	switch {
+	case options.Types != nil:
+		types = columnTypes(csv.Header, options.Types)
+	case options.InferTypes:
+		types = InferColumnTypes(csv)
+	}
+
+	forEach := func(fn func([]string) error) error {
+		for _, record := range csv.Records {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return write(csv.Header, types, forEach, writer, options)
+}
+
+func InferColumnTypes(csv *csvparser.CSV) []csvstats.ValueType {
+	inferrers := make([]csvstats.TypeInferrer, len(csv.Header))
+	for _, record := range csv.Records {
+		for i := range inferrers {
+			if i < len(record) {
+				inferrers[i].Add(record[i])
+			}
+		}
+	}
+
+	types := make([]csvstats.ValueType, len(inferrers))
+	for i, ti := range inferrers {
+		types[i] = ti.Type
+	}
+	return types
+}
+
+func columnTypes(header []string, byName map[string]csvstats.ValueType) []csvstats.ValueType {
+	types := make([]csvstats.ValueType, len(header))
+	for i, name := range header {
+		if t, ok := byName[name]; ok {
+			types[i] = t
+		} else {
+			types[i] = csvstats.TypeString
+		}
+	}
+	return types
+}
+
+func write(header []string, types []csvstats.ValueType, forEach func(func([]string) error) error, writer io.Writer, options WriteOptions) error {
+	bw := bufio.NewWriter(writer)
+
+	keys := make([][]byte, len(header))
+	for i, name := range header {
+		var buf bytes.Buffer
+		writeString(&buf, name)
+		keys[i] = buf.Bytes()
+	}
+
+	if !options.NDJSON {
+		bw.WriteString("[")
+	}
+
+	first := true
+	var buf bytes.Buffer
+	err := forEach(func(record []string) error {
+		buf.Reset()
+		if !options.NDJSON {
+			if first {
+				buf.WriteString("\n  ")
+			} else {
+				buf.WriteString(",\n  ")
+			}
+		}
+		first = false
+
+		buf.WriteByte('{')
+		for i := range header {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(keys[i])
+			buf.WriteByte(':')
+
+			value := ""
+			if i < len(record) {
+				value = record[i]
+			}
+			if types == nil {
+				writeString(&buf, value)
+			} else {
+				writeTyped(&buf, value, types[i])
+			}
+		}
+		buf.WriteByte('}')
+		if options.NDJSON {
+			buf.WriteByte('\n')
+		}
+
+		_, err := bw.Write(buf.Bytes())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if !options.NDJSON {
+		if !first {
+			bw.WriteString("\n")
+		}
+		bw.WriteString("]\n")
+	}
+	return bw.Flush()
+}
+
+func writeTyped(buf *bytes.Buffer, value string, t csvstats.ValueType) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		buf.WriteString("null")
+		return
+	}
+
+	switch t {
+	case csvstats.TypeInteger, csvstats.TypeNumber:
+		if n, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			buf.WriteString(strconv.FormatInt(n, 10))
+			return
+		}
+		if f, err := strconv.ParseFloat(trimmed, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			buf.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+			return
+		}
+	case csvstats.TypeBoolean:
+		if b, ok := csvstats.ParseBool(trimmed); ok {
+			buf.WriteString(strconv.FormatBool(b))
+			return
+		}
+	}
+
+	writeString(buf, value)
+}
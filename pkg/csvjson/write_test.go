@@ -0,0 +1,92 @@
+package csvjson
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+)
+
+func TestWrite(t *testing.T) {
+	input := "id,name,score,active,note\n1,Ann,3.5,true,\n2,\"Bo <Jr>\",10,false,x\n"
+
+	tests := []struct {
+		name    string
+		options WriteOptions
+		want    string
+	}{
+		{
+			name:    "array of strings",
+			options: WriteOptions{},
+			want: `[
+  {"id":"1","name":"Ann","score":"3.5","active":"true","note":""},
+  {"id":"2","name":"Bo <Jr>","score":"10","active":"false","note":"x"}
+]
+`,
+		},
+		{
+			name:    "ndjson with inferred types",
+			options: WriteOptions{NDJSON: true, InferTypes: true},
+			want: `{"id":1,"name":"Ann","score":3.5,"active":true,"note":null}
+{"id":2,"name":"Bo <Jr>","score":10,"active":false,"note":"x"}
+`,
+		},
+		{
+			name:    "types from a schema",
+			options: WriteOptions{NDJSON: true, InferTypes: true, Types: map[string]csvstats.ValueType{"id": csvstats.TypeString, "score": csvstats.TypeNumber}},
+			want: `{"id":"1","name":"Ann","score":3.5,"active":"true","note":null}
+{"id":"2","name":"Bo <Jr>","score":10,"active":"false","note":"x"}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := csvparser.NewReader(strings.NewReader(input), csvparser.DefaultConfig())
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := Write(r, &buf, tt.options); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteEmpty(t *testing.T) {
+	csv := &csvparser.CSV{Header: []string{"a"}, Records: [][]string{}}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(csv, &buf, WriteOptions{}); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("WriteCSV() = %q, want %q", buf.String(), "[]\n")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := `[{"id":1,"user":{"name":"Ann"},"ok":true,"note":null}]`
+
+	csv, err := Read(strings.NewReader(input), DefaultReadOptions())
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(csv, &buf, WriteOptions{NDJSON: true, InferTypes: true}); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	want := `{"id":1,"user.name":"Ann","ok":true,"note":null}` + "\n"
+	if buf.String() != want {
+		t.Errorf("round trip = %q, want %q", buf.String(), want)
+	}
+}
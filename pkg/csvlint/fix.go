@@ -0,0 +1,408 @@
+package csvlint
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type RaggedMode int
+
+const (
+	RaggedKeep RaggedMode = iota
+	RaggedPad
+	RaggedTruncate
+	RaggedPadTruncate
+)
+
+func ParseRaggedMode(s string) (RaggedMode, error) {
+	switch strings.ToLower(s) {
+	case "keep":
+		return RaggedKeep, nil
+	case "pad":
+		return RaggedPad, nil
+	case "truncate":
+		return RaggedTruncate, nil
+	case "both", "pad-truncate":
+		return RaggedPadTruncate, nil
+	default:
+		return RaggedKeep, fmt.Errorf("unknown ragged row mode %q (available: pad, truncate, both, keep)", s)
+	}
+}
+
+type FixOptions struct {
+	Delimiter  rune
+	Ragged     RaggedMode
+	TrimSpace  bool
+	LineEnding string
+}
+
+func DefaultFixOptions() FixOptions {
+	return FixOptions{Delimiter: ',', Ragged: RaggedPad}
+}
+
+type Change struct {
+	Line        int
+	Record      int
+	Rule        string
+	Description string
+	Unfixed     bool
+}
+
+func (c Change) String() string {
+	if c.Record == 0 {
+		return fmt.Sprintf("Line %d: %s", c.Line, c.Description)
+	}
+	return fmt.Sprintf("Line %d, record #%d: %s", c.Line, c.Record, c.Description)
+}
+
+type fixer struct {
+	options FixOptions
+	linter  *linter
+	diff    io.Writer
+
+	header  []string
+	records int
+	crlf    bool
+	decided bool
+	outLine int
+	changes []Change
+}
+
+func Fix(reader io.Reader, writer io.Writer, options FixOptions, diff io.Writer) ([]Change, error) {
+	f := &fixer{
+		options: options,
+		linter:  &linter{options: Options{Delimiter: options.Delimiter}, delimiter: string(options.Delimiter)},
+		diff:    diff,
+		outLine: 1,
+	}
+	switch strings.ToLower(options.LineEnding) {
+	case "", "auto":
+	case "lf":
+		f.decided = true
+	case "crlf":
+		f.crlf, f.decided = true, true
+	default:
+		return nil, fmt.Errorf("unknown line ending %q (available: lf, crlf, auto)", options.LineEnding)
+	}
+
+	br := bufio.NewReader(reader)
+	var bom []byte
+	if peek, _ := br.Peek(len(utf8BOM)); bytes.Equal(peek, utf8BOM) {
+		br.Discard(len(utf8BOM))
+		bom = utf8BOM
+		f.change(1, 0, "bom", "removed byte order mark")
+	}
+
+	out := bufio.NewWriter(writer)
+	line := 1
+	for {
+		rec, err := f.linter.readRecord(br, line)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return f.changes, err
+		}
+		line += rec.lines
+
+		fixed := f.fixRecord(rec)
+		original := rec.text
+		if bom != nil {
+			original = append(append([]byte{}, bom...), rec.text...)
+			bom = nil
+		}
+		if !bytes.Equal(original, fixed) {
+			if err := f.writeHunk(rec.line, original, fixed); err != nil {
+				return f.changes, err
+			}
+		}
+		f.outLine += bytes.Count(fixed, []byte("\n"))
+		if _, err := out.Write(fixed); err != nil {
+			return f.changes, err
+		}
+	}
+	return f.changes, out.Flush()
+}
+
+func FixFile(filename string, options FixOptions, dryRun bool, diff io.Writer) ([]Change, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	reader, decompressor, err := csvparser.Decompress(file)
+	if err != nil {
+		return nil, err
+	}
+	if decompressor != nil {
+		defer decompressor.Close()
+	}
+
+	if dryRun {
+		return Fix(reader, io.Discard, options, diff)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".csvtk-fix-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	out, err := csvparser.CompressWriter(tmp, filename)
+	if err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	changes, err := Fix(reader, out, options, diff)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || !slices.ContainsFunc(changes, Change.fixed) {
+		return changes, err
+	}
+
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return changes, err
+	}
+	return changes, os.Rename(tmp.Name(), filename)
+}
+
+func (c Change) fixed() bool {
+	return !c.Unfixed
+}
+
+func (f *fixer) change(line, record int, rule, description string) {
+	f.changes = append(f.changes, Change{Line: line, Record: record, Rule: rule, Description: description})
+}
+
+func (f *fixer) unfixed(line, record int, rule, description string) {
+	f.changes = append(f.changes, Change{Line: line, Record: record, Rule: rule, Description: description, Unfixed: true})
+}
+
+func (f *fixer) fixRecord(rec *rawRecord) []byte {
+	if !f.decided && bytes.IndexByte(rec.text, '\n') >= 0 {
+		f.crlf = bytes.Contains(rec.text, []byte("\r\n"))
+		f.decided = true
+	}
+
+	if rec.blank {
+		f.change(rec.line, 0, "blank-line", "dropped blank line")
+		return nil
+	}
+
+	header := f.header == nil
+	num := 0
+	if !header {
+		f.records++
+		num = f.records
+	}
+
+	fields, modified := rec.fields, false
+	if rec.err != nil && (rec.unterminated || rec.lines > 1) {
+		if header {
+			f.header = []string{}
+		}
+		if rec.unterminated {
+			f.unfixed(rec.line, num, "parse-error", fmt.Sprintf("quoted field opened at line %d, column %d is never closed; left unchanged", rec.quoteLine, rec.quoteColumn))
+		} else {
+			f.unfixed(rec.line, num, "parse-error", fmt.Sprintf("stray quotes in a record spanning %d lines; left unchanged", rec.lines))
+		}
+		return rec.text
+	}
+	if rec.err != nil {
+		r := csv.NewReader(bytes.NewReader(rec.text))
+		r.Comma = f.options.Delimiter
+		r.LazyQuotes = true
+		r.FieldsPerRecord = -1
+		lazy, err := r.Read()
+		if err != nil {
+			if header {
+				f.header = []string{}
+			}
+			return f.lineEndings(rec, rec.text)
+		}
+		fields, modified = lazy, true
+		f.change(rec.line, num, "parse-error", "re-quoted fields with stray quotes")
+	}
+
+	if f.options.TrimSpace {
+		trimmed := 0
+		for i, field := range fields {
+			if t := strings.TrimSpace(field); t != field {
+				fields[i] = t
+				trimmed++
+			}
+		}
+		if trimmed > 0 {
+			modified = true
+			f.change(rec.line, num, "whitespace", fmt.Sprintf("trimmed whitespace in %d cell(s)", trimmed))
+		}
+	}
+
+	if header {
+		if f.fixHeader(rec, fields) {
+			modified = true
+		}
+		f.header = fields
+	} else if f.fixRagged(rec, num, &fields) {
+		modified = true
+	}
+
+	if !modified {
+		return f.lineEndings(rec, rec.text)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = f.options.Delimiter
+	w.UseCRLF = f.crlf
+	w.Write(fields)
+	w.Flush()
+
+	fixed := buf.Bytes()
+	if !bytes.HasSuffix(rec.text, []byte("\n")) {
+		fixed = bytes.TrimRight(fixed, "\r\n")
+	}
+	return fixed
+}
+
+func (f *fixer) fixHeader(rec *rawRecord, fields []string) bool {
+	modified := false
+	seen := make(map[string]bool, len(fields))
+	for _, name := range fields {
+		seen[name] = true
+	}
+
+	counts := make(map[string]int, len(fields))
+	for i, name := range fields {
+		if name == "" {
+			renamed := "column" + strconv.Itoa(i+1)
+			for n := 2; seen[renamed]; n++ {
+				renamed = "column" + strconv.Itoa(i+1) + "_" + strconv.Itoa(n)
+			}
+			fields[i], seen[renamed], modified = renamed, true, true
+			f.change(rec.line, 0, "empty-header", fmt.Sprintf("named empty header in column %d %q", i+1, renamed))
+			continue
+		}
+
+		counts[name]++
+		if counts[name] == 1 {
+			continue
+		}
+		renamed := name + "_" + strconv.Itoa(counts[name])
+		for n := counts[name] + 1; seen[renamed]; n++ {
+			renamed = name + "_" + strconv.Itoa(n)
+		}
+		fields[i], seen[renamed], modified = renamed, true, true
+		f.change(rec.line, 0, "duplicate-header", fmt.Sprintf("renamed duplicate header %q in column %d to %q", name, i+1, renamed))
+	}
+	return modified
+}
+
+func (f *fixer) fixRagged(rec *rawRecord, num int, fields *[]string) bool {
+	want, got := len(f.header), len(*fields)
+	if want == 0 || got == want {
+		return false
+	}
+
+	pad := f.options.Ragged == RaggedPad || f.options.Ragged == RaggedPadTruncate
+	truncate := f.options.Ragged == RaggedTruncate || f.options.Ragged == RaggedPadTruncate
+
+	if got < want && pad {
+		for len(*fields) < want {
+			*fields = append(*fields, "")
+		}
+		f.change(rec.line, num, "field-count", fmt.Sprintf("padded record from %d to %d fields", got, want))
+		return true
+	}
+
+	if got > want {
+		extra := (*fields)[want:]
+		if truncate || (pad && strings.Join(extra, "") == "") {
+			*fields = (*fields)[:want]
+			f.change(rec.line, num, "field-count", fmt.Sprintf("truncated record from %d to %d fields", got, want))
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fixer) lineEndings(rec *rawRecord, text []byte) []byte {
+	normalized := bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))
+	if f.crlf {
+		normalized = bytes.ReplaceAll(normalized, []byte("\n"), []byte("\r\n"))
+	}
+	if !bytes.Equal(normalized, text) {
+		ending := "LF"
+		if f.crlf {
+			ending = "CRLF"
+		}
+		f.change(rec.line, 0, "mixed-line-endings", "normalized line ending to "+ending)
+	}
+	return normalized
+}
+
+func (f *fixer) writeHunk(line int, original, fixed []byte) error {
+	if f.diff == nil {
+		return nil
+	}
+	before, after := diffLines(original), diffLines(fixed)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(line, len(before)), hunkRange(f.outLine, len(after)))
+	for _, l := range before {
+		writeDiffLine(&buf, '-', l)
+	}
+	for _, l := range after {
+		writeDiffLine(&buf, '+', l)
+	}
+	_, err := f.diff.Write(buf.Bytes())
+	return err
+}
+
+func diffLines(text []byte) []string {
+	if len(text) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return strconv.Itoa(start) + "," + strconv.Itoa(count)
+}
+
+func writeDiffLine(buf *bytes.Buffer, prefix byte, line string) {
+	buf.WriteByte(prefix)
+	buf.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		buf.WriteString("\n\\ No newline at end of file\n")
+	}
+}
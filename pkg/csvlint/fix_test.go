@@ -0,0 +1,191 @@
+package csvlint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options func(*FixOptions)
+		want    string
+		rules   []string
+	}{
+		{
+			name:  "clean file is unchanged",
+			input: "id,name\n1,\"Ann\"\n",
+			want:  "id,name\n1,\"Ann\"\n",
+		},
+		{
+			name:  "bom and blank lines",
+			input: "\xef\xbb\xbfid,name\n\n1,Ann\n\n",
+			want:  "id,name\n1,Ann\n",
+			rules: []string{"bom", "blank-line", "blank-line"},
+		},
+		{
+			name:    "whitespace",
+			input:   "id,name\n1, Ann \n",
+			options: func(o *FixOptions) { o.TrimSpace = true },
+			want:    "id,name\n1,Ann\n",
+			rules:   []string{"whitespace"},
+		},
+		{
+			name:  "whitespace kept by default",
+			input: "id,name\n1, Ann \n",
+			want:  "id,name\n1, Ann \n",
+		},
+		{
+			name:  "pad short rows and drop empty extra fields",
+			input: "id,name,age\n1,Ann\n2,Bob,30,\n3,Cy,40,x\n",
+			want:  "id,name,age\n1,Ann,\n2,Bob,30\n3,Cy,40,x\n",
+			rules: []string{"field-count", "field-count"},
+		},
+		{
+			name:    "truncate long rows",
+			input:   "id,name\n1,Ann,x\n2\n",
+			options: func(o *FixOptions) { o.Ragged = RaggedTruncate },
+			want:    "id,name\n1,Ann\n2\n",
+			rules:   []string{"field-count"},
+		},
+		{
+			name:  "stray quotes",
+			input: "id,note\n1,say \"hi\"\n",
+			want:  "id,note\n1,\"say \"\"hi\"\"\"\n",
+			rules: []string{"parse-error"},
+		},
+		{
+			name:  "unclosed quote is left unchanged",
+			input: "a,b\n\"x,1\n2,3\n4,5\n",
+			want:  "a,b\n\"x,1\n2,3\n4,5\n",
+			rules: []string{"parse-error"},
+		},
+		{
+			name:  "stray quotes across lines are left unchanged",
+			input: "id,note\n1,\"a\nb\" c\n2,d\n",
+			want:  "id,note\n1,\"a\nb\" c\n2,d\n",
+			rules: []string{"parse-error"},
+		},
+		{
+			name:  "duplicate and empty headers",
+			input: "id,,id,id_2\n1,2,3,4\n",
+			want:  "id,column2,id_3,id_2\n1,2,3,4\n",
+			rules: []string{"empty-header", "duplicate-header"},
+		},
+		{
+			name:  "mixed line endings follow the first line",
+			input: "id,name\r\n1,Ann\n2,Bob\r\n",
+			want:  "id,name\r\n1,Ann\r\n2,Bob\r\n",
+			rules: []string{"mixed-line-endings"},
+		},
+		{
+			name:    "forced line ending",
+			input:   "id,name\r\n1,Ann\r\n",
+			options: func(o *FixOptions) { o.LineEnding = "lf" },
+			want:    "id,name\n1,Ann\n",
+			rules:   []string{"mixed-line-endings", "mixed-line-endings"},
+		},
+		{
+			name:    "no final newline",
+			input:   "id,name\n1, Ann",
+			options: func(o *FixOptions) { o.TrimSpace = true },
+			want:    "id,name\n1,Ann",
+			rules:   []string{"whitespace"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultFixOptions()
+			if tt.options != nil {
+				tt.options(&options)
+			}
+
+			var out bytes.Buffer
+			changes, err := Fix(strings.NewReader(tt.input), &out, options, nil)
+			if err != nil {
+				t.Fatalf("Fix() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("Fix() output = %q, want %q", out.String(), tt.want)
+			}
+
+			var rules []string
+			for _, c := range changes {
+				rules = append(rules, c.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.rules) {
+				t.Errorf("Fix() changes = %v, want rules %v", changes, tt.rules)
+			}
+		})
+	}
+}
+
+func TestFixDiff(t *testing.T) {
+	options := DefaultFixOptions()
+	options.TrimSpace = true
+
+	var out, diff bytes.Buffer
+	_, err := Fix(strings.NewReader("id,name\n\n1, Ann\n2,Bob\n"), &out, options, &diff)
+	if err != nil {
+		t.Fatalf("Fix() error = %v", err)
+	}
+
+	want := "@@ -2 +1,0 @@\n-\n@@ -3 +2 @@\n-1, Ann\n+1,Ann\n"
+	if diff.String() != want {
+		t.Errorf("diff = %q, want %q", diff.String(), want)
+	}
+}
+
+func TestFixFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "data.csv.gz")
+	file, _ := os.Create(filename)
+	zw := gzip.NewWriter(file)
+	zw.Write([]byte("id,name\n1, Ann\n"))
+	zw.Close()
+	file.Close()
+
+	options := DefaultFixOptions()
+	options.TrimSpace = true
+
+	changes, err := FixFile(filename, options, true, io.Discard)
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("FixFile(dry run) = %v, %v, want one change", changes, err)
+	}
+
+	if _, err := FixFile(filename, options, false, nil); err != nil {
+		t.Fatalf("FixFile() error = %v", err)
+	}
+	file, _ = os.Open(filename)
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("fixed file is not gzip: %v", err)
+	}
+	got, _ := io.ReadAll(zr)
+	if string(got) != "id,name\n1,Ann\n" {
+		t.Errorf("fixed file = %q, want %q", got, "id,name\n1,Ann\n")
+	}
+}
+
+func TestFixFileUnfixable(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "data.csv")
+	input := "a,b\n\"x,1\n2,3\n"
+	os.WriteFile(filename, []byte(input), 0o644)
+
+	changes, err := FixFile(filename, DefaultFixOptions(), false, nil)
+	if err != nil || len(changes) != 1 || !changes[0].Unfixed {
+		t.Fatalf("FixFile() = %v, %v, want one unfixed change", changes, err)
+	}
+	got, _ := os.ReadFile(filename)
+	if string(got) != input {
+		t.Errorf("file = %q, want it left as %q", got, input)
+	}
+}
@@ -1,72 +1,316 @@
 package csvlint
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/csv"
 	"fmt"
 	"io"
 	"os"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
 )
 
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
 type CSVError struct {
 	Record []string
 
-	Num int
-	err error
+	Num      int
+	Line     int
+	Column   int
+	Rule     string
+	Severity Severity
+	Value    string
+	Err      error
 }
 
 func (e CSVError) Error() string {
-	return fmt.Sprintf("Record #%d has error: %s", e.Num, e.err.Error())
+	if e.Line == 0 {
+		return fmt.Sprintf("Record #%d has error: %s", e.Num, e.Err.Error())
+	}
+	if e.Num == 0 {
+		return fmt.Sprintf("Line %d, column %d: %s", e.Line, e.Column, e.Err.Error())
+	}
+	return fmt.Sprintf("Line %d, column %d: record #%d has error: %s", e.Line, e.Column, e.Num, e.Err.Error())
+}
+
+func (e CSVError) Unwrap() error {
+	return e.Err
+}
+
+type Options struct {
+	Delimiter  rune
+	LazyQuotes bool
+	MaxErrors  int
+	Rules      map[string]bool
+}
+
+func DefaultOptions() Options {
+	return Options{Delimiter: ','}
+}
+
+func (o Options) Enabled(rule Rule) bool {
+	if enabled, ok := o.Rules[rule.ID]; ok {
+		return enabled
+	}
+	return rule.Default
 }
 
 func Validate(reader io.Reader, delimiter rune, lazyquotes bool) ([]CSVError, bool, error) {
-	r := csv.NewReader(reader)
-	r.FieldsPerRecord = -1
-	r.LazyQuotes = lazyquotes
-	r.Comma = delimiter
-
-	var header []string
-	errors := []CSVError{}
-	records := 0
-	for {
-		record, err := r.Read()
-		if header != nil {
-			records++
+	return ValidateReader(reader, Options{Delimiter: delimiter, LazyQuotes: lazyquotes})
+}
+
+func ValidateReader(reader io.Reader, options Options) ([]CSVError, bool, error) {
+	l := &linter{
+		options:   options,
+		delimiter: string(options.Delimiter),
+		byID:      make(map[string]Rule),
+		quoting:   make(map[int]bool),
+		errs:      []CSVError{},
+	}
+	for _, rule := range Rules {
+		l.byID[rule.ID] = rule
+		if rule.check != nil && options.Enabled(rule) {
+			l.rules = append(l.rules, rule)
+		}
+	}
+
+	br := bufio.NewReader(reader)
+	if bom, _ := br.Peek(len(utf8BOM)); bytes.Equal(bom, utf8BOM) {
+		br.Discard(len(utf8BOM))
+		l.report("bom", &rawRecord{}, 1, 1, "", ErrBOM)
+	}
+
+	line := 1
+	for !l.full() {
+		rec, err := l.readRecord(br, line)
+		if err == io.EOF {
+			break
 		}
 		if err != nil {
-			if err == io.EOF {
-				break
+			return l.errs, true, err
+		}
+		line += rec.lines
+
+		if !rec.blank {
+			rec.header = l.header == nil
+			if !rec.header {
+				l.records++
+				rec.num = l.records
 			}
-			parsedErr, ok := err.(*csv.ParseError)
-			if !ok {
-				return errors, true, err
+		}
+		for _, rule := range l.rules {
+			rule.check(l, rec)
+		}
+		if rec.header {
+			l.header = []string{}
+			if rec.parsed() {
+				l.header = rec.fields
 			}
-			errors = append(errors, CSVError{
-				Record: nil,
-				Num:    records,
-				err:    parsedErr.Err,
-			})
-			return errors, true, nil
 		}
-		if header == nil {
-			header = record
-			continue
-		} else if len(record) != len(header) {
-			errors = append(errors, CSVError{
-				Record: record,
-				Num:    records,
-				err:    csv.ErrFieldCount,
-			})
+	}
+	return l.errs, l.hasErrors, nil
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+type linter struct {
+	options   Options
+	delimiter string
+	rules     []Rule
+	byID      map[string]Rule
+
+	header  []string
+	records int
+	quoting map[int]bool
+
+	ending       string
+	endingLine   int
+	mixedEndings bool
+
+	errs      []CSVError
+	hasErrors bool
+}
+
+type rawRecord struct {
+	text   []byte
+	line   int
+	lines  int
+	blank  bool
+	header bool
+	num    int
+
+	fields []string
+	quoted []bool
+	err    error
+	parser *csv.Reader
+
+	unterminated bool
+	quoteLine    int
+	quoteColumn  int
+	quoteText    []byte
+}
+
+func (r *rawRecord) parsed() bool {
+	return !r.blank && r.err == nil
+}
+
+func (r *rawRecord) pos(field int) (int, int) {
+	line, column := r.parser.FieldPos(field)
+	return r.line + line - 1, column
+}
+
+func (l *linter) full() bool {
+	return l.options.MaxErrors > 0 && len(l.errs) >= l.options.MaxErrors
+}
+
+func (l *linter) report(id string, rec *rawRecord, line, column int, value string, err error) {
+	if l.full() {
+		return
+	}
+	rule := l.byID[id]
+	if !l.options.Enabled(rule) {
+		return
+	}
+	if rule.Severity == SeverityError {
+		l.hasErrors = true
+	}
+	l.errs = append(l.errs, CSVError{
+		Record:   rec.fields,
+		Num:      rec.num,
+		Line:     line,
+		Column:   column,
+		Rule:     rule.ID,
+		Severity: rule.Severity,
+		Value:    value,
+		Err:      err,
+	})
+}
+
+type quoteState int
+
+const (
+	fieldStart quoteState = iota
+	unquotedField
+	quotedField
+	quoteInQuotedField
+)
+
+func (l *linter) readRecord(br *bufio.Reader, line int) (*rawRecord, error) {
+	rec := &rawRecord{line: line, quoted: []bool{false}}
+	delimiter := []byte(l.delimiter)
+	state := fieldStart
+
+	for {
+		chunk, err := br.ReadBytes('\n')
+		if len(chunk) > 0 {
+			rec.lines++
+			rec.text = append(rec.text, chunk...)
+		}
+		for i := 0; i < len(chunk); i++ {
+			if chunk[i] == '\r' || chunk[i] == '\n' {
+				if state == quoteInQuotedField {
+					state = unquotedField
+				}
+				continue
+			}
+			isDelimiter := bytes.HasPrefix(chunk[i:], delimiter)
+			switch state {
+			case fieldStart, unquotedField:
+				switch {
+				case isDelimiter:
+					state = fieldStart
+					rec.quoted = append(rec.quoted, false)
+					i += len(delimiter) - 1
+				case state == fieldStart && chunk[i] == '"':
+					state = quotedField
+					rec.quoted[len(rec.quoted)-1] = true
+					rec.quoteLine, rec.quoteColumn, rec.quoteText = line+rec.lines-1, i+1, chunk
+				default:
+					state = unquotedField
+				}
+			case quotedField:
+				if chunk[i] == '"' {
+					state = quoteInQuotedField
+				}
+			case quoteInQuotedField:
+				switch {
+				case chunk[i] == '"':
+					state = quotedField
+				case isDelimiter:
+					state = fieldStart
+					rec.quoted = append(rec.quoted, false)
+					i += len(delimiter) - 1
+				case l.options.LazyQuotes:
+					state = quotedField
+				default:
+					state = unquotedField
+				}
+			}
+		}
+
+		if err == io.EOF {
+			if len(rec.text) == 0 {
+				return nil, io.EOF
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if state != quotedField {
+			break
 		}
 	}
-	return errors, false, nil
+
+	rec.unterminated = state == quotedField
+	if len(bytes.TrimRight(rec.text, "\r\n")) == 0 {
+		rec.blank = true
+		return rec, nil
+	}
+
+	rec.parser = csv.NewReader(bytes.NewReader(rec.text))
+	rec.parser.Comma = l.options.Delimiter
+	rec.parser.LazyQuotes = l.options.LazyQuotes
+	rec.parser.FieldsPerRecord = -1
+	rec.fields, rec.err = rec.parser.Read()
+	if rec.err != nil {
+		rec.fields = nil
+	}
+	return rec, nil
 }
 
 func ValidateFile(filename string, delimiter rune, lazyquotes bool) ([]CSVError, bool, error) {
+	return ValidateFileWithOptions(filename, Options{Delimiter: delimiter, LazyQuotes: lazyquotes})
+}
+
+func ValidateFileWithOptions(filename string, options Options) ([]CSVError, bool, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	return Validate(file, delimiter, lazyquotes)
+	reader, decompressor, err := csvparser.Decompress(file)
+	if err != nil {
+		return nil, false, err
+	}
+	if decompressor != nil {
+		defer decompressor.Close()
+	}
+
+	return ValidateReader(reader, options)
 }
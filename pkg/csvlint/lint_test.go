@@ -81,7 +81,7 @@ func TestCSVError_Error(t *testing.T) {
 	err := CSVError{
 		Record: []string{"John", "30"},
 		Num:    1,
-		err:    &testError{"field count mismatch"},
+		Err:    &testError{"field count mismatch"},
 	}
 
 	expected := "Record #1 has error: field count mismatch"
@@ -97,3 +97,57 @@ type testError struct {
 func (e *testError) Error() string {
 	return e.msg
 }
+
+func TestValidateReaderRecovers(t *testing.T) {
+	input := "a,b,c\n1,2,3\n4,x\"y,6\n7,8\n\"p\"q,1,2\n9,10,11,12\n"
+
+	errors, fatal, err := ValidateReader(strings.NewReader(input), DefaultOptions())
+	if err != nil {
+		t.Fatalf("ValidateReader() unexpected error = %v", err)
+	}
+	if !fatal {
+		t.Error("ValidateReader() fatal = false, want true")
+	}
+
+	want := []struct {
+		line, column, num int
+		severity          Severity
+	}{
+		{3, 4, 2, SeverityError},
+		{4, 1, 3, SeverityWarning},
+		{5, 3, 4, SeverityError},
+		{6, 9, 5, SeverityWarning},
+	}
+	if len(errors) != len(want) {
+		t.Fatalf("ValidateReader() got %d errors, want %d: %v", len(errors), len(want), errors)
+	}
+	for i, w := range want {
+		e := errors[i]
+		if e.Line != w.line || e.Column != w.column || e.Num != w.num || e.Severity != w.severity {
+			t.Errorf("errors[%d] = line %d, column %d, record %d, %s; want line %d, column %d, record %d, %s",
+				i, e.Line, e.Column, e.Num, e.Severity, w.line, w.column, w.num, w.severity)
+		}
+	}
+}
+
+func TestValidateReaderMaxErrors(t *testing.T) {
+	options := DefaultOptions()
+	options.MaxErrors = 2
+
+	errors, _, err := ValidateReader(strings.NewReader("a,b\n1\n2\n3\n4\n"), options)
+	if err != nil {
+		t.Fatalf("ValidateReader() unexpected error = %v", err)
+	}
+	if len(errors) != 2 {
+		t.Errorf("ValidateReader() got %d errors, want 2", len(errors))
+	}
+}
+
+func TestCSVError_ErrorWithPosition(t *testing.T) {
+	err := CSVError{Num: 2, Line: 3, Column: 4, Err: &testError{"bare quote"}}
+
+	expected := "Line 3, column 4: record #2 has error: bare quote"
+	if err.Error() != expected {
+		t.Errorf("Error() = %s, want %s", err.Error(), expected)
+	}
+}
@@ -0,0 +1,263 @@
+package csvlint
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Report struct {
+	Command   string
+	File      string
+	Rules     []Rule
+	Problems  []CSVError
+	MaxErrors int
+}
+
+func NewReport(file string, options Options, problems []CSVError) Report {
+	report := Report{Command: "lint", File: file, Problems: problems, MaxErrors: options.MaxErrors}
+	for _, rule := range Rules {
+		if options.Enabled(rule) {
+			report.Rules = append(report.Rules, rule)
+		}
+	}
+	return report
+}
+
+func (r Report) Counts() (errors, warnings int) {
+	for _, p := range r.Problems {
+		if p.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+func (r Report) Truncated() bool {
+	return r.MaxErrors > 0 && len(r.Problems) >= r.MaxErrors
+}
+
+func WriteText(w io.Writer, report Report) error {
+	errors, warnings := report.Counts()
+	switch {
+	case len(report.Problems) == 0:
+		_, err := fmt.Fprintln(w, "✓ CSV file is valid")
+		return err
+	case errors > 0:
+		fmt.Fprintf(w, "❌ CSV validation failed with %d error(s) and %d warning(s):\n", errors, warnings)
+	default:
+		fmt.Fprintf(w, "⚠️  CSV validation completed with %d warning(s):\n", warnings)
+	}
+	for _, p := range report.Problems {
+		fmt.Fprintf(w, "  %s [%s]: %s\n", p.Severity, p.Rule, p.Error())
+	}
+	if report.Truncated() {
+		_, err := fmt.Fprintf(w, "  (output limited to %d problems by --max-errors)\n", report.MaxErrors)
+		return err
+	}
+	return nil
+}
+
+type jsonProblem struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Record   int    `json:"record"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+	Value    string `json:"value,omitempty"`
+}
+
+type jsonReport struct {
+	File      string        `json:"file"`
+	Valid     bool          `json:"valid"`
+	Errors    int           `json:"errors"`
+	Warnings  int           `json:"warnings"`
+	Truncated bool          `json:"truncated"`
+	Problems  []jsonProblem `json:"problems"`
+}
+
+func WriteJSON(w io.Writer, report Report) error {
+	errors, warnings := report.Counts()
+	out := jsonReport{
+		File:      report.File,
+		Valid:     len(report.Problems) == 0,
+		Errors:    errors,
+		Warnings:  warnings,
+		Truncated: report.Truncated(),
+		Problems:  []jsonProblem{},
+	}
+	for _, p := range report.Problems {
+		out.Problems = append(out.Problems, jsonProblem{
+			Rule:     p.Rule,
+			Severity: p.Severity.String(),
+			Record:   p.Num,
+			Line:     p.Line,
+			Column:   p.Column,
+			Message:  p.Err.Error(),
+			Value:    p.Value,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine   int `json:"startLine"`
+			StartColumn int `json:"startColumn,omitempty"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+func WriteSARIF(w io.Writer, report Report) error {
+	driver := sarifDriver{Name: "csvtk", InformationURI: "https://github.com/sean-stapleton-doyle/csvtk", Rules: []sarifRule{}}
+	index := make(map[string]int)
+	for _, rule := range report.Rules {
+		sr := sarifRule{ID: rule.ID, ShortDescription: sarifMessage{Text: rule.Description}}
+		sr.DefaultConfiguration.Level = rule.Severity.String()
+		index[rule.ID] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, sr)
+	}
+
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: []sarifResult{}}
+	for _, p := range report.Problems {
+		message := p.Err.Error()
+		if p.Num > 0 {
+			message = fmt.Sprintf("Record #%d: %s", p.Num, message)
+		}
+
+		var location sarifLocation
+		location.PhysicalLocation.ArtifactLocation.URI = report.File
+		location.PhysicalLocation.Region.StartLine = max(1, p.Line)
+		location.PhysicalLocation.Region.StartColumn = p.Column
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    p.Rule,
+			RuleIndex: index[p.Rule],
+			Level:     p.Severity.String(),
+			Message:   sarifMessage{Text: message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}})
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func WriteJUnit(w io.Writer, report Report) error {
+	byRule := make(map[string][]CSVError)
+	for _, p := range report.Problems {
+		byRule[p.Rule] = append(byRule[p.Rule], p)
+	}
+
+	suite := junitSuite{Name: "csvtk " + report.Command + " " + report.File}
+	for _, rule := range report.Rules {
+		tc := junitCase{Name: rule.ID, ClassName: report.File}
+		if problems := byRule[rule.ID]; len(problems) > 0 {
+			lines := make([]string, len(problems))
+			for i, p := range problems {
+				lines[i] = p.Error()
+			}
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d %s(s): %s", len(problems), rule.Severity, rule.Description),
+				Type:    rule.Severity.String(),
+				Text:    strings.Join(lines, "\n"),
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Tests: suite.Tests, Failures: suite.Failures, Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,111 @@
+package csvlint
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testReport(t *testing.T, input string) Report {
+	t.Helper()
+	options := DefaultOptions()
+	problems, _, err := ValidateReader(strings.NewReader(input), options)
+	if err != nil {
+		t.Fatalf("ValidateReader() unexpected error = %v", err)
+	}
+	return NewReport("data.csv", options, problems)
+}
+
+func TestWriteJSON(t *testing.T) {
+	report := testReport(t, "id,name\n1, Ann\n2\n")
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, report); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var got jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if got.File != "data.csv" || got.Valid || got.Errors != 0 || got.Warnings != 2 {
+		t.Errorf("report = %+v, want 2 warnings for data.csv", got)
+	}
+	if len(got.Problems) != 2 {
+		t.Fatalf("got %d problems, want 2", len(got.Problems))
+	}
+	want := jsonProblem{Rule: "whitespace", Severity: "warning", Record: 1, Line: 2, Column: 3, Message: `leading or trailing whitespace in " Ann"`, Value: " Ann"}
+	if got.Problems[0] != want {
+		t.Errorf("problems[0] = %+v, want %+v", got.Problems[0], want)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	report := testReport(t, "id,id\n1,2\n")
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, report); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+
+	var got sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("log = %+v, want one SARIF 2.1.0 run", got)
+	}
+	run := got.Runs[0]
+	if len(run.Results) != 1 {
+		t.Fatalf("got %d results, want 1", len(run.Results))
+	}
+	result := run.Results[0]
+	if result.RuleID != "duplicate-header" || result.Level != "error" {
+		t.Errorf("result = %+v, want duplicate-header error", result)
+	}
+	if rule := run.Tool.Driver.Rules[result.RuleIndex]; rule.ID != result.RuleID {
+		t.Errorf("ruleIndex %d points at %q, want %q", result.RuleIndex, rule.ID, result.RuleID)
+	}
+	region := result.Locations[0].PhysicalLocation.Region
+	if region.StartLine != 1 || region.StartColumn != 4 {
+		t.Errorf("region = %+v, want line 1 column 4", region)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	report := testReport(t, "id,name\n1, Ann\n2, Bob\n")
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, report); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	var got junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if got.Tests != len(report.Rules) || got.Failures != 1 {
+		t.Errorf("tests = %d, failures = %d, want %d and 1", got.Tests, got.Failures, len(report.Rules))
+	}
+	for _, tc := range got.Suites[0].Cases {
+		if tc.Name == "whitespace" && (tc.Failure == nil || strings.Count(tc.Failure.Text, "\n") != 1) {
+			t.Errorf("whitespace test case = %+v, want a failure listing 2 problems", tc)
+		}
+	}
+}
+
+func TestCSVErrorExportsErr(t *testing.T) {
+	problems, _, err := ValidateReader(strings.NewReader("a,b\n1,2,3\n"), DefaultOptions())
+	if err != nil || len(problems) != 1 {
+		t.Fatalf("ValidateReader() = %v, %v, want one problem", problems, err)
+	}
+
+	var lintErr CSVError
+	if !errors.As(error(problems[0]), &lintErr) || !errors.Is(lintErr.Err, csv.ErrFieldCount) {
+		t.Errorf("Err = %v, want to wrap csv.ErrFieldCount", lintErr.Err)
+	}
+}
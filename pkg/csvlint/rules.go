@@ -0,0 +1,241 @@
+package csvlint
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrDuplicateHeader    = errors.New("duplicate header name")
+	ErrEmptyHeader        = errors.New("empty header name")
+	ErrWhitespace         = errors.New("leading or trailing whitespace")
+	ErrInvalidUTF8        = errors.New("invalid UTF-8")
+	ErrBOM                = errors.New("byte order mark at start of file")
+	ErrMixedLineEndings   = errors.New("mixed line endings")
+	ErrBlankLine          = errors.New("blank line")
+	ErrTrailingDelimiter  = errors.New("trailing delimiter")
+	ErrUnnecessaryQuotes  = errors.New("unnecessary quotes")
+	ErrInconsistentQuotes = errors.New("inconsistent quoting")
+	ErrControlCharacter   = errors.New("control character")
+	ErrUnterminatedQuote  = errors.New("quoted field is never closed")
+)
+
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Description string
+	Default     bool
+
+	check func(l *linter, rec *rawRecord)
+}
+
+var Rules = []Rule{
+	{ID: "parse-error", Severity: SeverityError, Default: true, Description: "Bare or extraneous quotes that break RFC 4180 parsing", check: checkParseError},
+	{ID: "field-count", Severity: SeverityWarning, Default: true, Description: "Records with a different number of fields than the header", check: checkFieldCount},
+	{ID: "duplicate-header", Severity: SeverityError, Default: true, Description: "Header names that appear more than once", check: checkDuplicateHeader},
+	{ID: "empty-header", Severity: SeverityWarning, Default: true, Description: "Empty header names", check: checkEmptyHeader},
+	{ID: "whitespace", Severity: SeverityWarning, Default: true, Description: "Leading or trailing whitespace in cells", check: checkWhitespace},
+	{ID: "invalid-utf8", Severity: SeverityError, Default: true, Description: "Bytes that are not valid UTF-8", check: checkInvalidUTF8},
+	{ID: "bom", Severity: SeverityWarning, Default: true, Description: "Byte order mark at the start of the file"},
+	{ID: "mixed-line-endings", Severity: SeverityWarning, Default: true, Description: "A mix of CRLF and LF line endings", check: checkLineEndings},
+	{ID: "blank-line", Severity: SeverityWarning, Default: true, Description: "Blank lines between records", check: checkBlankLine},
+	{ID: "trailing-delimiter", Severity: SeverityWarning, Default: true, Description: "Records that end with an extra delimiter", check: checkTrailingDelimiter},
+	{ID: "unnecessary-quotes", Severity: SeverityWarning, Default: false, Description: "Quoted cells that would be read the same without quotes", check: checkUnnecessaryQuotes},
+	{ID: "inconsistent-quotes", Severity: SeverityWarning, Default: true, Description: "Cells quoted differently from the rest of their column", check: checkInconsistentQuotes},
+	{ID: "control-character", Severity: SeverityWarning, Default: true, Description: "Control characters in cells", check: checkControlCharacter},
+}
+
+func LookupRule(id string) (Rule, error) {
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+	ids := make([]string, len(Rules))
+	for i, rule := range Rules {
+		ids[i] = rule.ID
+	}
+	return Rule{}, fmt.Errorf("unknown rule %q (available: %s)", id, strings.Join(ids, ", "))
+}
+
+func checkParseError(l *linter, rec *rawRecord) {
+	var parseErr *csv.ParseError
+	if !errors.As(rec.err, &parseErr) {
+		return
+	}
+	if rec.unterminated {
+		l.report("parse-error", rec, rec.quoteLine, rec.quoteColumn, strings.TrimRight(string(rec.quoteText), "\r\n"), ErrUnterminatedQuote)
+		return
+	}
+	l.report("parse-error", rec, rec.line+parseErr.Line-1, parseErr.Column, strings.TrimRight(string(rec.text), "\r\n"), parseErr.Err)
+}
+
+func checkFieldCount(l *linter, rec *rawRecord) {
+	if !rec.parsed() || rec.header || len(l.header) == 0 || len(rec.fields) == len(l.header) {
+		return
+	}
+	line, column := rec.pos(0)
+	if len(rec.fields) > len(l.header) {
+		line, column = rec.pos(len(l.header))
+	}
+	l.report("field-count", rec, line, column, "", fmt.Errorf("%w: expected %d, got %d", csv.ErrFieldCount, len(l.header), len(rec.fields)))
+}
+
+func checkDuplicateHeader(l *linter, rec *rawRecord) {
+	if !rec.parsed() || !rec.header {
+		return
+	}
+	for i, name := range rec.fields {
+		if first := slices.Index(rec.fields, name); first < i && name != "" {
+			line, column := rec.pos(i)
+			l.report("duplicate-header", rec, line, column, name, fmt.Errorf("%w %q (also column %d)", ErrDuplicateHeader, name, first+1))
+		}
+	}
+}
+
+func checkEmptyHeader(l *linter, rec *rawRecord) {
+	if !rec.parsed() || !rec.header {
+		return
+	}
+	for i, name := range rec.fields {
+		if strings.TrimSpace(name) == "" {
+			line, column := rec.pos(i)
+			l.report("empty-header", rec, line, column, name, fmt.Errorf("%w in column %d", ErrEmptyHeader, i+1))
+		}
+	}
+}
+
+func checkWhitespace(l *linter, rec *rawRecord) {
+	if !rec.parsed() {
+		return
+	}
+	for i, field := range rec.fields {
+		if field != "" && strings.TrimSpace(field) != field {
+			line, column := rec.pos(i)
+			l.report("whitespace", rec, line, column, field, fmt.Errorf("%w in %q", ErrWhitespace, field))
+		}
+	}
+}
+
+func checkInvalidUTF8(l *linter, rec *rawRecord) {
+	line, column := rec.line, 1
+	for i := 0; i < len(rec.text); {
+		r, size := utf8.DecodeRune(rec.text[i:])
+		if r == utf8.RuneError && size == 1 {
+			start := i - (column - 1)
+			for i < len(rec.text) && rec.text[i] != '\n' {
+				i++
+			}
+			value := strings.ToValidUTF8(strings.TrimRight(string(rec.text[start:i]), "\r"), "\uFFFD")
+			l.report("invalid-utf8", rec, line, column, value, fmt.Errorf("%w: byte 0x%02X", ErrInvalidUTF8, rec.text[start+column-1]))
+			continue
+		}
+		if r == '\n' {
+			line, column = line+1, 0
+		}
+		i += size
+		column += size
+	}
+}
+
+func checkLineEndings(l *linter, rec *rawRecord) {
+	line := rec.line
+	for i, c := range rec.text {
+		if c != '\n' {
+			continue
+		}
+		ending := "LF"
+		if i > 0 && rec.text[i-1] == '\r' {
+			ending = "CRLF"
+		}
+		switch {
+		case l.ending == "":
+			l.ending, l.endingLine = ending, line
+		case ending != l.ending && !l.mixedEndings:
+			l.mixedEndings = true
+			l.report("mixed-line-endings", rec, line, 1, "", fmt.Errorf("%w: line %d ends with %s but line %d ends with %s", ErrMixedLineEndings, line, ending, l.endingLine, l.ending))
+		}
+		line++
+	}
+}
+
+func checkBlankLine(l *linter, rec *rawRecord) {
+	if rec.blank {
+		l.report("blank-line", rec, rec.line, 1, "", ErrBlankLine)
+	}
+}
+
+func checkTrailingDelimiter(l *linter, rec *rawRecord) {
+	if !rec.parsed() || len(rec.fields) < 2 {
+		return
+	}
+	last := len(rec.fields) - 1
+	if rec.fields[last] != "" || rec.quoted[last] {
+		return
+	}
+	if !rec.header && len(rec.fields) <= len(l.header) {
+		return
+	}
+	line, column := rec.pos(last)
+	l.report("trailing-delimiter", rec, line, max(1, column-len(l.delimiter)), "", ErrTrailingDelimiter)
+}
+
+func checkUnnecessaryQuotes(l *linter, rec *rawRecord) {
+	if !rec.parsed() {
+		return
+	}
+	for i, field := range rec.fields {
+		if rec.quoted[i] && field != "" && !l.needsQuotes(field) {
+			line, column := rec.pos(i)
+			l.report("unnecessary-quotes", rec, line, column, field, fmt.Errorf("%w around %q", ErrUnnecessaryQuotes, field))
+		}
+	}
+}
+
+func checkInconsistentQuotes(l *linter, rec *rawRecord) {
+	if !rec.parsed() || rec.header {
+		return
+	}
+	for i, field := range rec.fields {
+		if field == "" || l.needsQuotes(field) {
+			continue
+		}
+		style, ok := l.quoting[i]
+		if !ok {
+			l.quoting[i] = rec.quoted[i]
+			continue
+		}
+		if style != rec.quoted[i] {
+			line, column := rec.pos(i)
+			want := "unquoted"
+			if style {
+				want = "quoted"
+			}
+			l.report("inconsistent-quotes", rec, line, column, field, fmt.Errorf("%w: earlier values in column %d are %s", ErrInconsistentQuotes, i+1, want))
+		}
+	}
+}
+
+func checkControlCharacter(l *linter, rec *rawRecord) {
+	if !rec.parsed() {
+		return
+	}
+	for i, field := range rec.fields {
+		for _, r := range field {
+			if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+				line, column := rec.pos(i)
+				l.report("control-character", rec, line, column, field, fmt.Errorf("%w %U", ErrControlCharacter, r))
+				break
+			}
+		}
+	}
+}
+
+func (l *linter) needsQuotes(field string) bool {
+	return strings.Contains(field, l.delimiter) || strings.ContainsAny(field, "\"\r\n") || strings.TrimSpace(field) != field
+}
@@ -0,0 +1,114 @@
+package csvlint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		rules map[string]bool
+		want  []string
+	}{
+		{"clean", "id,name\n1,Ann\n2,\"B, b\"\n", nil, nil},
+		{"duplicate header", "id,name,id\n1,a,b\n", nil, []string{"duplicate-header"}},
+		{"empty header", "id,,name\n1,a,b\n", nil, []string{"empty-header"}},
+		{"whitespace", "id,name\n1, Ann\n", nil, []string{"whitespace"}},
+		{"invalid utf-8", "id,name\n1,Zo\xeb\n", nil, []string{"invalid-utf8"}},
+		{"bom", "\xef\xbb\xbfid,name\n1,Ann\n", nil, []string{"bom"}},
+		{"mixed line endings", "id,name\r\n1,Ann\n2,Bob\n", nil, []string{"mixed-line-endings"}},
+		{"consistent crlf", "id,name\r\n1,Ann\r\n", nil, nil},
+		{"blank line", "id,name\n\n1,Ann\n", nil, []string{"blank-line"}},
+		{"blank line in quoted field", "id,note\n1,\"a\n\nb\"\n", nil, nil},
+		{"trailing delimiter", "id,name\n1,Ann,\n", nil, []string{"field-count", "trailing-delimiter"}},
+		{"empty last column", "id,name\n1,\n", nil, nil},
+		{"unnecessary quotes off by default", "id,name\n\"1\",\"Ann\"\n", nil, nil},
+		{"unnecessary quotes", "id,name\n\"1\",Ann\n", map[string]bool{"unnecessary-quotes": true}, []string{"unnecessary-quotes"}},
+		{"inconsistent quotes", "id,name\n1,\"Ann\"\n2,Bob\n", nil, []string{"inconsistent-quotes"}},
+		{"needed quotes are consistent", "id,name\n1,Ann\n2,\"Bob, Jr\"\n", nil, nil},
+		{"control character", "id,name\n1,A\x01nn\n", nil, []string{"control-character"}},
+		{"disabled rule", "id,name\n1, Ann\n", map[string]bool{"whitespace": false}, nil},
+		{"parse error", "id,name\n1,A\"nn\n2,Bob\n", nil, []string{"parse-error"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultOptions()
+			options.Rules = tt.rules
+
+			errs, _, err := ValidateReader(strings.NewReader(tt.input), options)
+			if err != nil {
+				t.Fatalf("ValidateReader() unexpected error = %v", err)
+			}
+
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateReader() rules = %v, want %v (%v)", got, tt.want, errs)
+			}
+		})
+	}
+}
+
+func TestUnterminatedQuote(t *testing.T) {
+	input := "id,note\n1,ok\n2,\"never closed\n3,x\n4,y\n"
+	errs, hasErrors, err := ValidateReader(strings.NewReader(input), DefaultOptions())
+	if err != nil {
+		t.Fatalf("ValidateReader() unexpected error = %v", err)
+	}
+	if !hasErrors || len(errs) != 1 {
+		t.Fatalf("ValidateReader() = %v, want one parse error", errs)
+	}
+	got := errs[0]
+	if got.Rule != "parse-error" || got.Line != 3 || got.Column != 3 || got.Value != `2,"never closed` || !errors.Is(got, ErrUnterminatedQuote) {
+		t.Errorf("unterminated quote reported as %+v, want line 3 column 3", got)
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(filename, []byte("id;name\n1;Ann;x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, _, err := ValidateFile(filename, ';', false)
+	if err != nil {
+		t.Fatalf("ValidateFile() unexpected error = %v", err)
+	}
+	if len(errs) != 1 || errs[0].Rule != "field-count" {
+		t.Errorf("ValidateFile() = %v, want one field-count problem", errs)
+	}
+}
+
+func TestRuleSeverity(t *testing.T) {
+	errs, hasErrors, err := ValidateReader(strings.NewReader("id,id\n1,2\n"), DefaultOptions())
+	if err != nil {
+		t.Fatalf("ValidateReader() unexpected error = %v", err)
+	}
+	if !hasErrors || len(errs) != 1 || errs[0].Severity != SeverityError {
+		t.Fatalf("ValidateReader() = %v, %v, want one error", errs, hasErrors)
+	}
+	if !errors.Is(errs[0], ErrDuplicateHeader) {
+		t.Errorf("errors.Is(%v, ErrDuplicateHeader) = false", errs[0])
+	}
+	if errs[0].Line != 1 || errs[0].Column != 4 {
+		t.Errorf("position = %d:%d, want 1:4", errs[0].Line, errs[0].Column)
+	}
+}
+
+func TestLookupRule(t *testing.T) {
+	if _, err := LookupRule("whitespace"); err != nil {
+		t.Errorf("LookupRule(whitespace) error = %v", err)
+	}
+	if _, err := LookupRule("nope"); err == nil {
+		t.Error("LookupRule(nope) error = nil, want error")
+	}
+}
@@ -0,0 +1,86 @@
+package csvparser
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	magicGzip  = []byte{0x1F, 0x8B}
+	magicBzip2 = []byte("BZh")
+	magicZstd  = []byte{0x28, 0xB5, 0x2F, 0xFD}
+)
+
+var compressionExtensions = map[string]string{
+	".gz":   "gzip",
+	".gzip": "gzip",
+	".bz2":  "bzip2",
+	".zst":  "zstd",
+	".zstd": "zstd",
+}
+
+func Compression(filename string) string {
+	return compressionExtensions[strings.ToLower(filepath.Ext(filename))]
+}
+
+func TrimCompression(filename string) string {
+	if Compression(filename) == "" {
+		return filename
+	}
+	return strings.TrimSuffix(filename, filepath.Ext(filename))
+}
+
+func DetectCompression(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, magicGzip):
+		return "gzip"
+	case bytes.HasPrefix(sample, magicBzip2):
+		return "bzip2"
+	case bytes.HasPrefix(sample, magicZstd):
+		return "zstd"
+	}
+	return ""
+}
+
+func Decompress(reader io.Reader) (io.Reader, io.Closer, error) {
+	br := bufio.NewReader(reader)
+	magic, _ := br.Peek(len(magicZstd))
+
+	switch DetectCompression(magic) {
+	case "gzip":
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read gzip stream: %w", err)
+		}
+		return zr, zr, nil
+	case "bzip2":
+		return bzip2.NewReader(br), nil, nil
+	case "zstd":
+		zr, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read zstd stream: %w", err)
+		}
+		return zr, zr.IOReadCloser(), nil
+	}
+	return br, nil, nil
+}
+
+func CompressWriter(writer io.Writer, filename string) (io.WriteCloser, error) {
+	switch Compression(filename) {
+	case "gzip":
+		return gzip.NewWriter(writer), nil
+	case "zstd":
+		return zstd.NewWriter(writer)
+	case "bzip2":
+		return nil, fmt.Errorf("writing bzip2 is not supported, use .gz or .zst instead")
+	}
+	return nopWriteCloser{writer}, nil
+}
@@ -0,0 +1,100 @@
+package csvparser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var bzip2Sample = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x43, 0x10,
+	0x1f, 0x9e, 0x00, 0x00, 0x04, 0xdd, 0x00, 0x00, 0x10, 0x00, 0x04, 0x20,
+	0x00, 0x20, 0x00, 0x26, 0x23, 0x20, 0x00, 0x22, 0x03, 0xd4, 0x1a, 0x10,
+	0x03, 0x0c, 0x46, 0x96, 0x72, 0x11, 0x6b, 0xc5, 0xdc, 0x91, 0x4e, 0x14,
+	0x24, 0x10, 0xc4, 0x07, 0xe7, 0x80,
+}
+
+func TestParseCompressed(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("id,name\n1,Ann\n"))
+	zw.Close()
+
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"plain", []byte("id,name\n1,Ann\n")},
+		{"gzip", gz.Bytes()},
+		{"bzip2", bzip2Sample},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csv, err := Parse(bytes.NewReader(tt.input), DefaultConfig())
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(csv.Header, []string{"id", "name"}) {
+				t.Errorf("Header = %v, want [id name]", csv.Header)
+			}
+			if !reflect.DeepEqual(csv.Records, [][]string{{"1", "Ann"}}) {
+				t.Errorf("Records = %v, want [[1 Ann]]", csv.Records)
+			}
+		})
+	}
+}
+
+func TestWriteToFileCompressed(t *testing.T) {
+	original := &CSV{Header: []string{"id", "name"}, Records: [][]string{{"1", "Ann"}, {"2", "Bob"}}}
+
+	for _, name := range []string{"data.csv.gz", "data.csv.zst", "data.csv"} {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), name)
+			config := DefaultConfig()
+			config.Sniff = true
+			if err := original.WriteToFile(filename, config); err != nil {
+				t.Fatalf("WriteToFile() error = %v", err)
+			}
+
+			got, err := ParseFile(filename, config)
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Header, original.Header) || !reflect.DeepEqual(got.Records, original.Records) {
+				t.Errorf("round trip = %v %v, want %v %v", got.Header, got.Records, original.Header, original.Records)
+			}
+		})
+	}
+}
+
+func TestWriteToFileBzip2(t *testing.T) {
+	csv := &CSV{Header: []string{"id"}}
+	if err := csv.WriteToFile(filepath.Join(t.TempDir(), "data.csv.bz2"), DefaultConfig()); err == nil {
+		t.Error("WriteToFile() error = nil, want unsupported bzip2 error")
+	}
+}
+
+func TestCompression(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+		trimmed  string
+	}{
+		{"data.csv.gz", "gzip", "data.csv"},
+		{"data.tsv.BZ2", "bzip2", "data.tsv"},
+		{"data.csv.zst", "zstd", "data.csv"},
+		{"data.csv", "", "data.csv"},
+	}
+
+	for _, tt := range tests {
+		if got := Compression(tt.filename); got != tt.want {
+			t.Errorf("Compression(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+		if got := TrimCompression(tt.filename); got != tt.trimmed {
+			t.Errorf("TrimCompression(%q) = %q, want %q", tt.filename, got, tt.trimmed)
+		}
+	}
+}
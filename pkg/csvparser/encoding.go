@@ -0,0 +1,273 @@
+package csvparser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+const encodingSampleSize = 4 << 10
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+func LookupEncoding(name string) (encoding.Encoding, string, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, "_", "-")) {
+	case "", "utf-8", "utf8":
+		return unicode.UTF8, "utf-8", nil
+	case "utf-16le", "utf16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), "utf-16le", nil
+	case "utf-16be", "utf16be":
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), "utf-16be", nil
+	case "utf-16", "utf16":
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), "utf-16", nil
+	case "windows-1252", "cp1252":
+		return charmap.Windows1252, "windows-1252", nil
+	case "latin1", "latin-1", "iso-8859-1", "iso8859-1":
+		return charmap.ISO8859_1, "iso-8859-1", nil
+	}
+
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("unknown encoding %q", name)
+	}
+	canonical, _ := htmlindex.Name(enc)
+	return enc, canonical, nil
+}
+
+func DetectEncoding(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, bomUTF8):
+		return "utf-8"
+	case bytes.HasPrefix(sample, bomUTF16LE):
+		return "utf-16le"
+	case bytes.HasPrefix(sample, bomUTF16BE):
+		return "utf-16be"
+	}
+
+	if len(sample) >= 2 {
+		evenZeros, oddZeros := 0, 0
+		for i := 0; i+1 < len(sample); i += 2 {
+			if sample[i] == 0 {
+				evenZeros++
+			}
+			if sample[i+1] == 0 {
+				oddZeros++
+			}
+		}
+		pairs := len(sample) / 2
+		switch {
+		case oddZeros > pairs/2 && evenZeros < pairs/10:
+			return "utf-16le"
+		case evenZeros > pairs/2 && oddZeros < pairs/10:
+			return "utf-16be"
+		}
+	}
+
+	if utf8.Valid(sample) {
+		return "utf-8"
+	}
+	for i := len(sample) - 1; i >= 0 && i >= len(sample)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(sample[i]) {
+			if !utf8.FullRune(sample[i:]) && utf8.Valid(sample[:i]) {
+				return "utf-8"
+			}
+			break
+		}
+	}
+	return "windows-1252"
+}
+
+func DecodeReader(reader io.Reader, name string) (io.Reader, string, error) {
+	br := bufio.NewReaderSize(reader, encodingSampleSize)
+
+	if name == "" {
+		sample, _ := br.Peek(encodingSampleSize)
+		name = DetectEncoding(sample)
+	}
+
+	enc, canonical, err := LookupEncoding(name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if strings.HasPrefix(canonical, "utf-16") {
+		bom, _ := br.Peek(2)
+		switch {
+		case bytes.Equal(bom, bomUTF16LE):
+			enc, canonical = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), "utf-16le"
+			br.Discard(2)
+		case bytes.Equal(bom, bomUTF16BE):
+			enc, canonical = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), "utf-16be"
+			br.Discard(2)
+		}
+		return transform.NewReader(br, enc.NewDecoder()), canonical, nil
+	}
+
+	if canonical == "utf-8" {
+		if bom, _ := br.Peek(len(bomUTF8)); bytes.Equal(bom, bomUTF8) {
+			br.Discard(len(bomUTF8))
+		}
+		return br, canonical, nil
+	}
+
+	return transform.NewReader(br, enc.NewDecoder()), canonical, nil
+}
+
+func EncodeWriter(writer io.Writer, name string, bom bool) (io.WriteCloser, error) {
+	enc, canonical, err := LookupEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if bom || canonical == "utf-16" {
+		var mark []byte
+		switch canonical {
+		case "utf-8":
+			mark = bomUTF8
+		case "utf-16le", "utf-16":
+			mark = bomUTF16LE
+		case "utf-16be":
+			mark = bomUTF16BE
+		default:
+			return nil, fmt.Errorf("cannot write a byte order mark for %s", canonical)
+		}
+		if _, err := writer.Write(mark); err != nil {
+			return nil, err
+		}
+	}
+
+	if canonical == "utf-8" {
+		return nopWriteCloser{writer}, nil
+	}
+	if canonical == "utf-16" {
+		enc = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	}
+	return transform.NewWriter(writer, encoding.ReplaceUnsupported(enc.NewEncoder())), nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+type closers []io.Closer
+
+func (c closers) Close() error {
+	var err error
+	for _, closer := range c {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func CreateOutput(filename, encodingName string, bom bool) (io.Writer, io.Closer, error) {
+	if filename == "" || filename == "-" {
+		out, err := EncodeWriter(os.Stdout, encodingName, bom)
+		if err != nil {
+			return nil, nil, err
+		}
+		return out, out, nil
+	}
+
+	file, err := createAtomic(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	compressed, err := CompressWriter(file, filename)
+	if err != nil {
+		file.abort()
+		return nil, nil, err
+	}
+	out, err := EncodeWriter(compressed, encodingName, bom)
+	if err != nil {
+		compressed.Close()
+		file.abort()
+		return nil, nil, err
+	}
+	return out, &atomicOutput{file: file, closers: closers{out, compressed}}, nil
+}
+
+func AbortOutput(closer io.Closer) {
+	if a, ok := closer.(interface{ Abort() }); ok {
+		a.Abort()
+	} else {
+		closer.Close()
+	}
+}
+
+type atomicOutput struct {
+	file    *atomicFile
+	closers closers
+}
+
+func (o *atomicOutput) Close() error {
+	return o.file.commit(o.closers.Close())
+}
+
+func (o *atomicOutput) Abort() {
+	o.closers.Close()
+	o.file.abort()
+}
+
+type atomicFile struct {
+	*os.File
+	filename string
+}
+
+func createAtomic(filename string) (*atomicFile, error) {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filename), ".csvtk-out-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	if err := file.Chmod(mode); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	return &atomicFile{File: file, filename: filename}, nil
+}
+
+func (f *atomicFile) abort() {
+	f.File.Close()
+	os.Remove(f.Name())
+}
+
+func (f *atomicFile) commit(err error) error {
+	if cerr := f.File.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), f.filename); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package csvparser
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func utf16Bytes(s string, bigEndian bool) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			b = append(b, byte(u>>8), byte(u))
+		} else {
+			b = append(b, byte(u), byte(u>>8))
+		}
+	}
+	return b
+}
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"ascii", []byte("id,name\n1,Ann\n"), "utf-8"},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, "id\n"...), "utf-8"},
+		{"utf-8 multibyte", []byte("name\nZoë\n"), "utf-8"},
+		{"utf-8 truncated rune", []byte("name\nZo\xc3"), "utf-8"},
+		{"utf-16le bom", append([]byte{0xFF, 0xFE}, utf16Bytes("id\n", false)...), "utf-16le"},
+		{"utf-16be bom", append([]byte{0xFE, 0xFF}, utf16Bytes("id\n", true)...), "utf-16be"},
+		{"utf-16le without bom", utf16Bytes("id,name\n1,Ann\n", false), "utf-16le"},
+		{"utf-16be without bom", utf16Bytes("id,name\n1,Ann\n", true), "utf-16be"},
+		{"windows-1252", []byte("name\nZo\xeb\n"), "windows-1252"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectEncoding(tt.input); got != tt.want {
+				t.Errorf("DetectEncoding() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewReaderEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		encoding string
+		want     string
+	}{
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, "id,name\n1,Zoë\n"...), "", "utf-8"},
+		{"utf-16le bom", append([]byte{0xFF, 0xFE}, utf16Bytes("id,name\n1,Zoë\n", false)...), "", "utf-16le"},
+		{"utf-16be bom", append([]byte{0xFE, 0xFF}, utf16Bytes("id,name\n1,Zoë\n", true)...), "", "utf-16be"},
+		{"utf-16le without bom", utf16Bytes("id,name\n1,Zoë\n", false), "", "utf-16le"},
+		{"windows-1252", []byte("id,name\n1,Zo\xeb\n"), "", "windows-1252"},
+		{"explicit latin1", []byte("id,name\n1,Zo\xeb\n"), "latin1", "iso-8859-1"},
+		{"explicit utf-16 with bom", append([]byte{0xFE, 0xFF}, utf16Bytes("id,name\n1,Zoë\n", true)...), "utf-16", "utf-16be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Encoding = tt.encoding
+
+			r, err := NewReader(bytes.NewReader(tt.input), config)
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+			if r.Encoding() != tt.want {
+				t.Errorf("Encoding() = %q, want %q", r.Encoding(), tt.want)
+			}
+			csv, err := ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if idx, err := csv.GetColumnIndex("id"); err != nil || idx != 0 {
+				t.Errorf("GetColumnIndex(\"id\") = %d, %v, want 0", idx, err)
+			}
+			if want := [][]string{{"1", "Zoë"}}; !reflect.DeepEqual(csv.Records, want) {
+				t.Errorf("Records = %q, want %q", csv.Records, want)
+			}
+		})
+	}
+}
+
+func TestNewReaderUnknownEncoding(t *testing.T) {
+	config := DefaultConfig()
+	config.Encoding = "klingon"
+	if _, err := NewReader(strings.NewReader("a\n1\n"), config); err == nil {
+		t.Error("NewReader() error = nil, want unknown encoding error")
+	}
+}
+
+func TestEncodeWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		encoding string
+		bom      bool
+		want     []byte
+	}{
+		{"utf-8", "Zoë\n", "utf-8", false, []byte("Zoë\n")},
+		{"utf-8 bom", "Zoë\n", "utf-8", true, append([]byte{0xEF, 0xBB, 0xBF}, "Zoë\n"...)},
+		{"utf-16le", "Zoë\n", "utf-16le", false, utf16Bytes("Zoë\n", false)},
+		{"utf-16be bom", "Zoë\n", "utf-16be", true, append([]byte{0xFE, 0xFF}, utf16Bytes("Zoë\n", true)...)},
+		{"utf-16 always writes bom", "Zoë\n", "utf-16", false, append([]byte{0xFF, 0xFE}, utf16Bytes("Zoë\n", false)...)},
+		{"windows-1252", "Zoë\n", "windows-1252", false, []byte("Zo\xeb\n")},
+		{"unsupported characters", "€\n", "latin1", false, []byte("\x1a\n")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := EncodeWriter(&buf, tt.encoding, tt.bom)
+			if err != nil {
+				t.Fatalf("EncodeWriter() error = %v", err)
+			}
+			if _, err := w.Write([]byte(tt.input)); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Errorf("output = % x, want % x", buf.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	original := &CSV{Header: []string{"id", "name"}, Records: [][]string{{"1", "Zoë"}, {"2", "Łukasz"}}}
+
+	var buf bytes.Buffer
+	out, err := EncodeWriter(&buf, "utf-16", false)
+	if err != nil {
+		t.Fatalf("EncodeWriter() error = %v", err)
+	}
+	if err := original.Write(out, DefaultConfig()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out.Close()
+
+	got, err := Parse(&buf, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Header, original.Header) || !reflect.DeepEqual(got.Records, original.Records) {
+		t.Errorf("round trip = %v %v, want %v %v", got.Header, got.Records, original.Header, original.Records)
+	}
+}
@@ -1,27 +1,11 @@
 package csvparser
 
-import (
-	"fmt"
-	"io"
-	"os"
-)
-
 func ParseFromFileOrStdin(filename string, config *Config) (*CSV, error) {
-	if config == nil {
-		config = DefaultConfig()
-	}
-
-	var reader io.Reader
-	if filename == "" || filename == "-" {
-		reader = os.Stdin
-	} else {
-		file, err := os.Open(filename)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open file: %w", err)
-		}
-		defer file.Close()
-		return Parse(file, config)
+	r, err := OpenFileOrStdin(filename, config)
+	if err != nil {
+		return nil, err
 	}
+	defer r.Close()
 
-	return Parse(reader, config)
+	return ReadAll(r)
 }
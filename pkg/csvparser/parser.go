@@ -1,10 +1,8 @@
 package csvparser
 
 import (
-	"encoding/csv"
 	"fmt"
 	"io"
-	"os"
 )
 
 type CSV struct {
@@ -14,14 +12,25 @@ type CSV struct {
 
 type Config struct {
 	Delimiter  rune
+	Quote      rune
 	LazyQuotes bool
 	TrimSpace  bool
 	SkipHeader bool
+	NoHeader   bool
+	Sheet      string
+	Sniff      bool
+
+	FieldsPerRecord int
+
+	Encoding       string
+	OutputEncoding string
+	BOM            bool
 }
 
 func DefaultConfig() *Config {
 	return &Config{
 		Delimiter:  ',',
+		Quote:      '"',
 		LazyQuotes: false,
 		TrimSpace:  false,
 		SkipHeader: false,
@@ -29,52 +38,36 @@ func DefaultConfig() *Config {
 }
 
 func ParseFile(filename string, config *Config) (*CSV, error) {
-	if config == nil {
-		config = DefaultConfig()
-	}
-
-	file, err := os.Open(filename)
+	r, err := OpenFile(filename, config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
+	defer r.Close()
 
-	return Parse(file, config)
+	return ReadAll(r)
 }
 
 func Parse(reader io.Reader, config *Config) (*CSV, error) {
-	if config == nil {
-		config = DefaultConfig()
-	}
-
-	r := csv.NewReader(reader)
-	r.Comma = config.Delimiter
-	r.LazyQuotes = config.LazyQuotes
-	r.TrimLeadingSpace = config.TrimSpace
-
-	records, err := r.ReadAll()
+	r, err := NewReader(reader, config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, err
 	}
 
-	if len(records) == 0 {
-		return &CSV{
-			Header:  []string{},
-			Records: [][]string{},
-		}, nil
+	return ReadAll(r)
+}
+
+func ReadAll(r *Reader) (*CSV, error) {
+	csvData := &CSV{
+		Header:  r.Header(),
+		Records: [][]string{},
 	}
 
-	csvData := &CSV{}
-	if config.SkipHeader {
-		csvData.Header = []string{}
-		csvData.Records = records
-	} else {
-		csvData.Header = records[0]
-		if len(records) > 1 {
-			csvData.Records = records[1:]
-		} else {
-			csvData.Records = [][]string{}
-		}
+	err := r.ForEach(func(record []string) error {
+		csvData.Records = append(csvData.Records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return csvData, nil
@@ -104,39 +97,36 @@ func (c *CSV) GetColumnIndex(columnName string) (int, error) {
 }
 
 func (c *CSV) WriteToFile(filename string, config *Config) error {
-	if config == nil {
-		config = DefaultConfig()
-	}
-
-	file, err := os.Create(filename)
+	w, err := CreateFile(filename, config)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return err
 	}
-	defer file.Close()
 
-	return c.Write(file, config)
+	if err := c.WriteAll(w); err != nil {
+		w.Abort()
+		return err
+	}
+	return w.Close()
 }
 
 func (c *CSV) Write(writer io.Writer, config *Config) error {
-	if config == nil {
-		config = DefaultConfig()
+	w := NewWriter(writer, config)
+	if err := c.WriteAll(w); err != nil {
+		return err
 	}
+	return w.Flush()
+}
 
-	w := csv.NewWriter(writer)
-	w.Comma = config.Delimiter
-
-	if len(c.Header) > 0 {
-		if err := w.Write(c.Header); err != nil {
-			return fmt.Errorf("failed to write header: %w", err)
-		}
+func (c *CSV) WriteAll(w *Writer) error {
+	if err := w.WriteHeader(c.Header); err != nil {
+		return err
 	}
 
 	for _, record := range c.Records {
 		if err := w.Write(record); err != nil {
-			return fmt.Errorf("failed to write record: %w", err)
+			return err
 		}
 	}
 
-	w.Flush()
-	return w.Error()
+	return nil
 }
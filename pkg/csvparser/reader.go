@@ -0,0 +1,222 @@
+package csvparser
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+type Decoder interface {
+	Read() ([]string, error)
+}
+
+type Reader struct {
+	r         Decoder
+	header    []string
+	pending   []string
+	delimiter rune
+	encoding  string
+	closer    io.Closer
+	count     int
+}
+
+func NewReader(reader io.Reader, config *Config) (*Reader, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	reader, decompressor, err := Decompress(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newReader(reader, config)
+	if err != nil {
+		if decompressor != nil {
+			decompressor.Close()
+		}
+		return nil, err
+	}
+	r.closer = decompressor
+	return r, nil
+}
+
+func newReader(reader io.Reader, config *Config) (*Reader, error) {
+	reader, encoding, err := DecodeReader(reader, config.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Sniff {
+		br := bufio.NewReaderSize(reader, SniffSampleSize)
+		sample, _ := br.Peek(SniffSampleSize)
+		result := Sniff(sample)
+		reader = br
+
+		detected := *config
+		detected.Quote = result.Quote
+		if result.DelimiterConfidence > 0 {
+			detected.Delimiter = result.Delimiter
+		}
+		config = &detected
+	}
+
+	quote := byte('"')
+	if config.Quote != 0 && config.Quote != '"' {
+		quote = byte(config.Quote)
+		reader = &quoteSwapReader{r: reader, quote: quote}
+	}
+
+	r := csv.NewReader(reader)
+	r.Comma = config.Delimiter
+	r.LazyQuotes = config.LazyQuotes
+	r.FieldsPerRecord = config.FieldsPerRecord
+	r.TrimLeadingSpace = config.TrimSpace
+
+	var dec Decoder = r
+	if quote != '"' {
+		dec = &quoteDecoder{r: r, quote: quote}
+	}
+
+	cr, err := NewDecoderReader(dec, config)
+	if err != nil {
+		return nil, err
+	}
+	cr.encoding = encoding
+	return cr, nil
+}
+
+func NewDecoderReader(dec Decoder, config *Config) (*Reader, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	cr := &Reader{
+		r:         dec,
+		header:    []string{},
+		delimiter: config.Delimiter,
+	}
+
+	if !config.SkipHeader {
+		header, err := dec.Read()
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if header != nil && config.NoHeader {
+			cr.pending = header
+			header = make([]string, len(header))
+			for i := range header {
+				header[i] = "column" + strconv.Itoa(i+1)
+			}
+		}
+		if header != nil {
+			cr.header = header
+		}
+	}
+
+	return cr, nil
+}
+
+func OpenFile(filename string, config *Config) (*Reader, error) {
+	if IsXLSX(filename) {
+		return OpenXLSX(filename, config)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	r, err := NewReader(file, config)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if r.closer != nil {
+		r.closer = closers{r.closer, file}
+	} else {
+		r.closer = file
+	}
+	return r, nil
+}
+
+func OpenFileOrStdin(filename string, config *Config) (*Reader, error) {
+	if filename == "" || filename == "-" {
+		return NewReader(os.Stdin, config)
+	}
+	return OpenFile(filename, config)
+}
+
+func (r *Reader) Header() []string {
+	return r.header
+}
+
+func (r *Reader) Delimiter() rune {
+	return r.delimiter
+}
+
+func (r *Reader) Encoding() string {
+	return r.encoding
+}
+
+func (r *Reader) GetColumnIndex(columnName string) (int, error) {
+	for i, name := range r.header {
+		if name == columnName {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("column %q not found", columnName)
+}
+
+func (r *Reader) Next() ([]string, error) {
+	if record := r.pending; record != nil {
+		r.pending = nil
+		r.count++
+		return record, nil
+	}
+	record, err := r.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	r.count++
+	return record, nil
+}
+
+func (r *Reader) ForEach(fn func(record []string) error) error {
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *Reader) FieldPos(field int) (line, column int) {
+	if p, ok := r.r.(interface{ FieldPos(int) (int, int) }); ok {
+		return p.FieldPos(field)
+	}
+	return 0, 0
+}
+
+func (r *Reader) RecordNum() int {
+	return r.count
+}
+
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
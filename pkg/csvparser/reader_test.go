@@ -0,0 +1,170 @@
+package csvparser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReaderNext(t *testing.T) {
+	r, err := NewReader(strings.NewReader("Name,Age\nJohn,30\nJane,25"), DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	if len(r.Header()) != 2 || r.Header()[0] != "Name" {
+		t.Errorf("Header() = %v, want [Name Age]", r.Header())
+	}
+
+	var names []string
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		names = append(names, record[0])
+	}
+
+	if len(names) != 2 || names[0] != "John" || names[1] != "Jane" {
+		t.Errorf("Next() records = %v, want [John Jane]", names)
+	}
+	if r.RecordNum() != 2 {
+		t.Errorf("RecordNum() = %d, want 2", r.RecordNum())
+	}
+}
+
+func TestReaderSkipHeader(t *testing.T) {
+	config := DefaultConfig()
+	config.SkipHeader = true
+
+	r, err := NewReader(strings.NewReader("John,30\nJane,25"), config)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	if len(r.Header()) != 0 {
+		t.Errorf("Header() = %v, want empty", r.Header())
+	}
+
+	rows := 0
+	if err := r.ForEach(func(record []string) error {
+		rows++
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEach() error = %v", err)
+	}
+	if rows != 2 {
+		t.Errorf("ForEach() visited %d rows, want 2", rows)
+	}
+}
+
+func TestReaderEmpty(t *testing.T) {
+	r, err := NewReader(strings.NewReader(""), DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	if len(r.Header()) != 0 {
+		t.Errorf("Header() = %v, want empty", r.Header())
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() error = %v, want io.EOF", err)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf strings.Builder
+	config := DefaultConfig()
+	config.Delimiter = '\t'
+
+	w := NewWriter(&buf, config)
+	if err := w.WriteHeader([]string{"Name", "Age"}); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	if err := w.Write([]string{"John", "30"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	expected := "Name\tAge\nJohn\t30\n"
+	if buf.String() != expected {
+		t.Errorf("Writer output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestWriteToInputFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "data.csv")
+	var want strings.Builder
+	want.WriteString("ID,Name\n")
+	for i := range 20000 {
+		fmt.Fprintf(&want, "%d,name%d\n", i, i)
+	}
+	if err := os.WriteFile(filename, []byte(want.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenFile(filename, DefaultConfig())
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	defer r.Close()
+	w, err := CreateFile(filename, DefaultConfig())
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	if err := w.WriteHeader(r.Header()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ForEach(w.Write); err != nil {
+		t.Fatalf("ForEach() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want.String() {
+		t.Errorf("rewritten file has %d bytes, want %d", len(got), want.Len())
+	}
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(filename)); len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the output file", len(entries))
+	}
+}
+
+func TestWriterAbort(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(filename, []byte("a,b\n1,2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := CreateFile(filename, DefaultConfig())
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	if err := w.WriteHeader([]string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	w.Abort()
+
+	got, _ := os.ReadFile(filename)
+	if string(got) != "a,b\n1,2\n" {
+		t.Errorf("file after Abort() = %q, want it unchanged", got)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(filename)); len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the original file", len(entries))
+	}
+}
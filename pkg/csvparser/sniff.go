@@ -0,0 +1,355 @@
+package csvparser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SniffSampleSize  = 64 << 10
+	minQuoteEvidence = 4
+)
+
+var (
+	SniffDelimiters = []rune{',', '\t', ';', '|', ':'}
+	SniffQuotes     = []rune{'"', '\''}
+)
+
+type SniffResult struct {
+	Delimiter           rune
+	Quote               rune
+	HasHeader           bool
+	Columns             int
+	Rows                int
+	DelimiterConfidence float64
+	QuoteConfidence     float64
+	HeaderConfidence    float64
+}
+
+func Sniff(sample []byte) SniffResult {
+	if i := bytes.LastIndexByte(sample, '\n'); i >= 0 && i < len(sample)-1 {
+		sample = sample[:i+1]
+	}
+
+	result := SniffResult{Delimiter: ',', Quote: '"'}
+	result.Quote, result.QuoteConfidence = sniffQuote(sample)
+
+	best, second := 0.0, 0.0
+	var bestRows [][]string
+	for _, delimiter := range SniffDelimiters {
+		rows := sniffRows(sample, delimiter, result.Quote)
+		score := delimiterScore(rows)
+		switch {
+		case score > best:
+			second = best
+			best = score
+			result.Delimiter = delimiter
+			bestRows = rows
+		case score > second:
+			second = score
+		}
+	}
+
+	if bestRows == nil {
+		bestRows = sniffRows(sample, result.Delimiter, result.Quote)
+	} else {
+		result.DelimiterConfidence = (best - second/2) * min(1, float64(len(bestRows))/3)
+	}
+
+	result.Rows = len(bestRows)
+	if len(bestRows) > 0 {
+		result.Columns = modalFieldCount(bestRows)
+	}
+	result.HasHeader, result.HeaderConfidence = sniffHeader(bestRows)
+	return result
+}
+
+func SniffReader(reader io.Reader) (SniffResult, error) {
+	reader, decompressor, err := Decompress(reader)
+	if err != nil {
+		return SniffResult{}, err
+	}
+	if decompressor != nil {
+		defer decompressor.Close()
+	}
+
+	sample, err := io.ReadAll(io.LimitReader(reader, SniffSampleSize))
+	if err != nil {
+		return SniffResult{}, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return Sniff(sample), nil
+}
+
+func SniffFile(filename string) (SniffResult, error) {
+	if filename == "" || filename == "-" {
+		return SniffReader(os.Stdin)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return SniffResult{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return SniffReader(file)
+}
+
+func sniffQuote(sample []byte) (rune, float64) {
+	counts := make([]int, len(SniffQuotes))
+	total := 0
+	for i, quote := range SniffQuotes {
+		q := byte(quote)
+		for j, c := range sample {
+			if c != q {
+				continue
+			}
+			opens := j == 0 || isBoundary(sample[j-1])
+			closes := j == len(sample)-1 || isBoundary(sample[j+1])
+			if opens != closes {
+				counts[i]++
+			}
+		}
+		total += counts[i]
+	}
+
+	if total == 0 {
+		return '"', 0
+	}
+
+	best := 0
+	for i, count := range counts {
+		if count > counts[best] {
+			best = i
+		}
+	}
+	if best != 0 && counts[best] < minQuoteEvidence {
+		return '"', 0
+	}
+	return SniffQuotes[best], float64(counts[best]) / float64(total)
+}
+
+func isBoundary(c byte) bool {
+	if c == '\n' || c == '\r' {
+		return true
+	}
+	for _, d := range SniffDelimiters {
+		if rune(c) == d {
+			return true
+		}
+	}
+	return false
+}
+
+func sniffRows(sample []byte, delimiter, quote rune) [][]string {
+	var input io.Reader = bytes.NewReader(sample)
+	if quote != '"' {
+		input = &quoteSwapReader{r: input, quote: byte(quote)}
+	}
+
+	r := csv.NewReader(input)
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var rows [][]string
+	for {
+		record, err := r.Read()
+		if err != nil {
+			break
+		}
+		if quote != '"' {
+			swapQuotes(record, byte(quote))
+		}
+		rows = append(rows, record)
+	}
+	return rows
+}
+
+func modalFieldCount(rows [][]string) int {
+	counts := make(map[int]int)
+	mode := 0
+	for _, row := range rows {
+		counts[len(row)]++
+		if counts[len(row)] > counts[mode] || (counts[len(row)] == counts[mode] && len(row) > mode) {
+			mode = len(row)
+		}
+	}
+	return mode
+}
+
+func delimiterScore(rows [][]string) float64 {
+	if len(rows) == 0 {
+		return 0
+	}
+	mode := modalFieldCount(rows)
+	if mode < 2 || len(rows[0]) != mode {
+		return 0
+	}
+
+	matching := 0
+	for _, row := range rows {
+		if len(row) == mode {
+			matching++
+		}
+	}
+	return float64(matching) / float64(len(rows))
+}
+
+type sniffKind int
+
+const (
+	kindEmpty sniffKind = iota
+	kindNumber
+	kindDate
+	kindText
+)
+
+func classify(value string) sniffKind {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return kindEmpty
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return kindNumber
+	}
+	for _, layout := range []string{"2006-01-02", "2006/01/02", "02/01/2006", "01/02/2006", time.RFC3339, "2006-01-02 15:04:05"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return kindDate
+		}
+	}
+	return kindText
+}
+
+func sniffHeader(rows [][]string) (bool, float64) {
+	if len(rows) < 2 {
+		return true, 0
+	}
+
+	header := rows[0]
+	data := rows[1:]
+	votes, columns := 0, 0
+
+	if slices.Contains(header, "") {
+		votes--
+	}
+	seen := make(map[string]bool)
+	for _, name := range header {
+		if seen[name] {
+			votes--
+			break
+		}
+		seen[name] = true
+	}
+
+	for col, name := range header {
+		kind := kindEmpty
+		consistent := true
+		values := make(map[string]bool)
+		for _, row := range data {
+			if col >= len(row) {
+				continue
+			}
+			values[row[col]] = true
+			k := classify(row[col])
+			switch {
+			case k == kindEmpty:
+			case kind == kindEmpty:
+				kind = k
+			case kind != k:
+				consistent = false
+			}
+		}
+
+		headerKind := classify(name)
+		switch {
+		case !consistent || kind == kindEmpty:
+			continue
+		case kind != kindText:
+			columns++
+			if headerKind == kind {
+				votes--
+			} else {
+				votes++
+			}
+		case headerKind != kindText:
+			columns++
+			votes--
+		case values[name]:
+			columns++
+			votes--
+		}
+	}
+
+	if columns == 0 {
+		return votes >= 0, 0
+	}
+	confidence := float64(abs(votes)) / float64(columns)
+	return votes >= 0, min(1, confidence)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+type quoteSwapReader struct {
+	r     io.Reader
+	quote byte
+}
+
+func (q *quoteSwapReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+	for i := 0; i < n; i++ {
+		switch p[i] {
+		case q.quote:
+			p[i] = '"'
+		case '"':
+			p[i] = q.quote
+		}
+	}
+	return n, err
+}
+
+func swapQuotes(record []string, quote byte) {
+	for i, field := range record {
+		if strings.IndexByte(field, quote) < 0 && strings.IndexByte(field, '"') < 0 {
+			continue
+		}
+		b := []byte(field)
+		for j, c := range b {
+			switch c {
+			case quote:
+				b[j] = '"'
+			case '"':
+				b[j] = quote
+			}
+		}
+		record[i] = string(b)
+	}
+}
+
+type quoteDecoder struct {
+	r     *csv.Reader
+	quote byte
+}
+
+func (d *quoteDecoder) Read() ([]string, error) {
+	record, err := d.r.Read()
+	if err == nil {
+		swapQuotes(record, d.quote)
+	}
+	return record, err
+}
+
+func (d *quoteDecoder) FieldPos(field int) (line, column int) {
+	return d.r.FieldPos(field)
+}
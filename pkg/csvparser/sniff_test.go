@@ -0,0 +1,132 @@
+package csvparser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		delimiter rune
+		quote     rune
+		hasHeader bool
+		columns   int
+	}{
+		{"comma", "id,name,score\n1,Ann,3.5\n2,Bob,4\n", ',', '"', true, 3},
+		{"tab with commas in values", "city\tnote\nOslo\ta, b\nRome\tc, d, e\n", '\t', '"', true, 2},
+		{"semicolon with decimal commas", "name;price\nfoo;1,5\nbar;2\n", ';', '"', true, 2},
+		{"pipe", "a|b|c\nx|y|z\n1|2|3\n", '|', '"', true, 3},
+		{"quoted delimiters", "a,b\n\"x,y,z\",1\n\"p,q\",2\n", ',', '"', true, 2},
+		{"single quotes", "a;b\n'x;y';1\n'p;q';2\n'r';3\n's';4\n", ';', '\'', true, 2},
+		{"apostrophes are not quotes", "name,note\nAnn,'tis fine\nBob,ok\n", ',', '"', true, 2},
+		{"headerless numbers", "1,2,3\n4,5,6\n7,8,9\n", ',', '"', false, 3},
+		{"truncated last line", "a,b\n1,2\n3,", ',', '"', true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sniff([]byte(tt.input))
+			if got.Delimiter != tt.delimiter {
+				t.Errorf("Delimiter = %q, want %q", got.Delimiter, tt.delimiter)
+			}
+			if got.Quote != tt.quote {
+				t.Errorf("Quote = %q, want %q", got.Quote, tt.quote)
+			}
+			if got.HasHeader != tt.hasHeader {
+				t.Errorf("HasHeader = %v, want %v", got.HasHeader, tt.hasHeader)
+			}
+			if got.Columns != tt.columns {
+				t.Errorf("Columns = %d, want %d", got.Columns, tt.columns)
+			}
+			if got.DelimiterConfidence <= 0 || got.DelimiterConfidence > 1 {
+				t.Errorf("DelimiterConfidence = %v, want in (0, 1]", got.DelimiterConfidence)
+			}
+		})
+	}
+}
+
+func TestSniffSingleColumn(t *testing.T) {
+	for _, input := range []string{
+		"name\nAnn\nBob\n",
+		"time\n10:00:01\n10:00:02\n10:00:03\n10:00:04\n",
+		"note\nx;y\na;b\nc;d\n",
+	} {
+		got := Sniff([]byte(input))
+		if got.Delimiter != ',' || got.DelimiterConfidence != 0 {
+			t.Errorf("Sniff(%q) = %q with confidence %v, want default ',' with no confidence", input, got.Delimiter, got.DelimiterConfidence)
+		}
+	}
+}
+
+func TestReaderDelimiter(t *testing.T) {
+	config := DefaultConfig()
+	config.Sniff = true
+	r, err := NewReader(strings.NewReader("a;b\n1;2\n3;4\n"), config)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if r.Delimiter() != ';' {
+		t.Errorf("Delimiter() = %q, want ';'", r.Delimiter())
+	}
+	if config.Delimiter != ',' {
+		t.Errorf("config.Delimiter = %q, want it left unchanged", config.Delimiter)
+	}
+}
+
+func TestNewReaderSniff(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		noHeader    bool
+		wantHeader  []string
+		wantRecords [][]string
+	}{
+		{
+			name:        "semicolon",
+			input:       "a;b\n1;x\n2;y\n",
+			wantHeader:  []string{"a", "b"},
+			wantRecords: [][]string{{"1", "x"}, {"2", "y"}},
+		},
+		{
+			name:        "single quotes",
+			input:       "a|b\n'x|\"y\"'|1\n'p'|2\n'q'|3\n'r'|4\n",
+			wantHeader:  []string{"a", "b"},
+			wantRecords: [][]string{{`x|"y"`, "1"}, {"p", "2"}, {"q", "3"}, {"r", "4"}},
+		},
+		{
+			name:        "numeric header",
+			input:       "2023\t2024\n3\t4\n5\t6\n",
+			wantHeader:  []string{"2023", "2024"},
+			wantRecords: [][]string{{"3", "4"}, {"5", "6"}},
+		},
+		{
+			name:        "no header",
+			input:       "1\t2\n3\t4\n5\t6\n",
+			noHeader:    true,
+			wantHeader:  []string{"column1", "column2"},
+			wantRecords: [][]string{{"1", "2"}, {"3", "4"}, {"5", "6"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Sniff = true
+			config.NoHeader = tt.noHeader
+
+			csv, err := Parse(strings.NewReader(tt.input), config)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(csv.Header, tt.wantHeader) {
+				t.Errorf("Header = %v, want %v", csv.Header, tt.wantHeader)
+			}
+			if !reflect.DeepEqual(csv.Records, tt.wantRecords) {
+				t.Errorf("Records = %v, want %v", csv.Records, tt.wantRecords)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package csvparser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+type Encoder interface {
+	WriteHeader(header []string) error
+	Write(record []string) error
+	Flush() error
+	Close() error
+}
+
+type Writer struct {
+	enc    Encoder
+	closer io.Closer
+}
+
+func NewWriter(writer io.Writer, config *Config) *Writer {
+	return &Writer{enc: NewDelimitedEncoder(writer, config)}
+}
+
+func NewEncoderWriter(enc Encoder, closer io.Closer) *Writer {
+	return &Writer{enc: enc, closer: closer}
+}
+
+func CreateFile(filename string, config *Config) (*Writer, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	out, closer, err := CreateOutput(filename, config.OutputEncoding, config.BOM)
+	if err != nil {
+		return nil, err
+	}
+	return NewEncoderWriter(NewDelimitedEncoder(out, config), closer), nil
+}
+
+func CreateFileOrStdout(filename string, config *Config) (*Writer, error) {
+	return CreateFile(filename, config)
+}
+
+func (w *Writer) WriteHeader(header []string) error {
+	if len(header) == 0 {
+		return nil
+	}
+	if err := w.enc.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	return nil
+}
+
+func (w *Writer) Write(record []string) error {
+	if err := w.enc.Write(record); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
+
+func (w *Writer) Flush() error {
+	return w.enc.Flush()
+}
+
+func (w *Writer) Close() error {
+	err := w.enc.Close()
+	if err != nil {
+		w.Abort()
+		return err
+	}
+	if w.closer != nil {
+		if cerr := w.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func (w *Writer) Abort() {
+	if w.closer != nil {
+		AbortOutput(w.closer)
+	}
+}
+
+type delimitedEncoder struct {
+	w *csv.Writer
+}
+
+func NewDelimitedEncoder(writer io.Writer, config *Config) Encoder {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	w := csv.NewWriter(writer)
+	w.Comma = config.Delimiter
+
+	return &delimitedEncoder{w: w}
+}
+
+func (e *delimitedEncoder) WriteHeader(header []string) error {
+	return e.w.Write(header)
+}
+
+func (e *delimitedEncoder) Write(record []string) error {
+	return e.w.Write(record)
+}
+
+func (e *delimitedEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *delimitedEncoder) Close() error {
+	return e.Flush()
+}
@@ -0,0 +1,615 @@
+package csvparser
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type xlsxSheet struct {
+	Name string
+	path string
+}
+
+type xlsxWorkbook struct {
+	files    map[string]*zip.File
+	sheets   []xlsxSheet
+	strings  []string
+	styles   []dateKind
+	date1904 bool
+}
+
+type dateKind int
+
+const (
+	notDate dateKind = iota
+	dateOnly
+	timeOnly
+	dateTime
+)
+
+type sliceDecoder struct {
+	rows [][]string
+	pos  int
+}
+
+func (d *sliceDecoder) Read() ([]string, error) {
+	if d.pos >= len(d.rows) {
+		return nil, io.EOF
+	}
+	row := d.rows[d.pos]
+	d.pos++
+	return row, nil
+}
+
+func IsXLSX(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".xlsx" || ext == ".xlsm"
+}
+
+func OpenXLSX(filename string, config *Config) (*Reader, error) {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer zr.Close()
+
+	return readXLSX(&zr.Reader, config)
+}
+
+func ReadXLSX(r io.ReaderAt, size int64, config *Config) (*Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XLSX: %w", err)
+	}
+	return readXLSX(zr, config)
+}
+
+func readXLSX(zr *zip.Reader, config *Config) (*Reader, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	wb, err := openWorkbook(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, err := wb.findSheet(config.Sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := wb.readSheet(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XLSX sheet %q: %w", sheet.Name, err)
+	}
+
+	if config.TrimSpace {
+		for _, row := range rows {
+			for i, value := range row {
+				row[i] = strings.TrimLeft(value, " \t")
+			}
+		}
+	}
+
+	return NewDecoderReader(&sliceDecoder{rows: rows}, config)
+}
+
+func openWorkbook(zr *zip.Reader) (*xlsxWorkbook, error) {
+	wb := &xlsxWorkbook{files: make(map[string]*zip.File)}
+	for _, f := range zr.File {
+		wb.files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+
+	workbookPath := "xl/workbook.xml"
+	if rels, err := wb.readRels("_rels/.rels"); err == nil {
+		for _, rel := range rels {
+			if strings.HasSuffix(rel.Type, "/officeDocument") {
+				workbookPath = resolveTarget("", rel.Target)
+			}
+		}
+	}
+
+	var workbook struct {
+		Properties struct {
+			Date1904 string `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name  string     `xml:"name,attr"`
+			Attrs []xml.Attr `xml:",any,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := wb.decodeXML(workbookPath, &workbook); err != nil {
+		return nil, fmt.Errorf("failed to read XLSX workbook: %w", err)
+	}
+	wb.date1904 = workbook.Properties.Date1904 == "1" || workbook.Properties.Date1904 == "true"
+
+	dir := path.Dir(workbookPath)
+	rels, err := wb.readRels(path.Join(dir, "_rels", path.Base(workbookPath)+".rels"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XLSX workbook relationships: %w", err)
+	}
+
+	targets := make(map[string]string)
+	for _, rel := range rels {
+		targets[rel.ID] = resolveTarget(dir, rel.Target)
+		switch {
+		case strings.HasSuffix(rel.Type, "/sharedStrings"):
+			if wb.strings, err = wb.readSharedStrings(targets[rel.ID]); err != nil {
+				return nil, fmt.Errorf("failed to read XLSX shared strings: %w", err)
+			}
+		case strings.HasSuffix(rel.Type, "/styles"):
+			if wb.styles, err = wb.readStyles(targets[rel.ID]); err != nil {
+				return nil, fmt.Errorf("failed to read XLSX styles: %w", err)
+			}
+		}
+	}
+
+	for _, s := range workbook.Sheets {
+		for _, attr := range s.Attrs {
+			if attr.Name.Local == "id" && attr.Name.Space != "" {
+				wb.sheets = append(wb.sheets, xlsxSheet{Name: s.Name, path: targets[attr.Value]})
+			}
+		}
+	}
+	if len(wb.sheets) == 0 {
+		return nil, fmt.Errorf("XLSX workbook has no sheets")
+	}
+
+	return wb, nil
+}
+
+type xlsxRel struct {
+	ID     string `xml:"Id,attr"`
+	Type   string `xml:"Type,attr"`
+	Target string `xml:"Target,attr"`
+}
+
+func (wb *xlsxWorkbook) readRels(name string) ([]xlsxRel, error) {
+	var rels struct {
+		Relationships []xlsxRel `xml:"Relationship"`
+	}
+	if err := wb.decodeXML(name, &rels); err != nil {
+		return nil, err
+	}
+	return rels.Relationships, nil
+}
+
+func resolveTarget(dir, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(dir, target)
+}
+
+func (wb *xlsxWorkbook) open(name string) (io.ReadCloser, error) {
+	f, ok := wb.files[name]
+	if !ok {
+		return nil, fmt.Errorf("missing %s", name)
+	}
+	return f.Open()
+}
+
+func (wb *xlsxWorkbook) decodeXML(name string, v any) error {
+	rc, err := wb.open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+type xlsxRichText struct {
+	T    *string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt xlsxRichText) String() string {
+	if rt.T != nil {
+		return *rt.T
+	}
+	var b strings.Builder
+	for _, run := range rt.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+func (wb *xlsxWorkbook) readSharedStrings(name string) ([]string, error) {
+	rc, err := wb.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var result []string
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "si" {
+			var si xlsxRichText
+			if err := dec.DecodeElement(&si, &start); err != nil {
+				return nil, err
+			}
+			result = append(result, si.String())
+		}
+	}
+}
+
+func (wb *xlsxWorkbook) readStyles(name string) ([]dateKind, error) {
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := wb.decodeXML(name, &styles); err != nil {
+		return nil, err
+	}
+
+	custom := make(map[int]dateKind)
+	for _, f := range styles.NumFmts {
+		custom[f.ID] = formatDateKind(f.Code)
+	}
+
+	kinds := make([]dateKind, len(styles.CellXfs))
+	for i, xf := range styles.CellXfs {
+		if kind, ok := custom[xf.NumFmtID]; ok {
+			kinds[i] = kind
+		} else {
+			kinds[i] = builtinDateKind(xf.NumFmtID)
+		}
+	}
+	return kinds, nil
+}
+
+func builtinDateKind(id int) dateKind {
+	switch {
+	case id >= 14 && id <= 17, id >= 27 && id <= 31, id >= 34 && id <= 36, id >= 50 && id <= 58:
+		return dateOnly
+	case id >= 18 && id <= 21, id == 32, id == 33, id >= 45 && id <= 47:
+		return timeOnly
+	case id == 22:
+		return dateTime
+	}
+	return notDate
+}
+
+func formatDateKind(code string) dateKind {
+	var b strings.Builder
+	inQuote, inBracket := false, false
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case inQuote:
+			inQuote = c != '"'
+		case inBracket:
+			inBracket = c != ']'
+			if c == 'h' || c == 'H' || c == 's' || c == 'S' {
+				b.WriteByte('h')
+			}
+		case c == '"':
+			inQuote = true
+		case c == '[':
+			inBracket = true
+		case c == '\\' || c == '_' || c == '*':
+			i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	stripped := strings.ToLower(b.String())
+	if section, _, found := strings.Cut(stripped, ";"); found {
+		stripped = section
+	}
+
+	hasDate := strings.ContainsAny(stripped, "yd")
+	hasTime := strings.ContainsAny(stripped, "hs")
+	if !hasDate && !hasTime && strings.Contains(stripped, "m") {
+		hasDate = true
+	}
+
+	switch {
+	case hasDate && hasTime:
+		return dateTime
+	case hasDate:
+		return dateOnly
+	case hasTime:
+		return timeOnly
+	}
+	return notDate
+}
+
+func (wb *xlsxWorkbook) sheetNames() []string {
+	names := make([]string, len(wb.sheets))
+	for i, s := range wb.sheets {
+		names[i] = s.Name
+	}
+	return names
+}
+
+func (wb *xlsxWorkbook) findSheet(name string) (xlsxSheet, error) {
+	if name == "" {
+		return wb.sheets[0], nil
+	}
+	for _, s := range wb.sheets {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	for _, s := range wb.sheets {
+		if strings.EqualFold(s.Name, name) {
+			return s, nil
+		}
+	}
+	if index, err := strconv.Atoi(name); err == nil && index >= 1 && index <= len(wb.sheets) {
+		return wb.sheets[index-1], nil
+	}
+	return xlsxSheet{}, fmt.Errorf("sheet %q not found (available: %s)", name, strings.Join(wb.sheetNames(), ", "))
+}
+
+type cellRange struct {
+	firstRow, firstCol, lastRow, lastCol int
+}
+
+func (wb *xlsxWorkbook) readSheet(sheet xlsxSheet) ([][]string, error) {
+	rc, err := wb.open(sheet.path)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	cells := make(map[int][]string)
+	var merges []cellRange
+
+	dec := xml.NewDecoder(rc)
+	row, col := 0, 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "row":
+			row++
+			if r := attr(start, "r"); r != "" {
+				if n, err := strconv.Atoi(r); err == nil {
+					row = n
+				}
+			}
+			col = 0
+		case "c":
+			col++
+			if ref := attr(start, "r"); ref != "" {
+				if c, r, ok := parseCellRef(ref); ok {
+					col, row = c, r
+				}
+			}
+			value, err := wb.readCell(dec, start)
+			if err != nil {
+				return nil, err
+			}
+			if value != "" {
+				setCell(cells, row, col, value)
+			}
+		case "mergeCell":
+			if rng, ok := parseRange(attr(start, "ref")); ok {
+				merges = append(merges, rng)
+			}
+		}
+	}
+
+	for _, m := range merges {
+		value := cellValue(cells, m.firstRow, m.firstCol)
+		if value == "" {
+			continue
+		}
+		for r := m.firstRow; r <= m.lastRow; r++ {
+			for c := m.firstCol; c <= m.lastCol; c++ {
+				setCell(cells, r, c, value)
+			}
+		}
+	}
+
+	return denseRows(cells), nil
+}
+
+func (wb *xlsxWorkbook) readCell(dec *xml.Decoder, start xml.StartElement) (string, error) {
+	cellType := attr(start, "t")
+	style := -1
+	if s := attr(start, "s"); s != "" {
+		style, _ = strconv.Atoi(s)
+	}
+
+	var raw string
+	var inline *xlsxRichText
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "v":
+				if err := dec.DecodeElement(&raw, &t); err != nil {
+					return "", err
+				}
+			case "is":
+				inline = &xlsxRichText{}
+				if err := dec.DecodeElement(inline, &t); err != nil {
+					return "", err
+				}
+			default:
+				if err := dec.Skip(); err != nil {
+					return "", err
+				}
+			}
+		case xml.EndElement:
+			return wb.cellString(cellType, style, raw, inline), nil
+		}
+	}
+}
+
+func (wb *xlsxWorkbook) cellString(cellType string, style int, raw string, inline *xlsxRichText) string {
+	switch cellType {
+	case "s":
+		index, err := strconv.Atoi(raw)
+		if err != nil || index < 0 || index >= len(wb.strings) {
+			return ""
+		}
+		return wb.strings[index]
+	case "inlineStr":
+		if inline != nil {
+			return inline.String()
+		}
+		return raw
+	case "b":
+		if raw == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	case "str", "e", "d":
+		return raw
+	}
+
+	if raw == "" || style < 0 || style >= len(wb.styles) || wb.styles[style] == notDate {
+		return raw
+	}
+	serial, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return raw
+	}
+	return formatExcelDate(serial, wb.styles[style], wb.date1904)
+}
+
+func formatExcelDate(serial float64, kind dateKind, date1904 bool) string {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	} else if serial < 60 {
+		serial++
+	}
+
+	t := base.Add(time.Duration(math.Round(serial*86400)) * time.Second)
+	switch kind {
+	case dateOnly:
+		return t.Format("2006-01-02")
+	case timeOnly:
+		return t.Format("15:04:05")
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func attr(start xml.StartElement, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func parseCellRef(ref string) (col, row int, ok bool) {
+	i := 0
+	for i < len(ref) {
+		c := ref[i] &^ 0x20
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+		i++
+	}
+	if i == 0 {
+		return 0, 0, false
+	}
+	row, err := strconv.Atoi(strings.ReplaceAll(ref[i:], "$", ""))
+	if err != nil {
+		return 0, 0, false
+	}
+	return col, row, true
+}
+
+func parseRange(ref string) (cellRange, bool) {
+	first, last, _ := strings.Cut(strings.ReplaceAll(ref, "$", ""), ":")
+	if last == "" {
+		last = first
+	}
+	c1, r1, ok1 := parseCellRef(first)
+	c2, r2, ok2 := parseCellRef(last)
+	if !ok1 || !ok2 {
+		return cellRange{}, false
+	}
+	return cellRange{firstRow: r1, firstCol: c1, lastRow: r2, lastCol: c2}, true
+}
+
+func cellValue(cells map[int][]string, row, col int) string {
+	r := cells[row]
+	if col-1 < len(r) {
+		return r[col-1]
+	}
+	return ""
+}
+
+func setCell(cells map[int][]string, row, col int, value string) {
+	r := cells[row]
+	for len(r) < col {
+		r = append(r, "")
+	}
+	r[col-1] = value
+	cells[row] = r
+}
+
+func denseRows(cells map[int][]string) [][]string {
+	firstRow, lastRow, width := 0, 0, 0
+	for row, values := range cells {
+		if firstRow == 0 || row < firstRow {
+			firstRow = row
+		}
+		lastRow = max(lastRow, row)
+		width = max(width, len(values))
+	}
+	if firstRow == 0 {
+		return nil
+	}
+
+	rows := make([][]string, 0, lastRow-firstRow+1)
+	for row := firstRow; row <= lastRow; row++ {
+		record := make([]string, width)
+		copy(record, cells[row])
+		rows = append(rows, record)
+	}
+	return rows
+}
@@ -0,0 +1,165 @@
+package csvparser
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func buildXLSX(t *testing.T, parts map[string]string) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func testWorkbook(t *testing.T, date1904 bool) *bytes.Reader {
+	workbookPr := ""
+	if date1904 {
+		workbookPr = `<workbookPr date1904="1"/>`
+	}
+
+	return buildXLSX(t, map[string]string{
+		"_rels/.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`,
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` + workbookPr + `
+<sheets><sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="Orders" sheetId="2" r:id="rId2"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/>
+<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>Region</t></si>
+<si><t>Sales</t></si>
+<si><r><t>No</t></r><r><rPr><b/></rPr><t>rth</t></r><rPh><t>ignored</t></rPh></si>
+<si><t>When</t></si>
+</sst>`,
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd\ hh:mm"/><numFmt numFmtId="165" formatCode="&quot;Qty&quot;\ 0"/></numFmts>
+<cellStyleXfs><xf numFmtId="14"/></cellStyleXfs>
+<cellXfs><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="164"/><xf numFmtId="165"/></cellXfs>
+</styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="2"><c r="A2" t="s"><v>0</v></c><c r="B2" t="s"><v>1</v></c><c r="D2" t="s"><v>3</v></c></row>
+<row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3"><v>1250.5</v></c><c r="C3" s="3"><v>7</v></c><c r="D3" s="1"><v>45322</v></c></row>
+<row r="4"><c r="A4" t="inlineStr"><is><t>South</t></is></c><c r="B4" t="b"><v>1</v></c><c r="D4" s="2"><v>45322.75</v></c></row>
+<row r="6"><c r="A6" t="str"><f>A4</f><v>South</v></c><c r="B6" t="e"><v>#DIV/0!</v></c></row>
+</sheetData><mergeCells count="1"><mergeCell ref="B2:C2"/></mergeCells></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row><c t="inlineStr"><is><t>id</t></is></c></row>
+<row><c><v>1</v></c></row>
+</sheetData></worksheet>`,
+	})
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := testWorkbook(t, false)
+	r, err := ReadXLSX(data, data.Size(), DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadXLSX() error = %v", err)
+	}
+
+	csv, err := ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	wantHeader := []string{"Region", "Sales", "Sales", "When"}
+	if !reflect.DeepEqual(csv.Header, wantHeader) {
+		t.Errorf("Header = %v, want %v", csv.Header, wantHeader)
+	}
+
+	wantRecords := [][]string{
+		{"North", "1250.5", "7", "2024-01-31"},
+		{"South", "TRUE", "", "2024-01-31 18:00:00"},
+		{"", "", "", ""},
+		{"South", "#DIV/0!", "", ""},
+	}
+	if !reflect.DeepEqual(csv.Records, wantRecords) {
+		t.Errorf("Records = %q, want %q", csv.Records, wantRecords)
+	}
+}
+
+func TestReadXLSXSheet(t *testing.T) {
+	for _, sheet := range []string{"Orders", "orders", "2"} {
+		data := testWorkbook(t, false)
+		r, err := ReadXLSX(data, data.Size(), &Config{Sheet: sheet})
+		if err != nil {
+			t.Fatalf("ReadXLSX(%q) error = %v", sheet, err)
+		}
+		csv, err := ReadAll(r)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if !reflect.DeepEqual(csv.Header, []string{"id"}) || !reflect.DeepEqual(csv.Records, [][]string{{"1"}}) {
+			t.Errorf("sheet %q = %v %v", sheet, csv.Header, csv.Records)
+		}
+	}
+
+	data := testWorkbook(t, false)
+	if _, err := ReadXLSX(data, data.Size(), &Config{Sheet: "Missing"}); err == nil {
+		t.Error("ReadXLSX() expected error for missing sheet")
+	}
+}
+
+func TestFormatExcelDate(t *testing.T) {
+	tests := []struct {
+		serial   float64
+		kind     dateKind
+		date1904 bool
+		want     string
+	}{
+		{1, dateOnly, false, "1900-01-01"},
+		{59, dateOnly, false, "1900-02-28"},
+		{61, dateOnly, false, "1900-03-01"},
+		{45322, dateOnly, false, "2024-01-31"},
+		{0, dateOnly, true, "1904-01-01"},
+		{0.5, timeOnly, false, "12:00:00"},
+		{45322.25, dateTime, false, "2024-01-31 06:00:00"},
+		{45322, dateTime, false, "2024-01-31"},
+	}
+
+	for _, tt := range tests {
+		if got := formatExcelDate(tt.serial, tt.kind, tt.date1904); got != tt.want {
+			t.Errorf("formatExcelDate(%v, %v, %v) = %q, want %q", tt.serial, tt.kind, tt.date1904, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDateKind(t *testing.T) {
+	tests := map[string]dateKind{
+		"General":             notDate,
+		"0.00":                notDate,
+		`"Qty"\ 0`:            notDate,
+		"[Red]#,##0":          notDate,
+		"yyyy-mm-dd":          dateOnly,
+		"[$-409]mmmm d, yyyy": dateOnly,
+		"h:mm AM/PM":          timeOnly,
+		"[h]:mm:ss":           timeOnly,
+		"dd/mm/yyyy hh:mm":    dateTime,
+		`yyyy\-mm\-dd\ hh:mm`: dateTime,
+	}
+	for code, want := range tests {
+		if got := formatDateKind(code); got != want {
+			t.Errorf("formatDateKind(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
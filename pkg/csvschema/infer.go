@@ -0,0 +1,171 @@
+package csvschema
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+)
+
+type InferOptions struct {
+	EnumLimit  int
+	SampleSize int
+}
+
+func DefaultInferOptions() InferOptions {
+	return InferOptions{EnumLimit: 10}
+}
+
+type fieldInferrer struct {
+	types    csvstats.TypeInferrer
+	count    int
+	nulls    int
+	padded   bool
+	seen     map[string]bool
+	enum     map[string]bool
+	booleans map[string]bool
+}
+
+func Infer(reader *csvparser.Reader, options InferOptions) (*Schema, error) {
+	header := reader.Header()
+	if len(header) == 0 {
+		return nil, fmt.Errorf("cannot infer a schema without a header")
+	}
+
+	inferrers := make([]*fieldInferrer, len(header))
+	for i := range inferrers {
+		inferrers[i] = &fieldInferrer{
+			seen:     make(map[string]bool),
+			enum:     make(map[string]bool),
+			booleans: make(map[string]bool),
+		}
+	}
+
+	for options.SampleSize <= 0 || reader.RecordNum() < options.SampleSize {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, fi := range inferrers {
+			value := ""
+			if i < len(record) {
+				value = record[i]
+			}
+			fi.add(value, options.EnumLimit)
+		}
+	}
+
+	schema := &Schema{Fields: make([]Field, len(header))}
+	for i, fi := range inferrers {
+		schema.Fields[i] = fi.field(header[i])
+	}
+	return schema, nil
+}
+
+func InferFile(filename string, config *csvparser.Config, options InferOptions) (*Schema, error) {
+	reader, err := csvparser.OpenFileOrStdin(filename, config)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return Infer(reader, options)
+}
+
+func (fi *fieldInferrer) add(value string, enumLimit int) {
+	if value == "" {
+		fi.nulls++
+		return
+	}
+	fi.count++
+	if strings.TrimSpace(value) != value {
+		fi.padded = true
+	}
+	fi.types.Add(value)
+
+	if fi.seen != nil {
+		if fi.seen[value] {
+			fi.seen = nil
+		} else {
+			fi.seen[value] = true
+		}
+	}
+	if fi.enum != nil && !fi.enum[value] {
+		fi.enum[value] = true
+		if len(fi.enum) > enumLimit {
+			fi.enum = nil
+		}
+	}
+	if b, ok := csvstats.ParseBool(value); ok {
+		fi.booleans[value] = b
+	}
+}
+
+func (fi *fieldInferrer) field(name string) Field {
+	f := Field{Name: name, Type: "any"}
+	switch t := fi.types.Type; {
+	case fi.padded && t != csvstats.TypeNull:
+		f.Type = "string"
+	case t == csvstats.TypeBoolean:
+		f.Type = "boolean"
+		f.TrueValues, f.FalseValues = fi.booleanValues()
+	case t == csvstats.TypeInteger:
+		f.Type = "integer"
+	case t == csvstats.TypeNumber:
+		f.Type = "number"
+	case t == csvstats.TypeDate:
+		f.Type = "date"
+		if fi.types.Layout != "2006-01-02" {
+			f.Format = strftimeFormat(fi.types.Layout)
+		}
+	case t == csvstats.TypeDateTime:
+		f.Type = "datetime"
+		if fi.types.Layout != time.RFC3339Nano {
+			f.Format = strftimeFormat(fi.types.Layout)
+		}
+	case t == csvstats.TypeString:
+		f.Type = "string"
+	}
+
+	f.Constraints.Required = fi.count > 0 && fi.nulls == 0
+	f.Constraints.Unique = fi.seen != nil && fi.count > 1
+	if f.Type == "string" && len(fi.enum) > 0 && fi.count >= 2*len(fi.enum) && !f.Constraints.Unique {
+		for value := range fi.enum {
+			f.Constraints.Enum = append(f.Constraints.Enum, Value(value))
+		}
+		slices.Sort(f.Constraints.Enum)
+	}
+	return f
+}
+
+func (fi *fieldInferrer) booleanValues() (trueValues, falseValues []string) {
+	standard := true
+	for value, b := range fi.booleans {
+		if b {
+			trueValues = append(trueValues, value)
+			standard = standard && slices.Contains(defaultTrueValues, value)
+		} else {
+			falseValues = append(falseValues, value)
+			standard = standard && slices.Contains(defaultFalseValues, value)
+		}
+	}
+	if standard {
+		return nil, nil
+	}
+	slices.Sort(trueValues)
+	slices.Sort(falseValues)
+	return trueValues, falseValues
+}
+
+func strftimeFormat(layout string) string {
+	if format := csvstats.StrftimeFormat(layout); format != "" {
+		return format
+	}
+	return "any"
+}
@@ -0,0 +1,106 @@
+package csvschema
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+const inferInput = `id,name,status,score,active,joined,seen,note
+1,Ann,open,1.5,yes,31/12/2023,2024-01-02T10:00:00Z,
+2,Bob,closed,2,no,01/01/2024,2024-01-03T11:30:00Z,x
+3,Cy,open,3,yes,15/02/2024,2024-01-04T12:00:00Z,
+4,Di,open,,no,20/03/2024,2024-01-05T09:15:00Z,
+`
+
+func inferSchema(t *testing.T, input string, options InferOptions) *Schema {
+	t.Helper()
+	reader, err := csvparser.NewReader(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	schema, err := Infer(reader, options)
+	if err != nil {
+		t.Fatalf("Infer() error = %v", err)
+	}
+	return schema
+}
+
+func TestInfer(t *testing.T) {
+	schema := inferSchema(t, inferInput, DefaultInferOptions())
+
+	tests := []struct {
+		name     string
+		typ      string
+		format   string
+		required bool
+		unique   bool
+		enum     int
+	}{
+		{"id", "integer", "", true, true, 0},
+		{"name", "string", "", true, true, 0},
+		{"status", "string", "", true, false, 2},
+		{"score", "number", "", false, true, 0},
+		{"active", "boolean", "", true, false, 0},
+		{"joined", "date", "%d/%m/%Y", true, true, 0},
+		{"seen", "datetime", "", true, true, 0},
+		{"note", "string", "", false, false, 0},
+	}
+	if len(schema.Fields) != len(tests) {
+		t.Fatalf("Infer() fields = %d, want %d", len(schema.Fields), len(tests))
+	}
+	for i, tt := range tests {
+		f := schema.Fields[i]
+		c := f.Constraints
+		if f.Name != tt.name || f.Type != tt.typ || f.Format != tt.format || c.Required != tt.required || c.Unique != tt.unique || len(c.Enum) != tt.enum {
+			t.Errorf("field %d = %+v, want %+v", i, f, tt)
+		}
+	}
+	if f := schema.Fields[4]; strings.Join(f.TrueValues, ",") != "yes" || strings.Join(f.FalseValues, ",") != "no" {
+		t.Errorf("boolean values = %v / %v, want yes / no", f.TrueValues, f.FalseValues)
+	}
+}
+
+func TestInferRoundTrip(t *testing.T) {
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := inferSchema(t, inferInput, DefaultInferOptions()).Write(&buf, format); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			schema, err := Read(&buf)
+			if err != nil {
+				t.Fatalf("Read() error = %v\n%s", err, buf.String())
+			}
+
+			reader, _ := csvparser.NewReader(strings.NewReader(inferInput), nil)
+			problems, err := Validate(reader, schema, Options{})
+			if err != nil || len(problems) != 0 {
+				t.Errorf("Validate() = %v, %v, want the inferred schema to accept its input", problems, err)
+			}
+		})
+	}
+}
+
+func TestInferSampleSize(t *testing.T) {
+	schema := inferSchema(t, "n\n1\n2\nx\n", InferOptions{SampleSize: 2})
+	if schema.Fields[0].Type != "integer" {
+		t.Errorf("type = %q, want integer from the first 2 rows", schema.Fields[0].Type)
+	}
+}
+
+func TestStrftimeFormat(t *testing.T) {
+	tests := map[string]string{
+		"2006/01/02":          "%Y/%m/%d",
+		"2006-01-02 15:04:05": "%Y-%m-%d %H:%M:%S",
+		"Jan 2, 2006":         "any",
+		"":                    "any",
+	}
+	for layout, want := range tests {
+		if got := strftimeFormat(layout); got != want {
+			t.Errorf("strftimeFormat(%q) = %q, want %q", layout, got, want)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package csvschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+
+	"go.yaml.in/yaml/v3"
+)
+
+type Schema struct {
+	Fields        []Field  `json:"fields" yaml:"fields"`
+	PrimaryKey    Names    `json:"primaryKey,omitempty" yaml:"primaryKey,omitempty"`
+	MissingValues []string `json:"missingValues,omitempty" yaml:"missingValues,omitempty"`
+	FieldsMatch   string   `json:"fieldsMatch,omitempty" yaml:"fieldsMatch,omitempty"`
+}
+
+type Field struct {
+	Name        string      `json:"name" yaml:"name"`
+	Title       string      `json:"title,omitempty" yaml:"title,omitempty"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Type        string      `json:"type,omitempty" yaml:"type,omitempty"`
+	Format      string      `json:"format,omitempty" yaml:"format,omitempty"`
+	TrueValues  []string    `json:"trueValues,omitempty" yaml:"trueValues,omitempty"`
+	FalseValues []string    `json:"falseValues,omitempty" yaml:"falseValues,omitempty"`
+	DecimalChar string      `json:"decimalChar,omitempty" yaml:"decimalChar,omitempty"`
+	GroupChar   string      `json:"groupChar,omitempty" yaml:"groupChar,omitempty"`
+	BareNumber  *bool       `json:"bareNumber,omitempty" yaml:"bareNumber,omitempty"`
+	Constraints Constraints `json:"constraints,omitzero" yaml:"constraints,omitempty"`
+}
+
+type Constraints struct {
+	Required  bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Unique    bool    `json:"unique,omitempty" yaml:"unique,omitempty"`
+	Enum      []Value `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Pattern   string  `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Minimum   *Value  `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum   *Value  `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	MinLength *int    `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength *int    `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+}
+
+type Names []string
+
+func (n *Names) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*n = Names{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("primaryKey must be a field name or a list of field names")
+	}
+	*n = names
+	return nil
+}
+
+func (n *Names) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*n = Names{node.Value}
+		return nil
+	case yaml.SequenceNode:
+		var names []string
+		if err := node.Decode(&names); err == nil {
+			*n = names
+			return nil
+		}
+	}
+	return fmt.Errorf("line %d: primaryKey must be a field name or a list of field names", node.Line)
+}
+
+type Value string
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = Value(s)
+		return nil
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) || data[0] == '{' || data[0] == '[' {
+		return fmt.Errorf("constraint value must be a string, number or boolean, got %s", data)
+	}
+	*v = Value(data)
+	return nil
+}
+
+func (v *Value) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+		return fmt.Errorf("line %d: constraint value must be a string, number or boolean", node.Line)
+	}
+	*v = Value(node.Value)
+	return nil
+}
+
+var fieldsMatchModes = []string{"exact", "equal", "subset", "superset", "partial"}
+
+func Load(filename string) (*Schema, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open schema: %w", err)
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+func Read(reader io.Reader) (*Schema, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	var schema Schema
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		err = json.Unmarshal(data, &schema)
+	} else {
+		err = yaml.Unmarshal(data, &schema)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if err := schema.Check(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (s *Schema) Write(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case "yaml", "yml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unknown schema format %q (available: json, yaml)", format)
+	}
+}
+
+func (s *Schema) ValueTypes() map[string]csvstats.ValueType {
+	types := make(map[string]csvstats.ValueType, len(s.Fields))
+	for _, f := range s.Fields {
+		switch f.typeName() {
+		case "integer", "year":
+			types[f.Name] = csvstats.TypeInteger
+		case "number":
+			types[f.Name] = csvstats.TypeNumber
+		case "boolean":
+			types[f.Name] = csvstats.TypeBoolean
+		case "date":
+			types[f.Name] = csvstats.TypeDate
+		case "datetime":
+			types[f.Name] = csvstats.TypeDateTime
+		default:
+			types[f.Name] = csvstats.TypeString
+		}
+	}
+	return types
+}
+
+func (s *Schema) Layouts() (map[string]string, error) {
+	layouts := make(map[string]string)
+	for _, f := range s.Fields {
+		format := strings.TrimPrefix(f.Format, "fmt:")
+		switch {
+		case f.Type != "date" && f.Type != "datetime", format == "any":
+		case format == "" || format == "default":
+			layouts[f.Name] = "2006-01-02"
+			if f.Type == "datetime" {
+				layouts[f.Name] = time.RFC3339
+			}
+		default:
+			layout, err := csvstats.Layout(format)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", f.Name, err)
+			}
+			layouts[f.Name] = layout
+		}
+	}
+	return layouts, nil
+}
+
+func (s *Schema) Check() error {
+	_, err := s.compile()
+	return err
+}
+
+func (s *Schema) missingValues() map[string]bool {
+	values := s.MissingValues
+	if values == nil {
+		values = []string{""}
+	}
+	missing := make(map[string]bool, len(values))
+	for _, v := range values {
+		missing[v] = true
+	}
+	return missing
+}
+
+type column struct {
+	field    *Field
+	index    int
+	required bool
+	cast     caster
+	pattern  *regexp.Regexp
+	enum     []any
+	minimum  any
+	maximum  any
+}
+
+func (s *Schema) compile() ([]*column, error) {
+	if len(s.Fields) == 0 {
+		return nil, fmt.Errorf("invalid schema: no fields defined")
+	}
+
+	switch strings.ToLower(s.FieldsMatch) {
+	case "", "exact", "equal", "subset", "superset", "partial":
+	default:
+		return nil, fmt.Errorf("invalid schema: unknown fieldsMatch %q (available: %s)", s.FieldsMatch, strings.Join(fieldsMatchModes, ", "))
+	}
+
+	byName := make(map[string]*column, len(s.Fields))
+	columns := make([]*column, len(s.Fields))
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		if f.Name == "" {
+			return nil, fmt.Errorf("invalid schema: field #%d has no name", i+1)
+		}
+		if byName[f.Name] != nil {
+			return nil, fmt.Errorf("invalid schema: duplicate field %q", f.Name)
+		}
+
+		c, err := compileField(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: field %q: %w", f.Name, err)
+		}
+		c.index = -1
+		columns[i] = c
+		byName[f.Name] = c
+	}
+
+	for _, name := range s.PrimaryKey {
+		c, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid schema: primary key field %q is not defined", name)
+		}
+		c.required = true
+	}
+	return columns, nil
+}
+
+func compileField(f *Field) (*column, error) {
+	cast, err := newCaster(f)
+	if err != nil {
+		return nil, err
+	}
+	c := &column{field: f, required: f.Constraints.Required, cast: cast}
+
+	constraints := f.Constraints
+	if constraints.Pattern != "" {
+		if c.pattern, err = regexp.Compile(`^(?:` + constraints.Pattern + `)$`); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", constraints.Pattern, err)
+		}
+	}
+	for _, v := range constraints.Enum {
+		value, ok := cast(string(v))
+		if !ok {
+			return nil, fmt.Errorf("enum value %q is not a valid %s", v, f.typeName())
+		}
+		c.enum = append(c.enum, value)
+	}
+	if constraints.Minimum != nil {
+		if c.minimum, err = castLimit(f, cast, "minimum", *constraints.Minimum); err != nil {
+			return nil, err
+		}
+	}
+	if constraints.Maximum != nil {
+		if c.maximum, err = castLimit(f, cast, "maximum", *constraints.Maximum); err != nil {
+			return nil, err
+		}
+	}
+	if constraints.MinLength != nil && *constraints.MinLength < 0 {
+		return nil, fmt.Errorf("minLength must not be negative")
+	}
+	if constraints.MaxLength != nil && *constraints.MaxLength < 0 {
+		return nil, fmt.Errorf("maxLength must not be negative")
+	}
+	return c, nil
+}
+
+func castLimit(f *Field, cast caster, name string, v Value) (any, error) {
+	value, ok := cast(string(v))
+	if !ok {
+		return nil, fmt.Errorf("%s %q is not a valid %s", name, v, f.typeName())
+	}
+	return value, nil
+}
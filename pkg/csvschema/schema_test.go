@@ -0,0 +1,56 @@
+package csvschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	schema, err := Read(strings.NewReader(`{
+		"fields": [
+			{"name": "id", "type": "integer", "constraints": {"minimum": 1, "enum": [1, 2, "3"]}},
+			{"name": "born", "type": "date", "format": "%d/%m/%Y"}
+		],
+		"primaryKey": "id",
+		"missingValues": ["", "NA"]
+	}`))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(schema.Fields) != 2 || len(schema.PrimaryKey) != 1 || schema.PrimaryKey[0] != "id" {
+		t.Errorf("Read() = %+v", schema)
+	}
+	if c := schema.Fields[0].Constraints; *c.Minimum != "1" || len(c.Enum) != 3 || c.Enum[2] != "3" {
+		t.Errorf("constraints = %+v", c)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"malformed json", `{"fields": [`, "invalid schema"},
+		{"malformed yaml", "fields:\n  - name: [", "invalid schema"},
+		{"no fields", `{"fields": []}`, "no fields"},
+		{"unnamed field", `{"fields": [{"type": "string"}]}`, "has no name"},
+		{"duplicate field", `{"fields": [{"name": "a"}, {"name": "a"}]}`, "duplicate field"},
+		{"unknown type", `{"fields": [{"name": "a", "type": "money"}]}`, `unknown type "money"`},
+		{"bad format", `{"fields": [{"name": "a", "type": "date", "format": "%Q"}]}`, "unsupported directive %Q"},
+		{"bad pattern", `{"fields": [{"name": "a", "constraints": {"pattern": "("}}]}`, "invalid pattern"},
+		{"bad enum", `{"fields": [{"name": "a", "type": "integer", "constraints": {"enum": ["x"]}}]}`, "not a valid integer"},
+		{"bad minimum", `{"fields": [{"name": "a", "type": "date", "constraints": {"minimum": 5}}]}`, "minimum"},
+		{"unknown primary key", `{"fields": [{"name": "a"}], "primaryKey": ["b"]}`, `primary key field "b"`},
+		{"unknown fieldsMatch", `{"fields": [{"name": "a"}], "fieldsMatch": "loose"}`, "unknown fieldsMatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.schema))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Read() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package csvschema
+
+import (
+	"cmp"
+	"encoding/base64"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvstats"
+)
+
+var Types = []string{"string", "integer", "number", "boolean", "date", "datetime", "time", "year", "any"}
+
+type caster func(value string) (any, bool)
+
+var (
+	defaultTrueValues  = []string{"true", "True", "TRUE", "1"}
+	defaultFalseValues = []string{"false", "False", "FALSE", "0"}
+	timeLayouts        = []string{"15:04:05", "15:04:05.999999999", "15:04", "3:04PM", "3:04 PM", "3:04:05 PM"}
+	uuidPattern        = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+func (f *Field) typeName() string {
+	if f.Type == "" {
+		return "string"
+	}
+	return f.Type
+}
+
+func newCaster(f *Field) (caster, error) {
+	format := strings.TrimPrefix(f.Format, "fmt:")
+	switch f.typeName() {
+	case "string":
+		return stringCaster(format)
+	case "integer":
+		return numberCaster(f, func(s string) (any, bool) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			return n, err == nil
+		}), nil
+	case "number":
+		return numberCaster(f, func(s string) (any, bool) {
+			n, err := strconv.ParseFloat(s, 64)
+			return n, err == nil
+		}), nil
+	case "boolean":
+		return booleanCaster(f), nil
+	case "date":
+		return timeCaster(format, "2006-01-02", csvstats.DateLayouts)
+	case "datetime":
+		layouts := append([]string{time.RFC3339}, csvstats.DateTimeLayouts...)
+		return timeCaster(format, time.RFC3339, append(layouts, csvstats.DateLayouts...))
+	case "time":
+		return timeCaster(format, "15:04:05", timeLayouts)
+	case "year":
+		return func(s string) (any, bool) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			return n, err == nil && len(strings.TrimLeft(s, "+-")) == 4
+		}, nil
+	case "any":
+		return func(s string) (any, bool) { return s, true }, nil
+	default:
+		return nil, fmt.Errorf("unknown type %q (available: %s)", f.Type, strings.Join(Types, ", "))
+	}
+}
+
+func stringCaster(format string) (caster, error) {
+	var valid func(string) bool
+	switch format {
+	case "", "default":
+		return func(s string) (any, bool) { return s, true }, nil
+	case "email":
+		valid = func(s string) bool {
+			addr, err := mail.ParseAddress(s)
+			return err == nil && addr.Address == s
+		}
+	case "uri":
+		valid = func(s string) bool {
+			u, err := url.Parse(s)
+			return err == nil && u.Scheme != ""
+		}
+	case "uuid":
+		valid = uuidPattern.MatchString
+	case "binary":
+		valid = func(s string) bool {
+			_, err := base64.StdEncoding.DecodeString(s)
+			return err == nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown string format %q (available: default, email, uri, uuid, binary)", format)
+	}
+	return func(s string) (any, bool) { return s, valid(s) }, nil
+}
+
+func numberCaster(f *Field, parse caster) caster {
+	bare := f.BareNumber == nil || *f.BareNumber
+	return func(s string) (any, bool) {
+		if f.GroupChar != "" {
+			s = strings.ReplaceAll(s, f.GroupChar, "")
+		}
+		if f.DecimalChar != "" && f.DecimalChar != "." {
+			s = strings.ReplaceAll(s, f.DecimalChar, ".")
+		}
+		if !bare {
+			s = strings.TrimFunc(s, func(r rune) bool {
+				return !unicode.IsDigit(r) && r != '-' && r != '+' && r != '.'
+			})
+		}
+		return parse(s)
+	}
+}
+
+func booleanCaster(f *Field) caster {
+	values := make(map[string]bool)
+	trueValues, falseValues := f.TrueValues, f.FalseValues
+	if trueValues == nil {
+		trueValues = defaultTrueValues
+	}
+	if falseValues == nil {
+		falseValues = defaultFalseValues
+	}
+	for _, v := range trueValues {
+		values[v] = true
+	}
+	for _, v := range falseValues {
+		values[v] = false
+	}
+	return func(s string) (any, bool) {
+		b, ok := values[s]
+		return b, ok
+	}
+}
+
+func timeCaster(format, layout string, anyLayouts []string) (caster, error) {
+	layouts := []string{layout}
+	switch format {
+	case "", "default":
+	case "any":
+		layouts = anyLayouts
+	default:
+		l, err := csvstats.Layout(format)
+		if err != nil {
+			return nil, err
+		}
+		layouts = []string{l}
+	}
+	return func(s string) (any, bool) {
+		t, _, ok := csvstats.ParseDate(s, layouts)
+		return t, ok && strings.TrimSpace(s) == s
+	}, nil
+}
+
+func compare(a, b any) int {
+	switch x := a.(type) {
+	case int64:
+		return cmp.Compare(x, b.(int64))
+	case float64:
+		return cmp.Compare(x, b.(float64))
+	case time.Time:
+		return x.Compare(b.(time.Time))
+	case bool:
+		return cmp.Compare(boolInt(x), boolInt(b.(bool)))
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func canonical(v any) string {
+	switch x := v.(type) {
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,327 @@
+package csvschema
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvlint"
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+var (
+	ErrMissingColumn = errors.New("missing column")
+	ErrExtraColumn   = errors.New("extra column")
+	ErrColumnOrder   = errors.New("column out of order")
+	ErrType          = errors.New("invalid type")
+	ErrRequired      = errors.New("missing required value")
+	ErrUnique        = errors.New("duplicate value")
+	ErrPrimaryKey    = errors.New("duplicate primary key")
+	ErrEnum          = errors.New("value not allowed")
+	ErrPattern       = errors.New("pattern mismatch")
+	ErrMinimum       = errors.New("value too small")
+	ErrMaximum       = errors.New("value too large")
+	ErrMinLength     = errors.New("value too short")
+	ErrMaxLength     = errors.New("value too long")
+)
+
+var Rules = []csvlint.Rule{
+	{ID: "missing-column", Severity: csvlint.SeverityError, Default: true, Description: "A schema field has no column in the header"},
+	{ID: "extra-column", Severity: csvlint.SeverityError, Default: true, Description: "A header column is not defined in the schema"},
+	{ID: "field-count", Severity: csvlint.SeverityError, Default: true, Description: "A record has more or fewer fields than the header"},
+	{ID: "column-order", Severity: csvlint.SeverityError, Default: true, Description: "Columns are not in schema order"},
+	{ID: "type", Severity: csvlint.SeverityError, Default: true, Description: "A value cannot be read as the field's type and format"},
+	{ID: "required", Severity: csvlint.SeverityError, Default: true, Description: "A required or primary key field is missing a value"},
+	{ID: "unique", Severity: csvlint.SeverityError, Default: true, Description: "A unique field has a repeated value"},
+	{ID: "primary-key", Severity: csvlint.SeverityError, Default: true, Description: "A primary key is repeated"},
+	{ID: "enum", Severity: csvlint.SeverityError, Default: true, Description: "A value is not one of the allowed values"},
+	{ID: "pattern", Severity: csvlint.SeverityError, Default: true, Description: "A value does not match the field's pattern"},
+	{ID: "minimum", Severity: csvlint.SeverityError, Default: true, Description: "A value is below the field's minimum"},
+	{ID: "maximum", Severity: csvlint.SeverityError, Default: true, Description: "A value is above the field's maximum"},
+	{ID: "min-length", Severity: csvlint.SeverityError, Default: true, Description: "A value is shorter than the field's minimum length"},
+	{ID: "max-length", Severity: csvlint.SeverityError, Default: true, Description: "A value is longer than the field's maximum length"},
+}
+
+type Options struct {
+	MaxErrors int
+}
+
+func NewReport(file string, options Options, problems []csvlint.CSVError) csvlint.Report {
+	return csvlint.Report{Command: "validate", File: file, Rules: Rules, Problems: problems, MaxErrors: options.MaxErrors}
+}
+
+type validator struct {
+	schema  *Schema
+	options Options
+	reader  *csvparser.Reader
+	columns []*column
+	key     []*column
+	missing map[string]bool
+	unique  map[*column]map[string]int
+	keys    map[string]int
+	errs    []csvlint.CSVError
+}
+
+func Validate(reader *csvparser.Reader, schema *Schema, options Options) ([]csvlint.CSVError, error) {
+	columns, err := schema.compile()
+	if err != nil {
+		return nil, err
+	}
+	v := &validator{
+		schema:  schema,
+		options: options,
+		reader:  reader,
+		columns: columns,
+		missing: schema.missingValues(),
+		unique:  make(map[*column]map[string]int),
+	}
+	for _, c := range columns {
+		if c.field.Constraints.Unique {
+			v.unique[c] = make(map[string]int)
+		}
+	}
+	for _, name := range schema.PrimaryKey {
+		for _, c := range columns {
+			if c.field.Name == name {
+				v.key = append(v.key, c)
+			}
+		}
+	}
+	if len(v.key) > 0 {
+		v.keys = make(map[string]int)
+	}
+
+	v.checkHeader(reader.Header())
+	for !v.full() {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return v.errs, err
+		}
+		v.checkRecord(record, reader.RecordNum())
+	}
+	return v.errs, nil
+}
+
+func ValidateFile(filename string, config *csvparser.Config, schema *Schema, options Options) ([]csvlint.CSVError, error) {
+	ragged := *config
+	ragged.FieldsPerRecord = -1
+	reader, err := csvparser.OpenFileOrStdin(filename, &ragged)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return Validate(reader, schema, options)
+}
+
+func (v *validator) full() bool {
+	return v.options.MaxErrors > 0 && len(v.errs) >= v.options.MaxErrors
+}
+
+func (v *validator) report(rule string, num, line, column int, value string, err error) {
+	if v.full() {
+		return
+	}
+	v.errs = append(v.errs, csvlint.CSVError{
+		Num:      num,
+		Line:     line,
+		Column:   column,
+		Rule:     rule,
+		Severity: csvlint.SeverityError,
+		Value:    value,
+		Err:      err,
+	})
+}
+
+func (v *validator) headerPos(index, count int) (line, column int) {
+	if index >= count {
+		index = count - 1
+	}
+	if index >= 0 {
+		line, column = v.reader.FieldPos(index)
+	}
+	if line == 0 {
+		return 1, index + 1
+	}
+	return line, column
+}
+
+func (v *validator) checkHeader(header []string) {
+	mode := strings.ToLower(v.schema.FieldsMatch)
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+
+	defined := make(map[string]bool, len(v.columns))
+	matched := 0
+	for i, c := range v.columns {
+		defined[c.field.Name] = true
+		if idx, ok := index[c.field.Name]; ok {
+			c.index = idx
+			matched++
+			continue
+		}
+		if mode == "superset" || mode == "partial" {
+			continue
+		}
+		line, column := v.headerPos(i, len(header))
+		v.report("missing-column", 0, line, column, "",
+			fmt.Errorf("%w: schema field %q is not in the header", ErrMissingColumn, c.field.Name))
+	}
+	if mode == "partial" && matched == 0 {
+		line, column := v.headerPos(0, len(header))
+		v.report("missing-column", 0, line, column, "",
+			fmt.Errorf("%w: none of the schema fields are in the header", ErrMissingColumn))
+	}
+
+	if mode != "subset" && mode != "partial" {
+		for i, name := range header {
+			if !defined[name] {
+				line, column := v.headerPos(i, len(header))
+				v.report("extra-column", 0, line, column, name,
+					fmt.Errorf("%w: header column %q is not defined in the schema", ErrExtraColumn, name))
+			}
+		}
+	}
+
+	if mode == "" || mode == "exact" {
+		for i, c := range v.columns {
+			if c.index >= 0 && c.index != i && i < len(header) && defined[header[i]] {
+				line, column := v.headerPos(i, len(header))
+				v.report("column-order", 0, line, column, header[i],
+					fmt.Errorf("%w: column %d is %q, schema expects %q", ErrColumnOrder, i+1, header[i], c.field.Name))
+			}
+		}
+	}
+}
+
+func (v *validator) checkRecord(record []string, num int) {
+	if header := v.reader.Header(); len(record) != len(header) {
+		line, column := v.reader.FieldPos(0)
+		if len(record) > len(header) {
+			line, column = v.reader.FieldPos(len(header))
+		}
+		v.report("field-count", num, line, column, "",
+			fmt.Errorf("%w: expected %d, got %d", csv.ErrFieldCount, len(header), len(record)))
+	}
+
+	values := make(map[*column]any, len(v.columns))
+	for _, c := range v.columns {
+		if c.index < 0 {
+			continue
+		}
+		value := ""
+		line, column := 0, 0
+		if c.index < len(record) {
+			value = record[c.index]
+			line, column = v.reader.FieldPos(c.index)
+		} else if len(record) > 0 {
+			line, _ = v.reader.FieldPos(len(record) - 1)
+		}
+		if typed := v.checkValue(c, value, num, line, column); typed != nil {
+			values[c] = typed
+		}
+	}
+
+	if len(v.key) == 0 {
+		return
+	}
+	parts := make([]string, len(v.key))
+	for i, c := range v.key {
+		typed, ok := values[c]
+		if !ok {
+			return
+		}
+		parts[i] = canonical(typed)
+	}
+	key := strings.Join(parts, "\x00")
+	first, ok := v.keys[key]
+	if !ok {
+		v.keys[key] = num
+		return
+	}
+	line, column := v.reader.FieldPos(v.key[0].index)
+	v.report("primary-key", num, line, column, strings.Join(parts, ", "),
+		fmt.Errorf("%w: (%s) = (%s) already appears in record #%d", ErrPrimaryKey, strings.Join(v.schema.PrimaryKey, ", "), strings.Join(parts, ", "), first))
+}
+
+func (v *validator) checkValue(c *column, value string, num, line, column int) any {
+	name := c.field.Name
+	if v.missing[value] {
+		if c.required {
+			v.report("required", num, line, column, value,
+				fmt.Errorf("%w: column %q is required", ErrRequired, name))
+		}
+		return nil
+	}
+
+	typed, ok := c.cast(value)
+	if !ok {
+		kind := c.field.typeName()
+		if c.field.Format != "" && c.field.Format != "default" {
+			kind = fmt.Sprintf("%s in format %q", kind, c.field.Format)
+		}
+		v.report("type", num, line, column, value,
+			fmt.Errorf("%w: value %q in column %q is not a valid %s", ErrType, value, name, kind))
+		return nil
+	}
+
+	constraints := c.field.Constraints
+	if c.enum != nil && !containsValue(c.enum, typed) {
+		allowed := make([]string, len(constraints.Enum))
+		for i, e := range constraints.Enum {
+			allowed[i] = string(e)
+		}
+		v.report("enum", num, line, column, value,
+			fmt.Errorf("%w: value %q in column %q is not one of: %s", ErrEnum, value, name, strings.Join(allowed, ", ")))
+	}
+	if c.pattern != nil && !c.pattern.MatchString(value) {
+		v.report("pattern", num, line, column, value,
+			fmt.Errorf("%w: value %q in column %q does not match %q", ErrPattern, value, name, constraints.Pattern))
+	}
+	if c.minimum != nil && compare(typed, c.minimum) < 0 {
+		v.report("minimum", num, line, column, value,
+			fmt.Errorf("%w: value %q in column %q is less than the minimum %s", ErrMinimum, value, name, *constraints.Minimum))
+	}
+	if c.maximum != nil && compare(typed, c.maximum) > 0 {
+		v.report("maximum", num, line, column, value,
+			fmt.Errorf("%w: value %q in column %q is greater than the maximum %s", ErrMaximum, value, name, *constraints.Maximum))
+	}
+	length := utf8.RuneCountInString(value)
+	if constraints.MinLength != nil && length < *constraints.MinLength {
+		v.report("min-length", num, line, column, value,
+			fmt.Errorf("%w: value %q in column %q has %d characters, minimum is %d", ErrMinLength, value, name, length, *constraints.MinLength))
+	}
+	if constraints.MaxLength != nil && length > *constraints.MaxLength {
+		v.report("max-length", num, line, column, value,
+			fmt.Errorf("%w: value %q in column %q has %d characters, maximum is %d", ErrMaxLength, value, name, length, *constraints.MaxLength))
+	}
+
+	if seen, ok := v.unique[c]; ok {
+		key := canonical(typed)
+		if first, dup := seen[key]; dup {
+			v.report("unique", num, line, column, value,
+				fmt.Errorf("%w: value %q in unique column %q already appears in record #%d", ErrUnique, value, name, first))
+		} else {
+			seen[key] = num
+		}
+	}
+	return typed
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if compare(v, value) == 0 {
+			return true
+		}
+	}
+	return false
+}
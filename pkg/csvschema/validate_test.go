@@ -0,0 +1,184 @@
+package csvschema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+func validate(t *testing.T, schema, input string, options Options) []string {
+	t.Helper()
+	s, err := Read(strings.NewReader(schema))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	config := csvparser.DefaultConfig()
+	config.FieldsPerRecord = -1
+	reader, err := csvparser.NewReader(strings.NewReader(input), config)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	problems, err := Validate(reader, s, options)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	var rules []string
+	for _, p := range problems {
+		rules = append(rules, p.Rule)
+	}
+	return rules
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		input  string
+		want   []string
+	}{
+		{
+			name:   "valid",
+			schema: `{"fields": [{"name": "id", "type": "integer"}, {"name": "score", "type": "number"}, {"name": "ok", "type": "boolean"}]}`,
+			input:  "id,score,ok\n1,2.5,true\n2,,0\n",
+		},
+		{
+			name:   "missing and extra columns",
+			schema: `{"fields": [{"name": "id"}, {"name": "name"}]}`,
+			input:  "id,email\n1,a@b.c\n",
+			want:   []string{"missing-column", "extra-column"},
+		},
+		{
+			name:   "column order",
+			schema: `{"fields": [{"name": "id"}, {"name": "name"}]}`,
+			input:  "name,id\nAnn,1\n",
+			want:   []string{"column-order", "column-order"},
+		},
+		{
+			name:   "equal ignores order",
+			schema: `{"fields": [{"name": "id"}, {"name": "name"}], "fieldsMatch": "equal"}`,
+			input:  "name,id\nAnn,1\n",
+		},
+		{
+			name:   "subset allows extra columns",
+			schema: `{"fields": [{"name": "id"}], "fieldsMatch": "subset"}`,
+			input:  "id,name\n1,Ann\n",
+		},
+		{
+			name:   "superset allows missing columns",
+			schema: `{"fields": [{"name": "id"}, {"name": "name"}], "fieldsMatch": "superset"}`,
+			input:  "id\n1\n",
+		},
+		{
+			name:   "types",
+			schema: `{"fields": [{"name": "n", "type": "integer"}, {"name": "d", "type": "date"}, {"name": "b", "type": "boolean"}]}`,
+			input:  "n,d,b\n1.5,2024-02-30,yes\n",
+			want:   []string{"type", "type", "type"},
+		},
+		{
+			name:   "date and datetime formats",
+			schema: `{"fields": [{"name": "d", "type": "date", "format": "%d/%m/%Y"}, {"name": "t", "type": "datetime", "format": "any"}]}`,
+			input:  "d,t\n31/12/2024,2024-12-31 10:00\n2024-12-31,noon\n",
+			want:   []string{"type", "type"},
+		},
+		{
+			name:   "required and missing values",
+			schema: `{"fields": [{"name": "id", "type": "integer", "constraints": {"required": true}}], "missingValues": ["", "NA"]}`,
+			input:  "id\n1\nNA\n\n",
+			want:   []string{"required"},
+		},
+		{
+			name:   "unique",
+			schema: `{"fields": [{"name": "id", "type": "integer", "constraints": {"unique": true}}]}`,
+			input:  "id\n1\n01\n2\n\n\n",
+			want:   []string{"unique"},
+		},
+		{
+			name:   "composite primary key",
+			schema: `{"fields": [{"name": "a"}, {"name": "b"}], "primaryKey": ["a", "b"]}`,
+			input:  "a,b\nx,1\nx,2\nx,1\ny,\n",
+			want:   []string{"primary-key", "required"},
+		},
+		{
+			name:   "enum and pattern",
+			schema: `{"fields": [{"name": "status", "constraints": {"enum": ["open", "closed"]}}, {"name": "code", "constraints": {"pattern": "[A-Z]{3}"}}]}`,
+			input:  "status,code\nopen,ABC\npending,ABCD\n",
+			want:   []string{"enum", "pattern"},
+		},
+		{
+			name:   "ranges",
+			schema: `{"fields": [{"name": "age", "type": "integer", "constraints": {"minimum": 0, "maximum": 120}}, {"name": "d", "type": "date", "constraints": {"minimum": "2000-01-01"}}]}`,
+			input:  "age,d\n-1,1999-12-31\n121,2000-01-01\n",
+			want:   []string{"minimum", "minimum", "maximum"},
+		},
+		{
+			name:   "lengths",
+			schema: `{"fields": [{"name": "code", "constraints": {"minLength": 2, "maxLength": 3}}]}`,
+			input:  "code\nA\nABCD\nÄÖÜ\n",
+			want:   []string{"min-length", "max-length"},
+		},
+		{
+			name:   "string formats",
+			schema: `{"fields": [{"name": "email", "format": "email"}, {"name": "id", "format": "uuid"}]}`,
+			input:  "email,id\nann@example.com,123e4567-e89b-12d3-a456-426614174000\nann,123\n",
+			want:   []string{"type", "type"},
+		},
+		{
+			name:   "custom booleans and number formatting",
+			schema: `{"fields": [{"name": "b", "type": "boolean", "trueValues": ["Y"], "falseValues": ["N"]}, {"name": "n", "type": "number", "groupChar": ",", "decimalChar": ".", "bareNumber": false}]}`,
+			input:  "b,n\nY,\"$1,234.50\"\ntrue,5%\n",
+			want:   []string{"type"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validate(t, tt.schema, tt.input, Options{})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRaggedRows(t *testing.T) {
+	schema := `{"fields": [{"name": "id", "type": "integer"}, {"name": "name", "constraints": {"required": true}}]}`
+	got := validate(t, schema, "id,name\n1\n2,Bob,x\ny,Cy\n", Options{})
+	want := []string{"field-count", "required", "field-count", "type"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() rules = %v, want %v", got, want)
+	}
+}
+
+func TestValidatePositions(t *testing.T) {
+	s, _ := Read(strings.NewReader(`{"fields": [{"name": "id", "type": "integer"}, {"name": "name"}]}`))
+	reader, _ := csvparser.NewReader(strings.NewReader("id,name\n1,Ann\n\"multi\nline\",Bob\nx,Cy\n"), nil)
+	problems, err := Validate(reader, s, Options{})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("Validate() = %v, want 2 problems", problems)
+	}
+
+	p := problems[1]
+	if p.Num != 3 || p.Line != 5 || p.Column != 1 || p.Value != "x" {
+		t.Errorf("problem = %+v, want record 3 at line 5, column 1", p)
+	}
+	if !errors.Is(p, ErrType) {
+		t.Errorf("errors.Is(%v, ErrType) = false", p)
+	}
+	want := `Line 5, column 1: record #3 has error: invalid type: value "x" in column "id" is not a valid integer`
+	if p.Error() != want {
+		t.Errorf("Error() = %q, want %q", p.Error(), want)
+	}
+}
+
+func TestValidateMaxErrors(t *testing.T) {
+	got := validate(t, `{"fields": [{"name": "n", "type": "integer"}]}`, "n\na\nb\nc\n", Options{MaxErrors: 2})
+	if len(got) != 2 {
+		t.Errorf("Validate() = %v, want 2 problems", got)
+	}
+}
@@ -0,0 +1,335 @@
+package csvstats
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type Options struct {
+	Approximate bool
+	TopN        int
+	Quantiles   []float64
+	NullValues  []string
+	SampleSize  int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Approximate: false,
+		TopN:        5,
+		Quantiles:   []float64{0.25, 0.5, 0.75},
+		NullValues:  []string{""},
+		SampleSize:  100000,
+	}
+}
+
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type Quantile struct {
+	P     float64 `json:"p"`
+	Value float64 `json:"value"`
+}
+
+type ColumnStats struct {
+	Name           string       `json:"name"`
+	Type           ValueType    `json:"type"`
+	Layout         string       `json:"layout,omitempty"`
+	Count          int          `json:"count"`
+	Nulls          int          `json:"nulls"`
+	Distinct       int          `json:"distinct"`
+	DistinctApprox bool         `json:"distinct_approx,omitempty"`
+	Min            string       `json:"min,omitempty"`
+	Max            string       `json:"max,omitempty"`
+	Mean           *float64     `json:"mean,omitempty"`
+	StdDev         *float64     `json:"stddev,omitempty"`
+	Quantiles      []Quantile   `json:"quantiles,omitempty"`
+	MinLength      int          `json:"min_length"`
+	MaxLength      int          `json:"max_length"`
+	Top            []ValueCount `json:"top,omitempty"`
+}
+
+type columnProfile struct {
+	name     string
+	options  Options
+	inferrer TypeInferrer
+
+	count int
+	nulls int
+
+	counts  map[string]int
+	hll     *HyperLogLog
+	summary *spaceSaving
+
+	numericCount int
+	mean         float64
+	m2           float64
+	minNum       float64
+	maxNum       float64
+	sample       []float64
+	seen         int
+	rng          *rand.Rand
+
+	minStr, maxStr string
+	minLen, maxLen int
+	dates          dateRange
+}
+
+type dateRange struct {
+	min, max       time.Time
+	minStr, maxStr string
+}
+
+func (r *dateRange) add(value string, layouts []string) {
+	t, _, ok := ParseDate(value, layouts)
+	if !ok {
+		return
+	}
+	if r.minStr == "" || t.Before(r.min) {
+		r.min, r.minStr = t, value
+	}
+	if r.maxStr == "" || t.After(r.max) {
+		r.max, r.maxStr = t, value
+	}
+}
+
+type Profiler struct {
+	columns []*columnProfile
+	options Options
+	nulls   map[string]bool
+}
+
+func NewProfiler(header []string, options Options) *Profiler {
+	p := &Profiler{
+		options: options,
+		nulls:   make(map[string]bool),
+	}
+	for _, v := range options.NullValues {
+		p.nulls[v] = true
+	}
+
+	for _, name := range header {
+		col := &columnProfile{
+			name:    name,
+			options: options,
+			rng:     rand.New(rand.NewSource(1)),
+			minLen:  -1,
+		}
+		if options.Approximate {
+			col.hll = NewHyperLogLog()
+			col.summary = newSpaceSaving(max(options.TopN*20, 100))
+		} else {
+			col.counts = make(map[string]int)
+		}
+		p.columns = append(p.columns, col)
+	}
+	return p
+}
+
+func (p *Profiler) Add(record []string) {
+	for i, col := range p.columns {
+		value := ""
+		if i < len(record) {
+			value = record[i]
+		}
+		col.count++
+		if p.nulls[strings.TrimSpace(value)] {
+			col.nulls++
+			continue
+		}
+		col.add(value)
+	}
+}
+
+func (c *columnProfile) add(value string) {
+	c.inferrer.Add(value)
+
+	if c.counts != nil {
+		c.counts[value]++
+	} else {
+		c.hll.Add(value)
+		c.summary.add(value)
+		if c.inferrer.Type == TypeDate || c.inferrer.Type == TypeDateTime {
+			c.dates.add(value, dateTimeLayouts)
+		}
+	}
+
+	length := utf8.RuneCountInString(value)
+	if c.minLen < 0 {
+		c.minLen, c.maxLen = length, length
+		c.minStr, c.maxStr = value, value
+	} else {
+		c.minLen = min(c.minLen, length)
+		c.maxLen = max(c.maxLen, length)
+		c.minStr = min(c.minStr, value)
+		c.maxStr = max(c.maxStr, value)
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return
+	}
+
+	c.numericCount++
+	delta := f - c.mean
+	c.mean += delta / float64(c.numericCount)
+	c.m2 += delta * (f - c.mean)
+	if c.numericCount == 1 || f < c.minNum {
+		c.minNum = f
+	}
+	if c.numericCount == 1 || f > c.maxNum {
+		c.maxNum = f
+	}
+
+	c.seen++
+	if !c.options.Approximate || len(c.sample) < c.options.SampleSize {
+		c.sample = append(c.sample, f)
+	} else if j := c.rng.Intn(c.seen); j < c.options.SampleSize {
+		c.sample[j] = f
+	}
+}
+
+func (c *columnProfile) result() ColumnStats {
+	stats := ColumnStats{
+		Name:      c.name,
+		Type:      c.inferrer.Type,
+		Layout:    c.inferrer.Layout,
+		Count:     c.count,
+		Nulls:     c.nulls,
+		MinLength: max(c.minLen, 0),
+		MaxLength: c.maxLen,
+	}
+
+	if c.counts != nil {
+		stats.Distinct = len(c.counts)
+		for value, count := range c.counts {
+			stats.Top = append(stats.Top, ValueCount{Value: value, Count: count})
+		}
+	} else {
+		stats.Distinct = int(c.hll.Count())
+		stats.DistinctApprox = true
+		stats.Top = c.summary.values()
+	}
+	slices.SortFunc(stats.Top, func(a, b ValueCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	if len(stats.Top) > c.options.TopN {
+		stats.Top = stats.Top[:c.options.TopN]
+	}
+
+	switch stats.Type {
+	case TypeInteger, TypeNumber:
+		stats.Min = strconv.FormatFloat(c.minNum, 'f', -1, 64)
+		stats.Max = strconv.FormatFloat(c.maxNum, 'f', -1, 64)
+
+		mean := c.mean
+		stats.Mean = &mean
+		if c.numericCount > 1 {
+			stddev := math.Sqrt(c.m2 / float64(c.numericCount-1))
+			stats.StdDev = &stddev
+		}
+
+		slices.Sort(c.sample)
+		for _, q := range c.options.Quantiles {
+			stats.Quantiles = append(stats.Quantiles, Quantile{P: q, Value: quantile(c.sample, q)})
+		}
+	case TypeDate, TypeDateTime:
+		stats.Min, stats.Max = c.timeRange()
+	case TypeNull:
+	default:
+		stats.Min, stats.Max = c.minStr, c.maxStr
+	}
+
+	return stats
+}
+
+func (c *columnProfile) timeRange() (string, string) {
+	if c.counts == nil {
+		return c.dates.minStr, c.dates.maxStr
+	}
+
+	layouts := dateTimeLayouts
+	if c.inferrer.Layout != "" {
+		layouts = []string{c.inferrer.Layout}
+	}
+	var dates dateRange
+	for value := range c.counts {
+		dates.add(value, layouts)
+	}
+	return dates.minStr, dates.maxStr
+}
+
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := q * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func (p *Profiler) Results() []ColumnStats {
+	results := make([]ColumnStats, len(p.columns))
+	for i, col := range p.columns {
+		results[i] = col.result()
+	}
+	return results
+}
+
+func Profile(r *csvparser.Reader, options Options) ([]ColumnStats, error) {
+	p := NewProfiler(r.Header(), options)
+	err := r.ForEach(func(record []string) error {
+		p.Add(record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.Results(), nil
+}
+
+type spaceSaving struct {
+	capacity int
+	counts   map[string]int
+}
+
+func newSpaceSaving(capacity int) *spaceSaving {
+	return &spaceSaving{capacity: capacity, counts: make(map[string]int)}
+}
+
+func (s *spaceSaving) add(value string) {
+	if _, ok := s.counts[value]; ok || len(s.counts) < s.capacity {
+		s.counts[value]++
+		return
+	}
+
+	minValue, minCount := "", -1
+	for v, c := range s.counts {
+		if minCount < 0 || c < minCount || c == minCount && v > minValue {
+			minValue, minCount = v, c
+		}
+	}
+	delete(s.counts, minValue)
+	s.counts[value] = minCount + 1
+}
+
+func (s *spaceSaving) values() []ValueCount {
+	result := make([]ValueCount, 0, len(s.counts))
+	for value, count := range s.counts {
+		result = append(result, ValueCount{Value: value, Count: count})
+	}
+	return result
+}
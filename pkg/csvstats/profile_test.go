@@ -0,0 +1,122 @@
+package csvstats
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+func TestProfile(t *testing.T) {
+	input := "id,name,score\n1,Ann,10\n2,Bob,20\n3,,30\n4,Ann,\n"
+	r, err := csvparser.NewReader(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	stats, err := Profile(r, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Profile() error = %v", err)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("Profile() returned %d columns, want 3", len(stats))
+	}
+
+	name := stats[1]
+	if name.Type != TypeString || name.Nulls != 1 || name.Distinct != 2 {
+		t.Errorf("name stats = %+v", name)
+	}
+	if len(name.Top) == 0 || name.Top[0] != (ValueCount{Value: "Ann", Count: 2}) {
+		t.Errorf("name top = %v, want Ann (2) first", name.Top)
+	}
+
+	score := stats[2]
+	if score.Type != TypeInteger || score.Min != "10" || score.Max != "30" {
+		t.Errorf("score stats = %+v", score)
+	}
+	if score.Mean == nil || *score.Mean != 20 {
+		t.Errorf("score mean = %v, want 20", score.Mean)
+	}
+	if score.StdDev == nil || *score.StdDev != 10 {
+		t.Errorf("score stddev = %v, want 10", score.StdDev)
+	}
+	if len(score.Quantiles) != 3 || score.Quantiles[1].Value != 20 {
+		t.Errorf("score quantiles = %v, want median 20", score.Quantiles)
+	}
+}
+
+func TestProfileApproximate(t *testing.T) {
+	options := DefaultOptions()
+	options.Approximate = true
+	options.SampleSize = 1000
+
+	p := NewProfiler([]string{"id", "bucket"}, options)
+	for i := 0; i < 50000; i++ {
+		p.Add([]string{fmt.Sprint(i), fmt.Sprint(i % 10)})
+	}
+	stats := p.Results()
+
+	id := stats[0]
+	if !id.DistinctApprox {
+		t.Error("DistinctApprox = false, want true")
+	}
+	if diff := math.Abs(float64(id.Distinct)-50000) / 50000; diff > 0.03 {
+		t.Errorf("approximate distinct = %d, want within 3%% of 50000", id.Distinct)
+	}
+	if stats[1].Distinct != 10 {
+		t.Errorf("bucket distinct = %d, want 10", stats[1].Distinct)
+	}
+	if len(stats[1].Top) != 5 || stats[1].Top[0].Count != 5000 {
+		t.Errorf("bucket top = %v, want five values with count 5000", stats[1].Top)
+	}
+}
+
+func TestProfileApproximateDates(t *testing.T) {
+	options := DefaultOptions()
+	options.Approximate = true
+
+	p := NewProfiler([]string{"when"}, options)
+	for _, value := range []string{"05/01/2024", "20/12/2023", "12/01/2024", ""} {
+		p.Add([]string{value})
+	}
+	when := p.Results()[0]
+	if when.Type != TypeDate || when.Min != "20/12/2023" || when.Max != "12/01/2024" {
+		t.Errorf("when stats = %+v, want date from 20/12/2023 to 12/01/2024", when)
+	}
+}
+
+func TestSpaceSavingTies(t *testing.T) {
+	for range 20 {
+		s := newSpaceSaving(3)
+		for _, value := range []string{"d", "a", "c", "b", "e"} {
+			s.add(value)
+		}
+		got := map[string]int{}
+		for _, vc := range s.values() {
+			got[vc.Value] = vc.Count
+		}
+		want := map[string]int{"a": 1, "b": 2, "e": 2}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("space saving counts = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	p := NewProfiler([]string{"n"}, DefaultOptions())
+	p.Add([]string{"1"})
+	p.Add([]string{"3"})
+
+	var buf strings.Builder
+	if err := WriteCSV(csvparser.NewWriter(&buf, nil), p.Results()); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	expected := "column,type,count,nulls,distinct,min,max,mean,stddev,p25,p50,p75,min_length,max_length,top\n" +
+		"n,integer,2,0,2,1,3,2,1.414214,1.5,2,2.5,1,1,1 (1); 3 (1)\n"
+	if buf.String() != expected {
+		t.Errorf("WriteCSV() = %q, want %q", buf.String(), expected)
+	}
+}
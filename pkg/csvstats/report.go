@@ -0,0 +1,115 @@
+package csvstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+func reportHeader(stats []ColumnStats) []string {
+	header := []string{"column", "type", "count", "nulls", "distinct", "min", "max", "mean", "stddev"}
+	header = append(header, quantileLabels(stats)...)
+	return append(header, "min_length", "max_length", "top")
+}
+
+func quantileLabels(stats []ColumnStats) []string {
+	for _, s := range stats {
+		if len(s.Quantiles) > 0 {
+			labels := make([]string, len(s.Quantiles))
+			for i, q := range s.Quantiles {
+				labels[i] = "p" + formatFloat(q.P*100)
+			}
+			return labels
+		}
+	}
+	return nil
+}
+
+func reportRow(s ColumnStats, quantiles int) []string {
+	distinct := strconv.Itoa(s.Distinct)
+	if s.DistinctApprox {
+		distinct = "~" + distinct
+	}
+
+	row := []string{
+		s.Name,
+		s.Type.String(),
+		strconv.Itoa(s.Count),
+		strconv.Itoa(s.Nulls),
+		distinct,
+		s.Min,
+		s.Max,
+		optionalFloat(s.Mean),
+		optionalFloat(s.StdDev),
+	}
+
+	for i := 0; i < quantiles; i++ {
+		if i < len(s.Quantiles) {
+			row = append(row, formatFloat(s.Quantiles[i].Value))
+		} else {
+			row = append(row, "")
+		}
+	}
+
+	top := make([]string, len(s.Top))
+	for i, vc := range s.Top {
+		top[i] = fmt.Sprintf("%s (%d)", vc.Value, vc.Count)
+	}
+
+	return append(row, strconv.Itoa(s.MinLength), strconv.Itoa(s.MaxLength), strings.Join(top, "; "))
+}
+
+func WriteTable(w io.Writer, stats []ColumnStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	quantiles := len(quantileLabels(stats))
+
+	fmt.Fprintln(tw, strings.Join(reportHeader(stats), "\t"))
+	for _, s := range stats {
+		row := reportRow(s, quantiles)
+		for i, v := range row {
+			if len(v) > 40 {
+				row[i] = v[:37] + "..."
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func WriteCSV(w *csvparser.Writer, stats []ColumnStats) error {
+	quantiles := len(quantileLabels(stats))
+
+	if err := w.WriteHeader(reportHeader(stats)); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		if err := w.Write(reportRow(s, quantiles)); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func WriteJSON(w io.Writer, stats []ColumnStats) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(stats)
+}
+
+func optionalFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return formatFloat(*f)
+}
+
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 6, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
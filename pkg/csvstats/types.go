@@ -0,0 +1,249 @@
+package csvstats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ValueType int
+
+const (
+	TypeNull ValueType = iota
+	TypeBoolean
+	TypeInteger
+	TypeNumber
+	TypeDate
+	TypeDateTime
+	TypeString
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case TypeNull:
+		return "null"
+	case TypeBoolean:
+		return "boolean"
+	case TypeInteger:
+		return "integer"
+	case TypeNumber:
+		return "number"
+	case TypeDate:
+		return "date"
+	case TypeDateTime:
+		return "datetime"
+	default:
+		return "string"
+	}
+}
+
+func (t ValueType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+var DateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"02/01/2006",
+	"01/02/2006",
+	"02.01.2006",
+	"Jan 2, 2006",
+	"2 Jan 2006",
+}
+
+var DateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+}
+
+var dateTimeLayouts = append(append([]string{}, DateLayouts...), DateTimeLayouts...)
+
+func ParseBool(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes":
+		return true, true
+	case "false", "no":
+		return false, true
+	}
+	return false, false
+}
+
+func IsInteger(value string) bool {
+	value = strings.TrimSpace(value)
+	digits := strings.TrimLeft(value, "+-")
+	if len(digits) > 1 && digits[0] == '0' {
+		return false
+	}
+	_, err := strconv.ParseInt(value, 10, 64)
+	return err == nil
+}
+
+func IsNumber(value string) bool {
+	value = strings.TrimSpace(value)
+	for _, r := range value {
+		if (r < '0' || r > '9') && r != '.' && r != '-' && r != '+' && r != 'e' && r != 'E' {
+			return false
+		}
+	}
+	digits := strings.TrimLeft(value, "+-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return false
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+func ParseDate(value string, layouts []string) (time.Time, string, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, layout, true
+		}
+	}
+	return time.Time{}, "", false
+}
+
+func InferType(value string) (ValueType, string) {
+	if strings.TrimSpace(value) == "" {
+		return TypeNull, ""
+	}
+	if _, ok := ParseBool(value); ok {
+		return TypeBoolean, ""
+	}
+	if IsInteger(value) {
+		return TypeInteger, ""
+	}
+	if IsNumber(value) {
+		return TypeNumber, ""
+	}
+	if _, layout, ok := ParseDate(value, DateLayouts); ok {
+		return TypeDate, layout
+	}
+	if _, layout, ok := ParseDate(value, DateTimeLayouts); ok {
+		return TypeDateTime, layout
+	}
+	return TypeString, ""
+}
+
+type TypeInferrer struct {
+	Type   ValueType
+	Layout string
+	mixed  bool
+}
+
+func (ti *TypeInferrer) Add(value string) {
+	if ti.Type == TypeString {
+		return
+	}
+
+	t, layout := InferType(value)
+	if t == TypeNull {
+		return
+	}
+
+	switch {
+	case ti.Type == TypeNull:
+		ti.Type, ti.Layout = t, layout
+	case ti.Type == t:
+		if layout != ti.Layout {
+			ti.mixed = true
+		}
+	case ti.Type == TypeInteger && t == TypeNumber, ti.Type == TypeNumber && t == TypeInteger:
+		ti.Type = TypeNumber
+	case ti.Type == TypeDate && t == TypeDateTime, ti.Type == TypeDateTime && t == TypeDate:
+		ti.Type = TypeDateTime
+		ti.mixed = true
+	default:
+		ti.Type = TypeString
+	}
+
+	if ti.mixed {
+		ti.Layout = ""
+	}
+}
+
+var strftime = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'j': "002",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'f': "000000",
+	'p': "PM",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'z': "-0700",
+	'Z': "MST",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'%': "%",
+}
+
+func Layout(format string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		if i+1 == len(format) {
+			return "", fmt.Errorf("invalid date format %q: trailing %%", format)
+		}
+		i++
+		layout, ok := strftime[format[i]]
+		if !ok {
+			return "", fmt.Errorf("invalid date format %q: unsupported directive %%%c", format, format[i])
+		}
+		b.WriteString(layout)
+	}
+	return b.String(), nil
+}
+
+var layoutTokens = []string{"January", "Monday", "-0700", "2006", "Jan", "Mon", "MST", "002", "_2", "01", "02", "03", "04", "05", "06", "15", "PM"}
+
+func StrftimeFormat(layout string) string {
+	directives := make(map[string]byte, len(strftime))
+	for directive, token := range strftime {
+		if directive != 'h' && directive != 'F' && directive != 'T' && directive != '%' {
+			directives[token] = directive
+		}
+	}
+
+	var b strings.Builder
+	for rest := layout; rest != ""; {
+		token := ""
+		for _, t := range layoutTokens {
+			if strings.HasPrefix(rest, t) {
+				token = t
+				break
+			}
+		}
+		switch {
+		case token != "":
+			b.WriteByte('%')
+			b.WriteByte(directives[token])
+			rest = rest[len(token):]
+		case strings.ContainsAny(rest[:1], "0123456789%"):
+			return ""
+		default:
+			b.WriteByte(rest[0])
+			rest = rest[1:]
+		}
+	}
+	return b.String()
+}
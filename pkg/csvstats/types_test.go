@@ -0,0 +1,81 @@
+package csvstats
+
+import "testing"
+
+func TestInferType(t *testing.T) {
+	tests := []struct {
+		value      string
+		wantType   ValueType
+		wantLayout string
+	}{
+		{"", TypeNull, ""},
+		{"  ", TypeNull, ""},
+		{"true", TypeBoolean, ""},
+		{"No", TypeBoolean, ""},
+		{"42", TypeInteger, ""},
+		{"-7", TypeInteger, ""},
+		{"007", TypeString, ""},
+		{"3.14", TypeNumber, ""},
+		{"0.5", TypeNumber, ""},
+		{"1e6", TypeNumber, ""},
+		{"NaN", TypeString, ""},
+		{"Inf", TypeString, ""},
+		{"2024-01-31", TypeDate, "2006-01-02"},
+		{"31/01/2024", TypeDate, "02/01/2006"},
+		{"2024-01-31T10:00:00Z", TypeDateTime, "2006-01-02T15:04:05.999999999Z07:00"},
+		{"2024-01-31 10:00:00", TypeDateTime, "2006-01-02 15:04:05"},
+		{"hello", TypeString, ""},
+	}
+
+	for _, tt := range tests {
+		gotType, gotLayout := InferType(tt.value)
+		if gotType != tt.wantType || gotLayout != tt.wantLayout {
+			t.Errorf("InferType(%q) = %v, %q, want %v, %q", tt.value, gotType, gotLayout, tt.wantType, tt.wantLayout)
+		}
+	}
+}
+
+func TestTypeInferrer(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   ValueType
+	}{
+		{"empty column", []string{"", ""}, TypeNull},
+		{"integers", []string{"1", "", "3"}, TypeInteger},
+		{"integers widen to number", []string{"1", "2.5"}, TypeNumber},
+		{"dates widen to datetime", []string{"2024-01-01", "2024-01-02 10:00:00"}, TypeDateTime},
+		{"mixed falls back to string", []string{"1", "abc"}, TypeString},
+		{"booleans", []string{"yes", "no", "true"}, TypeBoolean},
+	}
+
+	for _, tt := range tests {
+		var ti TypeInferrer
+		for _, v := range tt.values {
+			ti.Add(v)
+		}
+		if ti.Type != tt.want {
+			t.Errorf("%s: Type = %v, want %v", tt.name, ti.Type, tt.want)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%Y-%m-%d", "2006-01-02"},
+		{"%d/%m/%y %H:%M:%S", "02/01/06 15:04:05"},
+		{"%b %d, %Y %I:%M %p", "Jan 02, 2006 03:04 PM"},
+		{"100%%", "100%"},
+	}
+	for _, tt := range tests {
+		if got, err := Layout(tt.format); err != nil || got != tt.want {
+			t.Errorf("Layout(%q) = %q, %v, want %q", tt.format, got, err, tt.want)
+		}
+	}
+	if _, err := Layout("%Y-%"); err == nil {
+		t.Error("Layout(trailing %) error = nil, want error")
+	}
+}
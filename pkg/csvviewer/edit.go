@@ -0,0 +1,374 @@
+package csvviewer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csveditor"
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func editText(text string, msg tea.KeyMsg) string {
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if len(text) > 0 {
+			runes := []rune(text)
+			return string(runes[:len(runes)-1])
+		}
+	case tea.KeyCtrlU:
+		return ""
+	case tea.KeySpace:
+		return text + " "
+	case tea.KeyRunes:
+		return text + string(msg.Runes)
+	}
+	return text
+}
+
+func (m Model) handleEditInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.applyEdit()
+		m.mode = normalMode
+	case "esc":
+		m.mode = normalMode
+		m.statusMessage = "Edit cancelled"
+	default:
+		m.editInput = editText(m.editInput, msg)
+	}
+	return m, nil
+}
+
+func (m Model) handleCommandInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.mode = normalMode
+		return m.runCommand(strings.TrimSpace(m.commandInput))
+	case "esc":
+		m.mode = normalMode
+	default:
+		m.commandInput = editText(m.commandInput, msg)
+	}
+	return m, nil
+}
+
+func (m Model) runCommand(command string) (tea.Model, tea.Cmd) {
+	name, arg, _ := strings.Cut(command, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "w":
+		m.save(arg)
+	case "wq", "x":
+		if m.save(arg) {
+			return m, tea.Quit
+		}
+	case "q":
+		if m.modified() {
+			m.statusMessage = "Unsaved changes (use :w to save or :q! to discard)"
+			return m, nil
+		}
+		return m, tea.Quit
+	case "q!":
+		return m, tea.Quit
+	case "":
+	default:
+		m.statusMessage = fmt.Sprintf("Unknown command: %s", name)
+	}
+	return m, nil
+}
+
+func (m Model) handleConfirmQuit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		if m.save("") {
+			return m, tea.Quit
+		}
+		m.mode = normalMode
+	case "n", "N", "ctrl+c":
+		return m, tea.Quit
+	case "esc", "c":
+		m.mode = normalMode
+		m.statusMessage = ""
+	}
+	return m, nil
+}
+
+func (m *Model) save(filename string) bool {
+	if filename == "" {
+		filename = m.filename
+	}
+	if csvparser.IsXLSX(filename) {
+		m.statusMessage = "Cannot write .xlsx files; use :w FILE.csv"
+		return false
+	}
+	if err := m.csv.WriteToFile(filename, m.config); err != nil {
+		m.statusMessage = fmt.Sprintf("Error saving: %v", err)
+		return false
+	}
+	if filename == m.filename {
+		m.savedVersion = m.journal.Version()
+	}
+	m.statusMessage = fmt.Sprintf("Saved %d rows to %s", len(m.csv.Records), filename)
+	return true
+}
+
+func (m *Model) sourceRow(row int) int {
+	if m.filteredRows != nil {
+		return m.filteredRows[row]
+	}
+	return row
+}
+
+func (m *Model) editLabel() string {
+	if m.editingHeader {
+		return fmt.Sprintf("Column %d name", m.selectedCol+1)
+	}
+	return fmt.Sprintf("%s (row %d)", m.csv.Header[m.selectedCol], m.sourceRow(m.selectedRow)+1)
+}
+
+func (m *Model) startEdit(header bool) {
+	current := m.getCurrentCSV()
+	if len(current.Header) == 0 {
+		return
+	}
+	if header {
+		m.editInput = current.Header[m.selectedCol]
+	} else {
+		if m.selectedRow >= len(current.Records) {
+			m.statusMessage = "No row to edit (o: add a row)"
+			return
+		}
+		m.editInput = ""
+		if record := current.Records[m.selectedRow]; m.selectedCol < len(record) {
+			m.editInput = record[m.selectedCol]
+		}
+	}
+	m.editingHeader = header
+	m.mode = editMode
+	m.statusMessage = ""
+}
+
+func (m *Model) applyEdit() {
+	if m.editingHeader {
+		if m.editInput == "" || m.csv.Header[m.selectedCol] == m.editInput {
+			return
+		}
+		if m.apply(&csveditor.RenameColumnOp{Column: m.csv.Header[m.selectedCol], Name: m.editInput}) {
+			m.statusMessage = fmt.Sprintf("Renamed column %d to %q", m.selectedCol+1, m.editInput)
+		}
+		return
+	}
+
+	row := m.sourceRow(m.selectedRow)
+	if record := m.csv.Records[row]; m.selectedCol < len(record) && record[m.selectedCol] == m.editInput {
+		return
+	}
+	m.apply(&csveditor.SetCellOp{Row: row, Column: m.csv.Header[m.selectedCol], Value: m.editInput})
+}
+
+func (m *Model) insertRow(viewRow int) {
+	if len(m.csv.Header) == 0 {
+		m.statusMessage = "Add a column first (a)"
+		return
+	}
+	current := m.getCurrentCSV()
+	viewRow = min(viewRow, len(current.Records))
+
+	source := len(m.csv.Records)
+	if viewRow < len(current.Records) {
+		source = m.sourceRow(viewRow)
+	} else if viewRow > 0 {
+		source = m.sourceRow(viewRow-1) + 1
+	}
+	if m.apply(&csveditor.InsertRowOp{Index: source}) {
+		m.statusMessage = fmt.Sprintf("Inserted row %d", source+1)
+	}
+}
+
+func (m *Model) deleteRow() {
+	if m.selectedRow >= len(m.getCurrentCSV().Records) {
+		return
+	}
+	source := m.sourceRow(m.selectedRow)
+	if m.apply(&csveditor.DeleteRowOp{Index: source}) {
+		m.statusMessage = fmt.Sprintf("Deleted row %d", source+1)
+	}
+}
+
+func (m *Model) insertColumn(index int) {
+	index = min(index, len(m.csv.Header))
+	name := fmt.Sprintf("column%d", len(m.csv.Header)+1)
+	for n := len(m.csv.Header) + 2; slices.Contains(m.csv.Header, name); n++ {
+		name = fmt.Sprintf("column%d", n)
+	}
+	if !m.apply(&csveditor.InsertColumnOp{Index: index, Name: name}) {
+		return
+	}
+	m.startEdit(true)
+	m.editInput = ""
+}
+
+func (m *Model) deleteColumn() {
+	if len(m.csv.Header) <= 1 {
+		m.statusMessage = "Cannot delete the last column"
+		return
+	}
+	name := m.csv.Header[m.selectedCol]
+	if m.apply(&csveditor.DeleteColumnOp{Column: name}) {
+		m.statusMessage = fmt.Sprintf("Deleted column %q", name)
+	}
+}
+
+func (m *Model) apply(op csveditor.Operation) bool {
+	entry, err := m.journal.Apply(op)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Error: %v", err)
+		return false
+	}
+	m.syncView(op, entry.Undo)
+	return true
+}
+
+func (m *Model) undo() {
+	entry, ok, err := m.journal.Undo()
+	switch {
+	case err != nil:
+		m.statusMessage = fmt.Sprintf("Error: %v", err)
+	case !ok:
+		m.statusMessage = "Nothing to undo"
+	default:
+		m.syncView(entry.Undo, entry.Do)
+		m.statusMessage = fmt.Sprintf("Undo: %s", entry.Undo)
+	}
+}
+
+func (m *Model) redo() {
+	entry, ok, err := m.journal.Redo()
+	switch {
+	case err != nil:
+		m.statusMessage = fmt.Sprintf("Error: %v", err)
+	case !ok:
+		m.statusMessage = "Nothing to redo"
+	default:
+		m.syncView(entry.Do, entry.Undo)
+		m.statusMessage = fmt.Sprintf("Redo: %s", entry.Do)
+	}
+}
+
+func (m *Model) modified() bool {
+	return m.journal.Version() != m.savedVersion
+}
+
+func (m *Model) syncView(op, inverse csveditor.Operation) {
+	filtered := m.filteredRows != nil
+	row, col := -1, -1
+	delta := 0
+	resort := false
+	switch op := op.(type) {
+	case *csveditor.SetCellOp:
+		row, col = op.Row, slices.Index(m.csv.Header, op.Column)
+		resort = col == m.sortCol
+	case *csveditor.SetColumnOp:
+		col = slices.Index(m.csv.Header, op.Column)
+		resort = col == m.sortCol
+	case *csveditor.RenameColumnOp:
+		col = slices.Index(m.csv.Header, op.Name)
+	case *csveditor.InsertColumnOp:
+		col, delta = op.Index, 1
+	case *csveditor.DeleteColumnOp:
+		col, delta = inverse.(*csveditor.InsertColumnOp).Index, -1
+	case *csveditor.InsertRowOp:
+		row = op.Index
+		if filtered {
+			for i, r := range m.filteredRows {
+				if r >= op.Index {
+					m.filteredRows[i] = r + 1
+				}
+			}
+			if m.sortCol >= 0 {
+				m.filteredRows = append(m.filteredRows, op.Index)
+				resort = true
+			} else {
+				pos, _ := slices.BinarySearch(m.filteredRows, op.Index)
+				m.filteredRows = slices.Insert(m.filteredRows, pos, op.Index)
+			}
+		}
+	case *csveditor.DeleteRowOp:
+		if filtered {
+			row = len(m.filteredRows)
+			if pos := slices.Index(m.filteredRows, op.Index); pos >= 0 {
+				row = pos
+				m.filteredRows = slices.Delete(m.filteredRows, pos, pos+1)
+			}
+			for i, r := range m.filteredRows {
+				if r > op.Index {
+					m.filteredRows[i] = r - 1
+				}
+			}
+		} else {
+			row = op.Index
+		}
+	}
+
+	m.shiftLayout(col, delta)
+	if m.shiftColumns(col, delta) {
+		m.rebuildView()
+		filtered = false
+		row = -1
+	} else if resort {
+		m.sortRows()
+	}
+	m.refreshFiltered()
+	m.refreshSearch()
+
+	if row >= 0 {
+		if _, deleted := op.(*csveditor.DeleteRowOp); filtered && !deleted {
+			row = max(slices.Index(m.filteredRows, row), 0)
+		}
+		m.selectedRow = row
+	}
+	if col >= 0 {
+		m.selectedCol = col
+	}
+	m.selectedRow = max(min(m.selectedRow, len(m.getCurrentCSV().Records)-1), 0)
+	m.selectedCol = max(min(m.selectedCol, len(m.csv.Header)-1), 0)
+	m.ensureRowVisible()
+	m.ensureColumnVisible()
+}
+
+func (m *Model) ensureRowVisible() {
+	visibleRows := m.getVisibleRows()
+	if m.selectedRow < m.scrollOffsetRow {
+		m.scrollOffsetRow = m.selectedRow
+	}
+	if m.selectedRow >= m.scrollOffsetRow+visibleRows {
+		m.scrollOffsetRow = m.selectedRow - visibleRows + 1
+	}
+	if m.wrap && m.selectedRow < len(m.getCurrentCSV().Records) {
+		colWidths := m.columnWidths()
+		lines := 0
+		for row := m.scrollOffsetRow; row <= m.selectedRow; row++ {
+			lines += m.rowHeight(row, colWidths)
+		}
+		for m.scrollOffsetRow < m.selectedRow && lines > visibleRows {
+			lines -= m.rowHeight(m.scrollOffsetRow, colWidths)
+			m.scrollOffsetRow++
+		}
+	}
+}
+
+func (m *Model) ensureColumnVisible() {
+	if m.selectedCol < m.frozenCols {
+		return
+	}
+	if m.selectedCol < m.scrollOffsetCol {
+		m.scrollOffsetCol = m.selectedCol
+	}
+	colWidths := m.columnWidths()
+	for m.scrollOffsetCol < m.selectedCol && !slices.Contains(m.displayColumns(colWidths), m.selectedCol) {
+		m.scrollOffsetCol++
+	}
+}
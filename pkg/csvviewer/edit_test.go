@@ -0,0 +1,80 @@
+package csvviewer
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEditKeys(t *testing.T) {
+	original := "Name,Age,City|John,30,NYC;Jane,25,LA;Bob,35,SF;Ann,28,LA"
+	tests := []struct {
+		name         string
+		keys         []string
+		want         string
+		wantModified bool
+	}{
+		{"edit cell", []string{"l", "e", "ctrl+u", "4", "0", "enter"}, "Name,Age,City|John,40,NYC;Jane,25,LA;Bob,35,SF;Ann,28,LA", true},
+		{"cancel edit", []string{"e", "ctrl+u", "X", "esc"}, original, false},
+		{"unchanged edit", []string{"e", "enter"}, original, false},
+		{"undo edit", []string{"e", "backspace", "enter", "u"}, original, false},
+		{"redo edit", []string{"e", "backspace", "enter", "u", "ctrl+r"}, "Name,Age,City|Joh,30,NYC;Jane,25,LA;Bob,35,SF;Ann,28,LA", true},
+		{"insert row below", []string{"o"}, "Name,Age,City|John,30,NYC;,,;Jane,25,LA;Bob,35,SF;Ann,28,LA", true},
+		{"insert row above", []string{"j", "O"}, "Name,Age,City|John,30,NYC;,,;Jane,25,LA;Bob,35,SF;Ann,28,LA", true},
+		{"delete row", []string{"j", "D"}, "Name,Age,City|John,30,NYC;Bob,35,SF;Ann,28,LA", true},
+		{"rename column", []string{"E", "ctrl+u", "W", "h", "o", "enter"}, "Who,Age,City|John,30,NYC;Jane,25,LA;Bob,35,SF;Ann,28,LA", true},
+		{"rename to an existing name", []string{"E", "ctrl+u", "A", "g", "e", "enter"}, original, false},
+		{"add column", []string{"a", "Z", "i", "p", "enter"}, "Name,Zip,Age,City|John,,30,NYC;Jane,,25,LA;Bob,,35,SF;Ann,,28,LA", true},
+		{"delete column", []string{"l", "X"}, "Name,City|John,NYC;Jane,LA;Bob,SF;Ann,LA", true},
+		{"undo delete column", []string{"l", "X", "u"}, original, false},
+		{"undo everything", []string{"D", "X", "o", "u", "u", "u", "u"}, original, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := press(t, testModel(), tt.keys...)
+			if got := viewString(m); got != tt.want {
+				t.Errorf("after %v = %s, want %s", tt.keys, got, tt.want)
+			}
+			if m.modified() != tt.wantModified {
+				t.Errorf("modified() = %v, want %v", m.modified(), tt.wantModified)
+			}
+			if m.mode != normalMode {
+				t.Errorf("mode = %d, want normal mode", m.mode)
+			}
+		})
+	}
+}
+
+func TestConfirmQuit(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		wantQuit bool
+		wantMode viewMode
+	}{
+		{"unmodified quits", []string{"q"}, true, normalMode},
+		{"modified asks", []string{"D", "q"}, false, confirmQuitMode},
+		{"no quits", []string{"D", "q", "n"}, true, confirmQuitMode},
+		{"ctrl+c quits without saving", []string{"D", "ctrl+c", "ctrl+c"}, true, confirmQuitMode},
+		{"esc cancels", []string{"D", "q", "esc"}, false, normalMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := press(t, testModel(), tt.keys[:len(tt.keys)-1]...)
+			next, cmd := m.Update(keyMsg(tt.keys[len(tt.keys)-1]))
+			m = next.(Model)
+			quit := false
+			if cmd != nil {
+				_, quit = cmd().(tea.QuitMsg)
+			}
+			if quit != tt.wantQuit {
+				t.Errorf("after %v quit = %v, want %v", tt.keys, quit, tt.wantQuit)
+			}
+			if m.mode != tt.wantMode {
+				t.Errorf("mode = %d, want %d", m.mode, tt.wantMode)
+			}
+		})
+	}
+}
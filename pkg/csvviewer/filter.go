@@ -0,0 +1,287 @@
+package csvviewer
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sean-stapleton-doyle/csvtk/pkg/csveditor"
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+)
+
+type columnFilter struct {
+	col      int
+	operator string
+	value    string
+	strategy csveditor.FilterStrategy
+}
+
+var filterSymbols = []struct {
+	symbol   string
+	operator string
+}{
+	{">=", ">="},
+	{"<=", "<="},
+	{"==", "=="},
+	{"!=", "!="},
+	{"=~", "regex"},
+	{">", ">"},
+	{"<", "<"},
+	{"=", "equals"},
+}
+
+var filterWords = []string{
+	"equals", "eq", "not-equals", "ne", "contains",
+	"starts-with", "startswith", "ends-with", "endswith",
+	"regex", "regexp", "gt", "lt", "gte", "lte",
+}
+
+func parseColumnFilter(col int, input string) (columnFilter, error) {
+	operator, value := "contains", strings.TrimSpace(input)
+	if word, rest, ok := strings.Cut(value, " "); ok && slices.Contains(filterWords, word) {
+		operator, value = word, strings.TrimSpace(rest)
+	} else {
+		for _, s := range filterSymbols {
+			if rest, ok := strings.CutPrefix(value, s.symbol); ok {
+				operator, value = s.operator, strings.TrimSpace(rest)
+				break
+			}
+		}
+	}
+
+	strategy := csveditor.NewFilterStrategy(operator)
+	if _, err := strategy.Match("0", value); err != nil {
+		return columnFilter{}, err
+	}
+	return columnFilter{col: col, operator: operator, value: value, strategy: strategy}, nil
+}
+
+func (f columnFilter) match(record []string) bool {
+	value := ""
+	if f.col < len(record) {
+		value = record[f.col]
+	}
+	ok, err := f.strategy.Match(value, f.value)
+	return ok && err == nil
+}
+
+func (f columnFilter) label(header []string) string {
+	return fmt.Sprintf("%s %s %q", header[f.col], f.operator, f.value)
+}
+
+func (m *Model) active() bool {
+	return m.filterText != "" || len(m.columnFilters) > 0 || m.sortCol >= 0
+}
+
+func (m *Model) matches(record []string) bool {
+	if m.filterText != "" {
+		found := false
+		search := strings.ToLower(m.filterText)
+		for _, cell := range record {
+			if strings.Contains(strings.ToLower(cell), search) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, f := range m.columnFilters {
+		if !f.match(record) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Model) rebuildView() {
+	m.filteredRows = nil
+	if m.active() {
+		m.filteredRows = []int{}
+		for i, record := range m.csv.Records {
+			if m.matches(record) {
+				m.filteredRows = append(m.filteredRows, i)
+			}
+		}
+		m.sortRows()
+	}
+	m.refreshFiltered()
+	m.refreshSearch()
+	m.selectedRow = 0
+	m.scrollOffsetRow = 0
+}
+
+func (m *Model) sortRows() {
+	if m.sortCol < 0 || m.filteredRows == nil {
+		return
+	}
+	key := csveditor.SortKey{Descending: m.sortDesc, Type: csveditor.SortCaseInsensitive}
+	if m.numericColumn(m.sortCol) {
+		key.Type = csveditor.SortNumeric
+	}
+	csveditor.SortIndices(m.csv.Records, m.filteredRows, m.sortCol, key)
+}
+
+func (m *Model) numericColumn(col int) bool {
+	numeric := false
+	for _, record := range m.csv.Records {
+		if col >= len(record) || strings.TrimSpace(record[col]) == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(record[col]), 64); err != nil {
+			return false
+		}
+		numeric = true
+	}
+	return numeric
+}
+
+func (m *Model) toggleSort() {
+	if len(m.csv.Header) == 0 {
+		return
+	}
+	source := -1
+	if m.selectedRow < len(m.getCurrentCSV().Records) {
+		source = m.sourceRow(m.selectedRow)
+	}
+
+	if m.sortCol == m.selectedCol {
+		m.sortDesc = !m.sortDesc
+	} else {
+		m.sortCol, m.sortDesc = m.selectedCol, false
+	}
+	m.rebuildView()
+	m.selectSource(source)
+
+	order := "ascending"
+	if m.sortDesc {
+		order = "descending"
+	}
+	m.statusMessage = fmt.Sprintf("Sorted by %s (%s)", m.csv.Header[m.sortCol], order)
+}
+
+func (m *Model) clearSort() {
+	if m.sortCol < 0 {
+		return
+	}
+	source := -1
+	if m.selectedRow < len(m.getCurrentCSV().Records) {
+		source = m.sourceRow(m.selectedRow)
+	}
+	m.sortCol, m.sortDesc = -1, false
+	m.rebuildView()
+	m.selectSource(source)
+	m.statusMessage = "Sort cleared"
+}
+
+func (m *Model) selectSource(source int) {
+	if source < 0 {
+		return
+	}
+	m.selectedRow = source
+	if m.filteredRows != nil {
+		m.selectedRow = max(slices.Index(m.filteredRows, source), 0)
+	}
+	m.ensureRowVisible()
+}
+
+func (m *Model) startColumnFilter() {
+	if len(m.csv.Header) == 0 {
+		return
+	}
+	m.mode = filterInputMode
+	m.filterInput = ""
+	m.filterColumn = m.selectedCol
+	m.statusMessage = "Filter " + m.csv.Header[m.selectedCol] + ": text, >N, <=N, == N, = text, ne text, starts-with text, =~ regex"
+}
+
+func (m *Model) applyFilter() {
+	if m.filterColumn < 0 {
+		m.filterText = m.filterInput
+	} else {
+		f, err := parseColumnFilter(m.filterColumn, m.filterInput)
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("Invalid filter: %v", err)
+			return
+		}
+		m.columnFilters = append(m.columnFilters, f)
+	}
+	m.rebuildView()
+	m.statusMessage = fmt.Sprintf("Filtered: %d of %d rows", len(m.getCurrentCSV().Records), len(m.csv.Records))
+}
+
+func (m *Model) dropColumnFilter() {
+	if len(m.columnFilters) == 0 {
+		m.statusMessage = "No column filters"
+		return
+	}
+	m.columnFilters = m.columnFilters[:len(m.columnFilters)-1]
+	m.rebuildView()
+	m.statusMessage = fmt.Sprintf("Filtered: %d of %d rows", len(m.getCurrentCSV().Records), len(m.csv.Records))
+}
+
+func (m *Model) resetFilters() {
+	m.filterText = ""
+	m.columnFilters = nil
+	m.rebuildView()
+	m.statusMessage = "Filters cleared"
+}
+
+func (m *Model) shiftColumns(index, delta int) bool {
+	if delta == 0 {
+		return false
+	}
+	shift := columnShift(index, delta)
+
+	removed := false
+	if m.sortCol >= 0 {
+		var ok bool
+		if m.sortCol, ok = shift(m.sortCol); !ok {
+			m.sortDesc = false
+			removed = true
+		}
+	}
+	filters := m.columnFilters[:0]
+	for _, f := range m.columnFilters {
+		var ok bool
+		if f.col, ok = shift(f.col); ok {
+			filters = append(filters, f)
+		} else {
+			removed = true
+		}
+	}
+	m.columnFilters = filters
+	return removed
+}
+
+func (m *Model) refreshFiltered() {
+	if m.filteredRows == nil {
+		m.filteredCSV = nil
+		return
+	}
+	records := make([][]string, len(m.filteredRows))
+	for i, row := range m.filteredRows {
+		records[i] = m.csv.Records[row]
+	}
+	m.filteredCSV = &csvparser.CSV{Header: m.csv.Header, Records: records}
+}
+
+func (m *Model) viewChips() []string {
+	var chips []string
+	if m.sortCol >= 0 {
+		arrow := "↑"
+		if m.sortDesc {
+			arrow = "↓"
+		}
+		chips = append(chips, fmt.Sprintf("sort %s %s", arrow, m.csv.Header[m.sortCol]))
+	}
+	if m.filterText != "" {
+		chips = append(chips, fmt.Sprintf("any column contains %q", m.filterText))
+	}
+	for _, f := range m.columnFilters {
+		chips = append(chips, f.label(m.csv.Header))
+	}
+	return chips
+}
@@ -0,0 +1,150 @@
+package csvviewer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseColumnFilter(t *testing.T) {
+	tests := []struct {
+		input        string
+		wantOperator string
+		wantValue    string
+		wantErr      bool
+	}{
+		{"LA", "contains", "LA", false},
+		{"  New York ", "contains", "New York", false},
+		{">30", ">", "30", false},
+		{">= 30", ">=", "30", false},
+		{"<=30", "<=", "30", false},
+		{"== 30", "==", "30", false},
+		{"!=30", "!=", "30", false},
+		{"= LA", "equals", "LA", false},
+		{"=~ ^J", "regex", "^J", false},
+		{"ne Bob", "ne", "Bob", false},
+		{"starts-with Ja", "starts-with", "Ja", false},
+		{"gte 25", "gte", "25", false},
+		{"unknown word", "contains", "unknown word", false},
+		{"> abc", "", "", true},
+		{"=~ [", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			f, err := parseColumnFilter(1, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseColumnFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if f.col != 1 || f.operator != tt.wantOperator || f.value != tt.wantValue {
+				t.Errorf("parseColumnFilter() = (%d, %q, %q), want (1, %q, %q)", f.col, f.operator, f.value, tt.wantOperator, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestColumnFilterKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		want string
+	}{
+		{"numeric", []string{"l", "F", ">", "2", "8", "enter"}, "Name,Age,City|John,30,NYC;Bob,35,SF"},
+		{"two filters", []string{"l", "F", ">", "2", "6", "enter", "l", "F", "=", "L", "A", "enter"}, "Name,Age,City|Ann,28,LA"},
+		{"drop last filter", []string{"l", "F", ">", "2", "6", "enter", "l", "F", "=", "L", "A", "enter", "R"}, "Name,Age,City|John,30,NYC;Bob,35,SF;Ann,28,LA"},
+		{"invalid filter", []string{"l", "F", ">", "x", "enter"}, "Name,Age,City|John,30,NYC;Jane,25,LA;Bob,35,SF;Ann,28,LA"},
+		{"any column", []string{"f", "a", "n", "enter"}, "Name,Age,City|Jane,25,LA;Ann,28,LA"},
+		{"reset", []string{"f", "a", "n", "enter", "r"}, "Name,Age,City|John,30,NYC;Jane,25,LA;Bob,35,SF;Ann,28,LA"},
+		{"sort numeric", []string{"l", "s"}, "Name,Age,City|Jane,25,LA;Ann,28,LA;John,30,NYC;Bob,35,SF"},
+		{"sort text descending", []string{"s", "s"}, "Name,Age,City|John,30,NYC;Jane,25,LA;Bob,35,SF;Ann,28,LA"},
+		{"clear sort", []string{"s", "S"}, "Name,Age,City|John,30,NYC;Jane,25,LA;Bob,35,SF;Ann,28,LA"},
+		{"delete filtered column", []string{"l", "F", ">", "2", "8", "enter", "X"}, "Name,City|John,NYC;Jane,LA;Bob,SF;Ann,LA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := press(t, testModel(), tt.keys...)
+			if got := viewString(m); got != tt.want {
+				t.Errorf("after %v = %s, want %s", tt.keys, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncViewFilteredRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []string
+		want    string
+		wantMap []int
+		wantRow int
+	}{
+		{
+			"filter",
+			[]string{"l", "l", "F", "L", "A", "enter"},
+			"Name,Age,City|Jane,25,LA;Ann,28,LA", []int{1, 3}, 0,
+		},
+		{
+			"insert under filter",
+			[]string{"l", "l", "F", "L", "A", "enter", "o"},
+			"Name,Age,City|Jane,25,LA;,,;Ann,28,LA", []int{1, 3, 4}, 1,
+		},
+		{
+			"insert then delete under filter",
+			[]string{"l", "l", "F", "L", "A", "enter", "o", "D"},
+			"Name,Age,City|Jane,25,LA;Ann,28,LA", []int{1, 3}, 1,
+		},
+		{
+			"delete under filter",
+			[]string{"l", "l", "F", "L", "A", "enter", "D"},
+			"Name,Age,City|Ann,28,LA", []int{2}, 0,
+		},
+		{
+			"undo delete under filter",
+			[]string{"l", "l", "F", "L", "A", "enter", "D", "u"},
+			"Name,Age,City|Jane,25,LA;Ann,28,LA", []int{1, 3}, 0,
+		},
+		{
+			"sort descending under filter",
+			[]string{"l", "l", "F", "L", "A", "enter", "h", "s", "s"},
+			"Name,Age,City|Ann,28,LA;Jane,25,LA", []int{3, 1}, 1,
+		},
+		{
+			"delete under sort and filter",
+			[]string{"l", "l", "F", "L", "A", "enter", "h", "s", "s", "D"},
+			"Name,Age,City|Ann,28,LA", []int{2}, 0,
+		},
+		{
+			"undo delete under sort and filter",
+			[]string{"l", "l", "F", "L", "A", "enter", "h", "s", "s", "D", "u"},
+			"Name,Age,City|Ann,28,LA;Jane,25,LA", []int{3, 1}, 1,
+		},
+		{
+			"edit inserted row under sort and filter",
+			[]string{"l", "l", "F", "L", "A", "enter", "h", "s", "s", "k", "O", "e", "9", "9", "enter"},
+			"Name,Age,City|,99,;Ann,28,LA;Jane,25,LA", []int{3, 4, 1}, 0,
+		},
+		{
+			"edit moves the row under sort",
+			[]string{"l", "s", "e", "ctrl+u", "9", "9", "enter"},
+			"Name,Age,City|Jane,25,LA;Ann,28,LA;Bob,35,SF;John,99,NYC", []int{1, 3, 2, 0}, 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := press(t, testModel(), tt.keys...)
+			if got := viewString(m); got != tt.want {
+				t.Errorf("view = %s, want %s", got, tt.want)
+			}
+			if !slices.Equal(m.filteredRows, tt.wantMap) {
+				t.Errorf("filteredRows = %v, want %v", m.filteredRows, tt.wantMap)
+			}
+			if m.selectedRow != tt.wantRow {
+				t.Errorf("selectedRow = %d, want %d", m.selectedRow, tt.wantRow)
+			}
+		})
+	}
+}
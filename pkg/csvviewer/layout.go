@@ -0,0 +1,277 @@
+package csvviewer
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	minColumnWidth = 4
+	maxColumnWidth = 200
+	widthStep      = 4
+)
+
+func (m Model) displayColumns(colWidths []int) []int {
+	available := m.width - 4
+	used := 0
+	var cols []int
+	add := func(col int) bool {
+		if len(cols) > 0 && used+colWidths[col]+2 > available {
+			return false
+		}
+		cols = append(cols, col)
+		used += colWidths[col] + 2
+		return true
+	}
+
+	frozen := min(m.frozenCols, len(colWidths))
+	for col := range frozen {
+		if !m.hiddenCols[col] {
+			add(col)
+		}
+	}
+	for col := max(m.scrollOffsetCol, frozen); col < len(colWidths); col++ {
+		if m.hiddenCols[col] {
+			continue
+		}
+		if !add(col) {
+			break
+		}
+	}
+	return cols
+}
+
+func truncate(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:max(width-3, 0)]) + "..."
+}
+
+func wrapCell(text string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		runes := []rune(line)
+		for len(runes) > width {
+			lines = append(lines, string(runes[:width]))
+			runes = runes[width:]
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+func (m Model) cellLines(record []string, col, width int) []string {
+	text := ""
+	if col < len(record) {
+		text = record[col]
+	}
+	if m.wrap {
+		return wrapCell(text, width)
+	}
+	return []string{truncate(strings.ReplaceAll(text, "\n", " "), width)}
+}
+
+func (m Model) rowHeight(row int, colWidths []int) int {
+	if !m.wrap {
+		return 1
+	}
+	record := m.getCurrentCSV().Records[row]
+	height := 1
+	for _, col := range m.displayColumns(colWidths) {
+		height = max(height, len(m.cellLines(record, col, colWidths[col])))
+	}
+	return min(height, m.tableRows())
+}
+
+func (m *Model) moveColumn(step int) {
+	for col := m.selectedCol + step; col >= 0 && col < len(m.csv.Header); col += step {
+		if m.showDetail || !m.hiddenCols[col] {
+			m.selectedCol = col
+			m.ensureColumnVisible()
+			return
+		}
+	}
+}
+
+func (m *Model) toggleFreeze() {
+	if len(m.csv.Header) == 0 {
+		return
+	}
+	if m.frozenCols == m.selectedCol+1 {
+		m.frozenCols = 0
+		m.statusMessage = "Columns unfrozen"
+	} else {
+		m.frozenCols = m.selectedCol + 1
+		m.statusMessage = fmt.Sprintf("Froze %d columns", m.frozenCols)
+	}
+	m.ensureColumnVisible()
+}
+
+func (m *Model) resizeColumn(delta int) {
+	if len(m.csv.Header) == 0 {
+		return
+	}
+	width := m.columnWidths()[m.selectedCol] + delta
+	width = max(min(width, maxColumnWidth), minColumnWidth)
+	if m.colWidths == nil {
+		m.colWidths = map[int]int{}
+	}
+	m.colWidths[m.selectedCol] = width
+	m.ensureColumnVisible()
+	m.statusMessage = fmt.Sprintf("Column %s width %d", m.csv.Header[m.selectedCol], width)
+}
+
+func (m *Model) autoWidth() {
+	if _, ok := m.colWidths[m.selectedCol]; ok {
+		delete(m.colWidths, m.selectedCol)
+		m.statusMessage = "Column width reset"
+	}
+}
+
+func (m *Model) hideColumn() {
+	if len(m.csv.Header) == 0 {
+		return
+	}
+	if len(m.csv.Header)-len(m.hiddenCols) <= 1 {
+		m.statusMessage = "Cannot hide the last visible column"
+		return
+	}
+	if m.hiddenCols == nil {
+		m.hiddenCols = map[int]bool{}
+	}
+	name := m.csv.Header[m.selectedCol]
+	m.hiddenCols[m.selectedCol] = true
+	if col := m.selectedCol; !m.showDetail {
+		m.moveColumn(1)
+		if m.selectedCol == col {
+			m.moveColumn(-1)
+		}
+	}
+	m.refreshSearch()
+	m.statusMessage = fmt.Sprintf("Hid column %q (+: show all)", name)
+}
+
+func (m *Model) unhideColumns() {
+	if len(m.hiddenCols) == 0 {
+		return
+	}
+	m.statusMessage = fmt.Sprintf("Showing %d hidden columns", len(m.hiddenCols))
+	m.hiddenCols = nil
+	m.refreshSearch()
+	m.ensureColumnVisible()
+}
+
+func columnShift(index, delta int) func(col int) (int, bool) {
+	return func(col int) (int, bool) {
+		switch {
+		case delta < 0 && col == index:
+			return -1, false
+		case delta > 0 && col >= index, delta < 0 && col > index:
+			return col + delta, true
+		}
+		return col, true
+	}
+}
+
+func (m *Model) shiftLayout(index, delta int) {
+	if delta == 0 {
+		return
+	}
+	if index < m.frozenCols {
+		m.frozenCols += delta
+	}
+	shift := columnShift(index, delta)
+
+	widths := map[int]int{}
+	for col, width := range m.colWidths {
+		if col, ok := shift(col); ok {
+			widths[col] = width
+		}
+	}
+	m.colWidths = widths
+	hidden := map[int]bool{}
+	for col := range m.hiddenCols {
+		if col, ok := shift(col); ok {
+			hidden[col] = true
+		}
+	}
+	m.hiddenCols = hidden
+}
+
+func (m *Model) toggleDetail() {
+	m.showDetail = !m.showDetail
+	if col := m.selectedCol; !m.showDetail && m.hiddenCols[col] {
+		m.moveColumn(1)
+		if m.selectedCol == col {
+			m.moveColumn(-1)
+		}
+	}
+	m.ensureRowVisible()
+	m.ensureColumnVisible()
+}
+
+func (m Model) detailHeight() int {
+	if !m.showDetail || len(m.csv.Header) == 0 {
+		return 0
+	}
+	return min(len(m.csv.Header)+1, m.bodyRows()/2)
+}
+
+func (m Model) renderDetail(titleStyle, nameStyle, valueStyle, selectedStyle lipgloss.Style) string {
+	current := m.getCurrentCSV()
+	height := m.detailHeight()
+	if height == 0 || m.selectedRow >= len(current.Records) {
+		return ""
+	}
+	record := current.Records[m.selectedRow]
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render(fmt.Sprintf("Record %d of %d", m.selectedRow+1, len(current.Records))))
+	s.WriteString("\n")
+
+	nameWidth := 0
+	for _, name := range current.Header {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(name))
+	}
+	nameWidth = min(nameWidth, 30)
+	valueWidth := max(m.width-nameWidth-6, minColumnWidth)
+
+	fields := height - 1
+	start := max(min(m.selectedCol-fields/2, len(current.Header)-fields), 0)
+	for col := start; col < start+fields && col < len(current.Header); col++ {
+		value := ""
+		if col < len(record) {
+			value = record[col]
+		}
+		value = truncate(strings.ReplaceAll(value, "\n", "↵"), valueWidth)
+		style := valueStyle
+		if col == m.selectedCol {
+			style = selectedStyle
+		}
+		name := fmt.Sprintf("%-*s", nameWidth, truncate(current.Header[col], nameWidth))
+		s.WriteString(nameStyle.Render(name))
+		s.WriteString(" : ")
+		s.WriteString(style.Render(value))
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+func (m Model) layoutChips() []string {
+	var chips []string
+	if m.frozenCols > 0 {
+		chips = append(chips, fmt.Sprintf("%d frozen", m.frozenCols))
+	}
+	if len(m.hiddenCols) > 0 {
+		chips = append(chips, fmt.Sprintf("%d hidden", len(m.hiddenCols)))
+	}
+	if m.wrap {
+		chips = append(chips, "wrap")
+	}
+	return chips
+}
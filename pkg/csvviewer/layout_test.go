@@ -0,0 +1,112 @@
+package csvviewer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDisplayColumns(t *testing.T) {
+	widths := []int{10, 10, 10, 10, 10, 10}
+	tests := []struct {
+		name   string
+		width  int
+		frozen int
+		hidden []int
+		scroll int
+		want   []int
+	}{
+		{"all fit", 80, 0, nil, 0, []int{0, 1, 2, 3, 4, 5}},
+		{"cut at the edge", 40, 0, nil, 0, []int{0, 1, 2}},
+		{"scrolled", 40, 0, nil, 2, []int{2, 3, 4}},
+		{"frozen stays while scrolled", 40, 1, nil, 3, []int{0, 3, 4}},
+		{"scroll inside the frozen columns", 40, 2, nil, 1, []int{0, 1, 2}},
+		{"hidden columns are skipped", 40, 0, []int{1, 3}, 0, []int{0, 2, 4}},
+		{"hidden frozen column", 40, 2, []int{0}, 4, []int{1, 4, 5}},
+		{"narrow window shows one column", 5, 0, nil, 1, []int{1}},
+		{"frozen wider than the table", 80, 9, []int{5}, 0, []int{0, 1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel()
+			m.width = tt.width
+			m.frozenCols = tt.frozen
+			m.scrollOffsetCol = tt.scroll
+			m.hiddenCols = map[int]bool{}
+			for _, col := range tt.hidden {
+				m.hiddenCols[col] = true
+			}
+			if got := m.displayColumns(widths); !slices.Equal(got, tt.want) {
+				t.Errorf("displayColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapCell(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"", 4, []string{""}},
+		{"abc", 4, []string{"abc"}},
+		{"abcd", 4, []string{"abcd"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"ab\ncdefg", 4, []string{"ab", "cdef", "g"}},
+		{"日本語のテキスト", 3, []string{"日本語", "のテキ", "スト"}},
+		{"a\n\nb", 4, []string{"a", "", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := wrapCell(tt.text, tt.width); !slices.Equal(got, tt.want) {
+				t.Errorf("wrapCell(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayoutShift(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys       []string
+		wantFrozen int
+		wantHidden []int
+		wantWidths map[int]int
+	}{
+		{"freeze", []string{"l", "|"}, 2, nil, nil},
+		{"unfreeze", []string{"l", "|", "|"}, 0, nil, nil},
+		{"insert inside the frozen columns", []string{"l", "|", "h", "a", "enter"}, 3, nil, nil},
+		{"delete a frozen column", []string{"l", "|", "X"}, 1, nil, nil},
+		{"hidden column moves right", []string{"l", "l", "-", "h", "h", "a", "enter"}, 0, []int{3}, nil},
+		{"hidden column deleted", []string{"l", "l", "-", "X"}, 0, []int{1}, nil},
+		{"width follows its column", []string{"l", ">", "h", "a", "enter"}, 0, nil, map[int]int{2: 14}},
+		{"width dropped with its column", []string{"l", ">", "X"}, 0, nil, map[int]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := press(t, testModel(), tt.keys...)
+			if m.frozenCols != tt.wantFrozen {
+				t.Errorf("frozenCols = %d, want %d", m.frozenCols, tt.wantFrozen)
+			}
+			var hidden []int
+			for col := range m.hiddenCols {
+				hidden = append(hidden, col)
+			}
+			slices.Sort(hidden)
+			if !slices.Equal(hidden, tt.wantHidden) {
+				t.Errorf("hiddenCols = %v, want %v", hidden, tt.wantHidden)
+			}
+			if tt.wantWidths != nil && len(m.colWidths) != len(tt.wantWidths) {
+				t.Errorf("colWidths = %v, want %v", m.colWidths, tt.wantWidths)
+			}
+			for col, width := range tt.wantWidths {
+				if m.colWidths[col] != width {
+					t.Errorf("colWidths = %v, want %v", m.colWidths, tt.wantWidths)
+				}
+			}
+		})
+	}
+}
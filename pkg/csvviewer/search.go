@@ -0,0 +1,200 @@
+package csvviewer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type cellPos struct {
+	row int
+	col int
+}
+
+func (m Model) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.mode = normalMode
+		if m.searchQuery == "" {
+			m.clearSearch()
+		}
+		return m, nil
+	case "esc":
+		m.mode = normalMode
+		m.clearSearch()
+		m.selectedRow, m.selectedCol = m.searchOrigin.row, m.searchOrigin.col
+		m.ensureRowVisible()
+		m.ensureColumnVisible()
+		m.statusMessage = "Search cancelled"
+		return m, nil
+	case "ctrl+r":
+		m.searchRegex = !m.searchRegex
+	case "ctrl+t":
+		m.searchCase = !m.searchCase
+	default:
+		m.searchQuery = editText(m.searchQuery, msg)
+	}
+
+	m.selectedRow, m.selectedCol = m.searchOrigin.row, m.searchOrigin.col
+	if err := m.refreshSearch(); err != nil {
+		m.statusMessage = fmt.Sprintf("Invalid regex: %v", err)
+	} else if len(m.searchMatches) > 0 {
+		m.jumpToMatch(m.nextMatch(m.searchOrigin, true))
+	} else if m.searchQuery != "" {
+		m.statusMessage = m.matchStatus()
+	} else {
+		m.statusMessage = ""
+	}
+	return m, nil
+}
+
+func (m *Model) startSearch() {
+	m.mode = searchInputMode
+	m.searchQuery = ""
+	m.searchOrigin = cellPos{row: m.selectedRow, col: m.selectedCol}
+	m.searchRe = nil
+	m.searchMatches = nil
+	m.statusMessage = ""
+}
+
+func (m *Model) clearSearch() {
+	m.searchQuery = ""
+	m.searchRe = nil
+	m.searchMatches = nil
+}
+
+func (m *Model) refreshSearch() error {
+	m.searchRe = nil
+	m.searchMatches = nil
+	if m.searchQuery == "" {
+		return nil
+	}
+
+	pattern := m.searchQuery
+	if !m.searchRegex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if !m.searchCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	m.searchRe = re
+
+	for row, record := range m.getCurrentCSV().Records {
+		for col, cell := range record {
+			if col < len(m.csv.Header) && !m.hiddenCols[col] && re.MatchString(cell) {
+				m.searchMatches = append(m.searchMatches, cellPos{row: row, col: col})
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Model) nextMatch(from cellPos, inclusive bool) int {
+	for i, pos := range m.searchMatches {
+		if pos.row > from.row || pos.row == from.row && (pos.col > from.col || inclusive && pos.col == from.col) {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m *Model) prevMatch(from cellPos) int {
+	for i := len(m.searchMatches) - 1; i >= 0; i-- {
+		pos := m.searchMatches[i]
+		if pos.row < from.row || pos.row == from.row && pos.col < from.col {
+			return i
+		}
+	}
+	return len(m.searchMatches) - 1
+}
+
+func (m *Model) jumpToMatch(i int) {
+	pos := m.searchMatches[i]
+	m.selectedRow, m.selectedCol = pos.row, pos.col
+	m.ensureRowVisible()
+	m.ensureColumnVisible()
+	m.statusMessage = m.matchStatus()
+}
+
+func (m *Model) searchStep(forward bool) {
+	if m.searchRe == nil {
+		m.statusMessage = "No search (press /)"
+		return
+	}
+	if len(m.searchMatches) == 0 {
+		m.statusMessage = m.matchStatus()
+		return
+	}
+	current := cellPos{row: m.selectedRow, col: m.selectedCol}
+	if forward {
+		m.jumpToMatch(m.nextMatch(current, false))
+	} else {
+		m.jumpToMatch(m.prevMatch(current))
+	}
+}
+
+func (m *Model) matchStatus() string {
+	if len(m.searchMatches) == 0 {
+		return fmt.Sprintf("No matches for %q", m.searchQuery)
+	}
+	current := cellPos{row: m.selectedRow, col: m.selectedCol}
+	for i, pos := range m.searchMatches {
+		if pos == current {
+			return fmt.Sprintf("match %d of %d", i+1, len(m.searchMatches))
+		}
+	}
+	return fmt.Sprintf("%d matches", len(m.searchMatches))
+}
+
+func (m *Model) searchFlags() string {
+	var flags []string
+	if m.searchRegex {
+		flags = append(flags, "regex")
+	}
+	if m.searchCase {
+		flags = append(flags, "case-sensitive")
+	}
+	if len(flags) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(flags, ", ") + ")"
+}
+
+func (m Model) renderCell(text string, width int, style, match lipgloss.Style) string {
+	var ranges [][]int
+	if m.searchRe != nil {
+		for _, r := range m.searchRe.FindAllStringIndex(text, -1) {
+			if r[1] > r[0] {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		return style.Render(fmt.Sprintf("%-*s", width, text))
+	}
+
+	plain := style.UnsetPadding()
+	var b strings.Builder
+	b.WriteString(plain.Render(" "))
+	last := 0
+	for _, r := range ranges {
+		if r[0] > last {
+			b.WriteString(plain.Render(text[last:r[0]]))
+		}
+		b.WriteString(match.Render(text[r[0]:r[1]]))
+		last = r[1]
+	}
+	if last < len(text) {
+		b.WriteString(plain.Render(text[last:]))
+	}
+	b.WriteString(plain.Render(strings.Repeat(" ", max(width-utf8.RuneCountInString(text), 0)+1)))
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package csvviewer
+
+import (
+	"testing"
+)
+
+func TestNextPrevMatch(t *testing.T) {
+	m := testModel()
+	m.searchMatches = []cellPos{{0, 1}, {1, 2}, {3, 0}, {3, 2}}
+
+	tests := []struct {
+		name      string
+		from      cellPos
+		inclusive bool
+		wantNext  int
+		wantPrev  int
+	}{
+		{"before first", cellPos{0, 0}, false, 0, 3},
+		{"on first", cellPos{0, 1}, false, 1, 3},
+		{"on first inclusive", cellPos{0, 1}, true, 0, 3},
+		{"between rows", cellPos{2, 1}, false, 2, 1},
+		{"same row", cellPos{3, 1}, false, 3, 2},
+		{"on last", cellPos{3, 2}, false, 0, 2},
+		{"after last", cellPos{3, 5}, false, 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.nextMatch(tt.from, tt.inclusive); got != tt.wantNext {
+				t.Errorf("nextMatch(%v) = %d, want %d", tt.from, got, tt.wantNext)
+			}
+			if got := m.prevMatch(tt.from); got != tt.wantPrev {
+				t.Errorf("prevMatch(%v) = %d, want %d", tt.from, got, tt.wantPrev)
+			}
+		})
+	}
+}
+
+func TestSearchKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		keys        []string
+		wantMatches []cellPos
+		wantPos     cellPos
+	}{
+		{"incremental", []string{"/", "l", "a"}, []cellPos{{1, 2}, {3, 2}}, cellPos{1, 2}},
+		{"next", []string{"/", "l", "a", "enter", "n"}, []cellPos{{1, 2}, {3, 2}}, cellPos{3, 2}},
+		{"next wraps", []string{"/", "l", "a", "enter", "n", "n"}, []cellPos{{1, 2}, {3, 2}}, cellPos{1, 2}},
+		{"previous wraps", []string{"/", "l", "a", "enter", "N"}, []cellPos{{1, 2}, {3, 2}}, cellPos{3, 2}},
+		{"case-sensitive", []string{"/", "l", "a", "ctrl+t"}, nil, cellPos{0, 0}},
+		{"regex", []string{"/", "^", "j", "ctrl+r"}, []cellPos{{0, 0}, {1, 0}}, cellPos{0, 0}},
+		{"cancel restores the cursor", []string{"j", "/", "a", "n", "n", "esc"}, nil, cellPos{1, 0}},
+		{"skips hidden columns", []string{"l", "l", "-", "/", "l", "a"}, nil, cellPos{0, 1}},
+		{"hiding drops matches", []string{"/", "l", "a", "enter", "-"}, nil, cellPos{1, 1}},
+		{"showing restores matches", []string{"l", "l", "-", "/", "l", "a", "enter", "+"}, []cellPos{{1, 2}, {3, 2}}, cellPos{0, 1}},
+		{"follows the filtered view", []string{"f", "j", "enter", "/", "j"}, []cellPos{{0, 0}, {1, 0}}, cellPos{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := press(t, testModel(), tt.keys...)
+			if len(m.searchMatches) != len(tt.wantMatches) {
+				t.Fatalf("searchMatches = %v, want %v", m.searchMatches, tt.wantMatches)
+			}
+			for i := range tt.wantMatches {
+				if m.searchMatches[i] != tt.wantMatches[i] {
+					t.Fatalf("searchMatches = %v, want %v", m.searchMatches, tt.wantMatches)
+				}
+			}
+			if got := (cellPos{m.selectedRow, m.selectedCol}); got != tt.wantPos {
+				t.Errorf("selection = %v, want %v", got, tt.wantPos)
+			}
+		})
+	}
+}
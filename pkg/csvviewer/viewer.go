@@ -3,8 +3,11 @@ package csvviewer
 import (
 	"encoding/csv"
 	"fmt"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
+	"sean-stapleton-doyle/csvtk/pkg/csveditor"
 	"sean-stapleton-doyle/csvtk/pkg/csvparser"
 
 	"github.com/atotto/clipboard"
@@ -12,44 +15,73 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-type filterMode int
+type viewMode int
 
 const (
-	normalMode filterMode = iota
+	normalMode viewMode = iota
 	filterInputMode
+	searchInputMode
+	editMode
+	commandMode
+	confirmQuitMode
 )
 
 type Model struct {
 	csv             *csvparser.CSV
 	filteredCSV     *csvparser.CSV
+	filteredRows    []int
 	scrollOffsetRow int
 	scrollOffsetCol int
 	selectedRow     int
+	selectedCol     int
 	width           int
 	height          int
 	filename        string
-	mode            filterMode
+	config          *csvparser.Config
+	mode            viewMode
 	filterInput     string
-	filterColumn    string
+	filterColumn    int
+	filterText      string
+	columnFilters   []columnFilter
+	sortCol         int
+	sortDesc        bool
+	searchQuery     string
+	searchRegex     bool
+	searchCase      bool
+	searchRe        *regexp.Regexp
+	searchMatches   []cellPos
+	searchOrigin    cellPos
+	frozenCols      int
+	colWidths       map[int]int
+	hiddenCols      map[int]bool
+	wrap            bool
+	showDetail      bool
+	editInput       string
+	editingHeader   bool
+	commandInput    string
+	journal         *csveditor.Journal
+	savedVersion    int
 	statusMessage   string
-	showingFiltered bool
 }
 
-func New(csv *csvparser.CSV, filename string) Model {
+func New(csv *csvparser.CSV, filename string, config *csvparser.Config) Model {
 	return Model{
 		csv:             csv,
 		filteredCSV:     nil,
 		scrollOffsetRow: 0,
 		scrollOffsetCol: 0,
 		selectedRow:     0,
+		selectedCol:     0,
 		width:           80,
 		height:          24,
 		filename:        filename,
+		config:          config,
+		journal:         csveditor.NewJournal(csv),
 		mode:            normalMode,
 		filterInput:     "",
-		filterColumn:    "",
+		filterColumn:    -1,
+		sortCol:         -1,
 		statusMessage:   "",
-		showingFiltered: false,
 	}
 }
 
@@ -60,8 +92,17 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if m.mode == filterInputMode {
+		switch m.mode {
+		case filterInputMode:
 			return m.handleFilterInput(msg)
+		case searchInputMode:
+			return m.handleSearchInput(msg)
+		case editMode:
+			return m.handleEditInput(msg)
+		case commandMode:
+			return m.handleCommandInput(msg)
+		case confirmQuitMode:
+			return m.handleConfirmQuit(msg)
 		}
 		return m.handleNormalInput(msg)
 	case tea.WindowSizeMsg:
@@ -77,13 +118,15 @@ func (m Model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch msg.String() {
 	case "ctrl+c", "q":
+		if m.modified() {
+			m.mode = confirmQuitMode
+			return m, nil
+		}
 		return m, tea.Quit
 	case "down", "j":
 		if m.selectedRow < maxRows-1 {
 			m.selectedRow++
-			if m.selectedRow >= m.scrollOffsetRow+m.getVisibleRows() {
-				m.scrollOffsetRow++
-			}
+			m.ensureRowVisible()
 		}
 	case "up", "k":
 		if m.selectedRow > 0 {
@@ -93,13 +136,9 @@ func (m Model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 	case "right", "l":
-		if m.scrollOffsetCol < len(currentCSV.Header)-1 {
-			m.scrollOffsetCol++
-		}
+		m.moveColumn(1)
 	case "left", "h":
-		if m.scrollOffsetCol > 0 {
-			m.scrollOffsetCol--
-		}
+		m.moveColumn(-1)
 	case "pgdown":
 		visibleRows := m.getVisibleRows()
 		m.selectedRow += visibleRows
@@ -110,6 +149,7 @@ func (m Model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.scrollOffsetRow < 0 {
 			m.scrollOffsetRow = 0
 		}
+		m.ensureRowVisible()
 	case "pgup":
 		visibleRows := m.getVisibleRows()
 		m.selectedRow -= visibleRows
@@ -126,6 +166,7 @@ func (m Model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.scrollOffsetRow < 0 {
 			m.scrollOffsetRow = 0
 		}
+		m.ensureRowVisible()
 	case "c":
 
 		if m.selectedRow < len(currentCSV.Records) {
@@ -140,14 +181,69 @@ func (m Model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 		m.mode = filterInputMode
 		m.filterInput = ""
+		m.filterColumn = -1
 		m.statusMessage = "Filter mode: enter search text"
+	case "|":
+		m.toggleFreeze()
+	case ">":
+		m.resizeColumn(widthStep)
+	case "<":
+		m.resizeColumn(-widthStep)
+	case "=":
+		m.autoWidth()
+	case "-":
+		m.hideColumn()
+	case "+":
+		m.unhideColumns()
+	case "w":
+		m.wrap = !m.wrap
+		m.ensureRowVisible()
+	case "v":
+		m.toggleDetail()
+	case "/":
+		m.startSearch()
+	case "n":
+		m.searchStep(true)
+	case "N":
+		m.searchStep(false)
+	case "esc":
+		if m.searchRe != nil {
+			m.clearSearch()
+			m.statusMessage = "Search cleared"
+		}
+	case "F":
+		m.startColumnFilter()
+	case "R":
+		m.dropColumnFilter()
 	case "r":
-
-		m.showingFiltered = false
-		m.filteredCSV = nil
-		m.selectedRow = 0
-		m.scrollOffsetRow = 0
-		m.statusMessage = "Filter cleared"
+		m.resetFilters()
+	case "s":
+		m.toggleSort()
+	case "S":
+		m.clearSort()
+	case "enter", "e":
+		m.startEdit(false)
+	case "E":
+		m.startEdit(true)
+	case "o":
+		m.insertRow(m.selectedRow + 1)
+	case "O":
+		m.insertRow(m.selectedRow)
+	case "D":
+		m.deleteRow()
+	case "a":
+		m.insertColumn(m.selectedCol + 1)
+	case "X":
+		m.deleteColumn()
+	case "u":
+		m.undo()
+	case "ctrl+r":
+		m.redo()
+	case "ctrl+s":
+		m.save("")
+	case ":":
+		m.mode = commandMode
+		m.commandInput = ""
 	}
 	return m, nil
 }
@@ -165,49 +261,20 @@ func (m Model) handleFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = normalMode
 		m.filterInput = ""
 		m.statusMessage = "Filter cancelled"
-	case "backspace":
-		if len(m.filterInput) > 0 {
-			m.filterInput = m.filterInput[:len(m.filterInput)-1]
-		}
 	default:
-		if len(msg.String()) == 1 {
-			m.filterInput += msg.String()
-		}
+		m.filterInput = editText(m.filterInput, msg)
 	}
 	return m, nil
 }
 
-func (m *Model) applyFilter() {
-	filtered := &csvparser.CSV{
-		Header:  m.csv.Header,
-		Records: [][]string{},
-	}
-
-	searchTerm := strings.ToLower(m.filterInput)
-	for _, record := range m.csv.Records {
-		for _, cell := range record {
-			if strings.Contains(strings.ToLower(cell), searchTerm) {
-				filtered.Records = append(filtered.Records, record)
-				break
-			}
-		}
-	}
-
-	m.filteredCSV = filtered
-	m.showingFiltered = true
-	m.selectedRow = 0
-	m.scrollOffsetRow = 0
-	m.statusMessage = fmt.Sprintf("Filtered: %d of %d rows", len(filtered.Records), len(m.csv.Records))
-}
-
 func (m Model) getCurrentCSV() *csvparser.CSV {
-	if m.showingFiltered && m.filteredCSV != nil {
+	if m.filteredCSV != nil {
 		return m.filteredCSV
 	}
 	return m.csv
 }
 
-func (m Model) getVisibleRows() int {
+func (m Model) bodyRows() int {
 	visibleRows := m.height - 10
 	if visibleRows < 1 {
 		visibleRows = 10
@@ -215,6 +282,14 @@ func (m Model) getVisibleRows() int {
 	return visibleRows
 }
 
+func (m Model) tableRows() int {
+	return max(m.bodyRows()-m.detailHeight(), 1)
+}
+
+func (m Model) getVisibleRows() int {
+	return m.tableRows()
+}
+
 func (m Model) View() string {
 
 	titleStyle := lipgloss.NewStyle().
@@ -229,6 +304,20 @@ func (m Model) View() string {
 		Background(lipgloss.Color("63")).
 		Padding(0, 1)
 
+	focusedHeaderStyle := headerStyle.
+		Background(lipgloss.Color("99"))
+
+	frozenHeaderStyle := headerStyle.
+		Background(lipgloss.Color("60"))
+
+	detailTitleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("205"))
+
+	detailNameStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("229"))
+
 	cellStyle := lipgloss.NewStyle().
 		Padding(0, 1)
 
@@ -241,6 +330,30 @@ func (m Model) View() string {
 		Foreground(lipgloss.Color("229")).
 		Padding(0, 1)
 
+	selectedCellStyle := lipgloss.NewStyle().
+		Bold(true).
+		Background(lipgloss.Color("63")).
+		Foreground(lipgloss.Color("229")).
+		Padding(0, 1)
+
+	chipStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("24")).
+		Padding(0, 1)
+
+	matchStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("235")).
+		Background(lipgloss.Color("226"))
+
+	currentMatchStyle := matchStyle.
+		Background(lipgloss.Color("208"))
+
+	modifiedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("235")).
+		Background(lipgloss.Color("214")).
+		Padding(0, 1)
+
 	helpStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("241")).
 		Padding(1, 0, 0, 0)
@@ -263,75 +376,75 @@ func (m Model) View() string {
 	s.WriteString(titleStyle.Render(title))
 	s.WriteString(" ")
 	s.WriteString(titleStyle.Render(stats))
-	s.WriteString("\n\n")
-
-	colWidths := make([]int, len(currentCSV.Header))
-	for i, header := range currentCSV.Header {
-		colWidths[i] = len(header)
+	if m.modified() {
+		s.WriteString(" ")
+		s.WriteString(modifiedStyle.Render("modified"))
 	}
-	for _, record := range currentCSV.Records {
-		for i, cell := range record {
-			if i < len(colWidths) && len(cell) > colWidths[i] {
-				colWidths[i] = len(cell)
-			}
-		}
-	}
-
-	for i := range colWidths {
-		if colWidths[i] > 30 {
-			colWidths[i] = 30
-		}
-		if colWidths[i] < 10 {
-			colWidths[i] = 10
-		}
+	for _, chip := range append(m.viewChips(), m.layoutChips()...) {
+		s.WriteString(" ")
+		s.WriteString(chipStyle.Render(chip))
 	}
+	s.WriteString("\n\n")
 
-	visibleCols := m.getVisibleColumns(colWidths)
+	colWidths := m.columnWidths()
+	displayCols := m.displayColumns(colWidths)
 
 	var headerRow strings.Builder
-	for i := m.scrollOffsetCol; i < m.scrollOffsetCol+visibleCols && i < len(currentCSV.Header); i++ {
-		header := currentCSV.Header[i]
+	for _, i := range displayCols {
 		width := colWidths[i]
-		if len(header) > width {
-			header = header[:width-3] + "..."
+		marker := m.sortMarker(i)
+		header := truncate(currentCSV.Header[i], width-utf8.RuneCountInString(marker)) + marker
+		style := headerStyle
+		if i == m.selectedCol {
+			style = focusedHeaderStyle
+		} else if i < m.frozenCols {
+			style = frozenHeaderStyle
 		}
-		headerRow.WriteString(headerStyle.Render(fmt.Sprintf("%-*s", width, header)))
+		headerRow.WriteString(style.Render(fmt.Sprintf("%-*s", width, header)))
 	}
 	s.WriteString(headerRow.String())
 	s.WriteString("\n")
 
-	visibleRows := m.getVisibleRows()
-	start := m.scrollOffsetRow
-	end := start + visibleRows
-	if end > len(currentCSV.Records) {
-		end = len(currentCSV.Records)
-	}
-
-	for idx := start; idx < end; idx++ {
+	lines := 0
+	for idx := m.scrollOffsetRow; idx < len(currentCSV.Records) && lines < m.tableRows(); idx++ {
 		record := currentCSV.Records[idx]
-		var row strings.Builder
-		for i := m.scrollOffsetCol; i < m.scrollOffsetCol+visibleCols && i < len(record); i++ {
-			if i >= len(colWidths) {
-				break
-			}
-			cell := record[i]
-			width := colWidths[i]
-			if len(cell) > width {
-				cell = cell[:width-3] + "..."
-			}
+		height := min(m.rowHeight(idx, colWidths), m.tableRows()-lines)
+		cells := make([][]string, len(displayCols))
+		for j, i := range displayCols {
+			cells[j] = m.cellLines(record, i, colWidths[i])
+		}
 
-			var style lipgloss.Style
-			if idx == m.selectedRow {
-				style = selectedRowStyle
-			} else if idx%2 == 1 {
-				style = altRowStyle
-			} else {
-				style = cellStyle
+		for line := range height {
+			var row strings.Builder
+			for j, i := range displayCols {
+				cell := ""
+				if line < len(cells[j]) {
+					cell = cells[j][line]
+				}
+
+				var style lipgloss.Style
+				match := matchStyle
+				if idx == m.selectedRow && i == m.selectedCol {
+					style = selectedCellStyle
+					match = currentMatchStyle
+				} else if idx == m.selectedRow {
+					style = selectedRowStyle
+				} else if idx%2 == 1 {
+					style = altRowStyle
+				} else {
+					style = cellStyle
+				}
+				row.WriteString(m.renderCell(cell, colWidths[i], style, match))
 			}
-			row.WriteString(style.Render(fmt.Sprintf("%-*s", width, cell)))
+			s.WriteString(row.String())
+			s.WriteString("\n")
 		}
-		s.WriteString(row.String())
+		lines += height
+	}
+
+	if m.showDetail {
 		s.WriteString("\n")
+		s.WriteString(m.renderDetail(detailTitleStyle, detailNameStyle, cellStyle.UnsetPadding(), selectedCellStyle.UnsetPadding()))
 	}
 
 	s.WriteString("\n")
@@ -340,51 +453,95 @@ func (m Model) View() string {
 		s.WriteString("\n")
 	}
 
-	if m.mode == filterInputMode {
-		s.WriteString(filterInputStyle.Render(fmt.Sprintf("Filter: %s_", m.filterInput)))
+	switch m.mode {
+	case filterInputMode:
+		label := "Filter rows"
+		if m.filterColumn >= 0 {
+			label = "Filter " + m.csv.Header[m.filterColumn]
+		}
+		s.WriteString(filterInputStyle.Render(fmt.Sprintf("%s: %s_", label, m.filterInput)))
+		s.WriteString("\n")
+	case searchInputMode:
+		s.WriteString(filterInputStyle.Render(fmt.Sprintf("/%s_%s", m.searchQuery, m.searchFlags())))
+		s.WriteString("\n")
+	case editMode:
+		s.WriteString(filterInputStyle.Render(fmt.Sprintf("%s: %s_", m.editLabel(), m.editInput)))
+		s.WriteString("\n")
+	case commandMode:
+		s.WriteString(filterInputStyle.Render(fmt.Sprintf(":%s_", m.commandInput)))
+		s.WriteString("\n")
+	case confirmQuitMode:
+		s.WriteString(filterInputStyle.Render("Unsaved changes. Save before quitting? (y/n)"))
 		s.WriteString("\n")
 	}
 
-	if m.mode == normalMode {
-		help := "↑↓/jk: move • ←→/hl: scroll • PgUp/PgDn: page • g/G: top/bottom • c: copy • f: filter • r: reset • q: quit"
-		s.WriteString(helpStyle.Render(help))
-	} else {
-		help := "Type to filter • Enter: apply • Esc: cancel"
-		s.WriteString(helpStyle.Render(help))
+	var help string
+	switch m.mode {
+	case normalMode:
+		help = "↑↓←→/hjkl: move • PgUp/PgDn: page • g/G: top/bottom • enter: edit • E: rename column • o/O: add row • D: delete row • a: add column • X: delete column • u/ctrl+r: undo/redo • ctrl+s: save • :: command • c: copy • /: search • n/N: next/prev match • |: freeze • </>/=: width • -/+: hide/show columns • w: wrap • v: record • s/S: sort/unsort • f: filter rows • F: filter column • R: drop filter • r: reset • q: quit"
+	case filterInputMode:
+		help = "Type to filter • Enter: apply • Esc: cancel"
+	case searchInputMode:
+		help = "Type to search • Enter: done • Esc: cancel • ctrl+r: toggle regex • ctrl+t: toggle case-sensitive"
+	case editMode:
+		help = "Type to edit • Enter: apply • Esc: cancel"
+	case commandMode:
+		help = "w: save • w FILE: save as • q: quit • q!: quit without saving • wq: save and quit • Esc: cancel"
+	case confirmQuitMode:
+		help = "y: save and quit • n: quit without saving • Esc: keep editing"
 	}
+	s.WriteString(helpStyle.Render(help))
 
-	if len(currentCSV.Records) > visibleRows {
-		scrollInfo := fmt.Sprintf("\nRow %d of %d", m.selectedRow+1, len(currentCSV.Records))
-		if m.scrollOffsetCol > 0 {
-			scrollInfo += fmt.Sprintf(" • Col %d+", m.scrollOffsetCol+1)
-		}
-		s.WriteString(helpStyle.Render(scrollInfo))
+	if len(currentCSV.Header) > 0 {
+		position := fmt.Sprintf("\nRow %d of %d • Col %d of %d (%s)", min(m.selectedRow+1, len(currentCSV.Records)), len(currentCSV.Records),
+			m.selectedCol+1, len(currentCSV.Header), currentCSV.Header[m.selectedCol])
+		s.WriteString(helpStyle.Render(position))
 	}
 
 	return s.String()
 }
 
-func (m Model) getVisibleColumns(colWidths []int) int {
-	availableWidth := m.width - 4
-	totalWidth := 0
-	visibleCols := 0
+func (m Model) columnWidths() []int {
+	currentCSV := m.getCurrentCSV()
+	colWidths := make([]int, len(currentCSV.Header))
+	for i, header := range currentCSV.Header {
+		colWidths[i] = len(header) + utf8.RuneCountInString(m.sortMarker(i))
+	}
+	for _, record := range currentCSV.Records {
+		for i, cell := range record {
+			if i < len(colWidths) && len(cell) > colWidths[i] {
+				colWidths[i] = len(cell)
+			}
+		}
+	}
 
-	for i := m.scrollOffsetCol; i < len(colWidths); i++ {
-		totalWidth += colWidths[i] + 2
-		if totalWidth > availableWidth {
-			break
+	for i := range colWidths {
+		if colWidths[i] > 30 {
+			colWidths[i] = 30
+		}
+		if colWidths[i] < 10 {
+			colWidths[i] = 10
+		}
+		if width, ok := m.colWidths[i]; ok {
+			colWidths[i] = width
 		}
-		visibleCols++
 	}
+	return colWidths
+}
 
-	if visibleCols == 0 {
-		visibleCols = 1
+func (m Model) sortMarker(col int) string {
+	switch {
+	case col != m.sortCol:
+		return ""
+	case m.sortDesc:
+		return " ↓"
+	default:
+		return " ↑"
 	}
-	return visibleCols
 }
 
-func Run(csv *csvparser.CSV, filename string) error {
-	p := tea.NewProgram(New(csv, filename), tea.WithAltScreen())
+func Run(csv *csvparser.CSV, filename string, config *csvparser.Config) error {
+	p := tea.NewProgram(New(csv, filename, config), tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
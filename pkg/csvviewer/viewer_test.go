@@ -0,0 +1,87 @@
+package csvviewer
+
+import (
+	"strings"
+	"testing"
+
+	"sean-stapleton-doyle/csvtk/pkg/csvparser"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func testModel() Model {
+	csv := &csvparser.CSV{
+		Header: []string{"Name", "Age", "City"},
+		Records: [][]string{
+			{"John", "30", "NYC"},
+			{"Jane", "25", "LA"},
+			{"Bob", "35", "SF"},
+			{"Ann", "28", "LA"},
+		},
+	}
+	return New(csv, "test.csv", csvparser.DefaultConfig())
+}
+
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEscape}
+	case "ctrl+c":
+		return tea.KeyMsg{Type: tea.KeyCtrlC}
+	case "ctrl+r":
+		return tea.KeyMsg{Type: tea.KeyCtrlR}
+	case "ctrl+t":
+		return tea.KeyMsg{Type: tea.KeyCtrlT}
+	case "ctrl+u":
+		return tea.KeyMsg{Type: tea.KeyCtrlU}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func press(t *testing.T, m Model, keys ...string) Model {
+	t.Helper()
+	for _, key := range keys {
+		next, _ := m.Update(keyMsg(key))
+		m = next.(Model)
+	}
+	return m
+}
+
+func viewString(m Model) string {
+	current := m.getCurrentCSV()
+	rows := make([]string, len(current.Records))
+	for i, record := range current.Records {
+		rows[i] = strings.Join(record, ",")
+	}
+	return strings.Join(current.Header, ",") + "|" + strings.Join(rows, ";")
+}
+
+func TestUpdateNavigation(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []string
+		wantRow int
+		wantCol int
+	}{
+		{"down", []string{"j", "j"}, 2, 0},
+		{"down stops at last row", []string{"j", "j", "j", "j", "j"}, 3, 0},
+		{"up stops at first row", []string{"j", "k", "k"}, 0, 0},
+		{"right and left", []string{"l", "l", "l", "h"}, 0, 1},
+		{"bottom and top", []string{"G", "g"}, 0, 0},
+		{"bottom", []string{"G"}, 3, 0},
+		{"right skips hidden columns", []string{"l", "-", "h", "l"}, 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := press(t, testModel(), tt.keys...)
+			if m.selectedRow != tt.wantRow || m.selectedCol != tt.wantCol {
+				t.Errorf("selection = (%d, %d), want (%d, %d)", m.selectedRow, m.selectedCol, tt.wantRow, tt.wantCol)
+			}
+		})
+	}
+}